@@ -17,6 +17,10 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -25,18 +29,54 @@ import (
 
 // AppSpec defines the desired state of App
 type AppSpec struct {
+	// Template instantiates an AppTemplate from the App's namespace. The controller renders it into
+	// services and databases, overwriting them whenever the template or its parameters change.
+	// +optional
+	Template *AppTemplateRef `json:"template,omitempty"`
+
+	// Paused stops the controller from changing the App's workloads, so a test can break them on purpose.
+	// Status is still reported; out-of-band changes are reverted, and reported as drift, on resume.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
 	// Services defines the stack of microservices
-	Services []ServiceSpec `json:"services"`
+	// +optional
+	Services []ServiceSpec `json:"services,omitempty"`
 
 	// Databases defines database services with schema initialization
 	// +optional
 	Databases []DatabaseSpec `json:"databases,omitempty"`
+
+	// MaxConcurrentTestRuns bounds the TestRuns targeting the App that run at once; the others wait in
+	// the queue
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrentTestRuns *int32 `json:"maxConcurrentTestRuns,omitempty"`
+}
+
+// AppTemplateRef names an AppTemplate and the parameter values to render it with
+type AppTemplateRef struct {
+	// Name of the AppTemplate
+	Name string `json:"name"`
+
+	// Parameters are substituted for the template's ${name} references
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
 }
 
 type ServiceSpec struct {
-	Name     string `json:"name"`
-	Image    string `json:"image"`
-	Version  string `json:"version"`
+	Name string `json:"name"`
+	// Kind Mock replaces the service with the built-in stub server configured by Mock
+	// +kubebuilder:validation:Enum=Container;Mock
+	// +kubebuilder:default=Container
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// Image to run; required unless Kind is Mock
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Version is used as the image tag when Image has no tag or digest
+	// +optional
+	Version  string `json:"version,omitempty"`
 	Replicas *int32 `json:"replicas,omitempty"`
 	Port     int32  `json:"port"`
 	// GrpcPort (optional) for gRPC traffic
@@ -45,21 +85,405 @@ type ServiceSpec struct {
 	// EnvVars are additional environment variables to inject
 	// +optional
 	EnvVars map[string]string `json:"envVars,omitempty"`
+	// Env are additional environment variables that may reference Secrets or ConfigMaps via valueFrom.
+	// They are applied after EnvVars.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// EnvFrom injects every key of the referenced Secrets or ConfigMaps
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+	// DependsOn names services or databases of this App that must be ready, with all migrations
+	// applied, before this service's Deployment is created or rolled
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// Strategy selects how changes to the service are rolled out (default: in-place rolling update)
+	// +optional
+	Strategy *RolloutStrategy `json:"strategy,omitempty"`
+	// Mock holds the stubs served when Kind is Mock
+	// +optional
+	Mock *MockSpec `json:"mock,omitempty"`
+	// FaultInjection routes the service's traffic through a fault-injection proxy in its pods, driven
+	// by the chaos Lua module
+	// +optional
+	FaultInjection *FaultInjectionSpec `json:"faultInjection,omitempty"`
+
+	WorkloadSpec `json:",inline"`
+}
+
+// Rollout strategies for services
+const (
+	// RolloutStrategyRollingUpdate replaces the service's pods in place
+	RolloutStrategyRollingUpdate = "RollingUpdate"
+	// RolloutStrategyCanary runs the new version in a second Deployment next to the stable one,
+	// behind the shared Service, until it is promoted or aborted
+	RolloutStrategyCanary = "Canary"
+	// RolloutStrategyBlueGreen runs the new version at full size in a preview Deployment and flips
+	// the Service selector over to it when promoted
+	RolloutStrategyBlueGreen = "BlueGreen"
+)
+
+// Rollout requests are set on the App as the annotation RolloutRequestAnnotationPrefix+<service>
+// and removed by the controller once acted on
+const (
+	RolloutRequestAnnotationPrefix = "rollout.topas.io/"
+	RolloutRequestPromote          = "promote"
+	RolloutRequestAbort            = "abort"
+)
+
+// TemplateHashAnnotation records on the App the hash of the services and databases last rendered from
+// spec.template, written together with the rendered spec so a failed status update cannot lose it
+const TemplateHashAnnotation = "topas.io/template-hash"
+
+// RolloutStrategy selects how a changed service reaches its new version
+type RolloutStrategy struct {
+	// Type of rollout. Canary and BlueGreen keep the previous version serving until the rollout
+	// is promoted or aborted (sut.promote / sut.abort).
+	// +kubebuilder:validation:Enum=RollingUpdate;Canary;BlueGreen
+	// +kubebuilder:default=RollingUpdate
+	Type string `json:"type,omitempty"`
+
+	// CanaryWeight is the percentage of replicas running the new version during a canary (default 20).
+	// Traffic follows the replica split, so the weight is rounded to whole pods.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	// +optional
+	CanaryWeight *int32 `json:"canaryWeight,omitempty"`
+}
+
+// WorkloadSpec holds the pod-level settings shared by services and databases
+type WorkloadSpec struct {
+	// ReadinessProbe gates traffic and sut.wait_ready. A probe port left at 0 defaults to the
+	// component's port (gRPC probes prefer GrpcPort). Databases default to an engine-specific check.
+	// +optional
+	ReadinessProbe *corev1.Probe `json:"readinessProbe,omitempty"`
+	// LivenessProbe restarts the container when it fails; port defaulting matches ReadinessProbe
+	// +optional
+	LivenessProbe *corev1.Probe `json:"livenessProbe,omitempty"`
+	// Resources are the container's requests and limits
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// NodeSelector constrains the pods to matching nodes
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations let the pods schedule onto tainted nodes
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// VolumeMounts are extra volumes mounted into the container
+	// +optional
+	VolumeMounts []VolumeMountSpec `json:"volumeMounts,omitempty"`
+}
+
+// VolumeMountSpec mounts a ConfigMap, Secret, PersistentVolumeClaim or scratch directory.
+// Exactly one source must be set.
+type VolumeMountSpec struct {
+	// Name of the volume, unique within the component
+	Name string `json:"name"`
+	// MountPath inside the container
+	MountPath string `json:"mountPath"`
+	// SubPath mounts a single file or directory of the volume
+	// +optional
+	SubPath string `json:"subPath,omitempty"`
+	// ReadOnly mounts the volume read-only
+	// +optional
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// +optional
+	ConfigMap *corev1.ConfigMapVolumeSource `json:"configMap,omitempty"`
+	// +optional
+	Secret *corev1.SecretVolumeSource `json:"secret,omitempty"`
+	// +optional
+	PersistentVolumeClaim *corev1.PersistentVolumeClaimVolumeSource `json:"persistentVolumeClaim,omitempty"`
+	// +optional
+	EmptyDir *corev1.EmptyDirVolumeSource `json:"emptyDir,omitempty"`
+}
+
+// ImageRef returns the image to run: Image with Version appended as its tag when Image carries
+// neither a tag nor a digest, otherwise Image unchanged.
+func (s ServiceSpec) ImageRef() string {
+	if s.Version == "" || imageTag(s.Image) != "" {
+		return s.Image
+	}
+	return s.Image + ":" + s.Version
+}
+
+// IsMock reports whether the service runs the built-in stub server.
+func (s ServiceSpec) IsMock() bool {
+	return s.Kind == ServiceKindMock
+}
+
+// RolloutStrategyType returns the service's rollout strategy, defaulting to RollingUpdate.
+func (s ServiceSpec) RolloutStrategyType() string {
+	if s.Strategy == nil || s.Strategy.Type == "" {
+		return RolloutStrategyRollingUpdate
+	}
+	return s.Strategy.Type
+}
+
+// DesiredVersion returns the version the service should be running: the tag or digest of
+// ImageRef, or "latest" when it has neither.
+func (s ServiceSpec) DesiredVersion() string {
+	if tag := imageTag(s.ImageRef()); tag != "" {
+		return tag
+	}
+	return "latest"
+}
+
+// imageTag returns the digest or tag of an image reference, or "" if it has neither.
+func imageTag(image string) string {
+	if i := strings.LastIndex(image, "@"); i >= 0 {
+		return image[i+1:]
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return ""
+}
+
+// Supported database engines
+const (
+	DatabaseEnginePostgres = "postgres"
+	DatabaseEngineMySQL    = "mysql"
+	DatabaseEngineRedis    = "redis"
+	DatabaseEngineMongoDB  = "mongodb"
+)
+
+// DefaultDatabasePort returns the standard listen port for an engine, or 0 if the engine is unknown.
+// An empty engine is treated as postgres.
+func DefaultDatabasePort(engine string) int32 {
+	switch engine {
+	case "", DatabaseEnginePostgres:
+		return 5432
+	case DatabaseEngineMySQL:
+		return 3306
+	case DatabaseEngineRedis:
+		return 6379
+	case DatabaseEngineMongoDB:
+		return 27017
+	}
+	return 0
 }
 
 // DatabaseSpec defines a database service managed by the platform
 type DatabaseSpec struct {
 	// Name of the database service
 	Name string `json:"name"`
+	// Engine selects env var mapping, readiness check, init runner and default port
+	// +kubebuilder:validation:Enum=postgres;mysql;redis;mongodb
+	// +kubebuilder:default=postgres
+	// +optional
+	Engine string `json:"engine,omitempty"`
 	// Image is the container image (e.g. postgres:16-alpine)
 	Image string `json:"image"`
-	// Port the database listens on
-	Port int32 `json:"port"`
-	// Credentials for connecting (user, password, dbname)
-	Credentials map[string]string `json:"credentials"`
-	// InitSQL is inline DDL to run once after the database is ready
+	// Port the database listens on (defaults to the engine's standard port)
+	// +optional
+	Port int32 `json:"port,omitempty"`
+	// Credentials for connecting (user, password, dbname).
+	// Inline values are copied into a managed Secret so they never appear in pod specs;
+	// prefer CredentialsSecretRef to keep them off the App as well.
+	// +optional
+	Credentials map[string]string `json:"credentials,omitempty"`
+	// CredentialsSecretRef names a Secret in the App's namespace holding the user, password and dbname keys.
+	// When neither this nor Credentials is set, the controller generates a Secret named <app>-<name>-credentials.
+	// +optional
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+	// InitSQL is inline DDL (or redis-cli / mongosh commands) to run once after the database is ready.
+	// It is applied as a migration with version "initSQL" ahead of Migrations.
 	// +optional
 	InitSQL string `json:"initSQL,omitempty"`
+	// Migrations are applied in order, each at most once. Applied versions are tracked in
+	// status.migrations; adding an entry runs only that entry.
+	// +optional
+	Migrations []MigrationSpec `json:"migrations,omitempty"`
+
+	// Storage keeps the database's data on a PersistentVolumeClaim; the database then runs as a
+	// StatefulSet instead of a Deployment
+	// +optional
+	Storage *DatabaseStorageSpec `json:"storage,omitempty"`
+
+	WorkloadSpec `json:",inline"`
+}
+
+// Storage retention policies applied when the App is deleted
+const (
+	RetentionPolicyDelete = "Delete"
+	RetentionPolicyRetain = "Retain"
+)
+
+// DatabaseStorageSpec requests a persistent volume for a database
+type DatabaseStorageSpec struct {
+	// Size of the volume
+	Size resource.Quantity `json:"size"`
+
+	// StorageClassName selects the storage class (empty uses the cluster default)
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// RetentionPolicy decides whether the volume is deleted along with the App or kept for reuse
+	// by an App of the same name
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +kubebuilder:default=Delete
+	// +optional
+	RetentionPolicy string `json:"retentionPolicy,omitempty"`
+}
+
+// MigrationSpec is one versioned schema change. Exactly one of SQL or ConfigMapRef must be set.
+type MigrationSpec struct {
+	// Version identifies the migration and must be unique within the database
+	Version string `json:"version"`
+	// SQL is the inline script (or redis-cli / mongosh commands)
+	// +optional
+	SQL string `json:"sql,omitempty"`
+	// ConfigMapRef reads the script from a key of a ConfigMap in the App's namespace
+	// +optional
+	ConfigMapRef *corev1.ConfigMapKeySelector `json:"configMapRef,omitempty"`
+}
+
+// Migration states reported in AppStatus.Migrations
+const (
+	MigrationStatePending = "Pending"
+	MigrationStateRunning = "Running"
+	MigrationStateApplied = "Applied"
+	MigrationStateFailed  = "Failed"
+)
+
+// MigrationStatus records the state of one migration against one database
+type MigrationStatus struct {
+	// Database is the name of the database as declared in the spec
+	Database string `json:"database"`
+
+	// Version of the migration
+	Version string `json:"version"`
+
+	// Hash is the sha256 of the script that was (or is being) applied
+	Hash string `json:"hash"`
+
+	// State of the migration
+	// +kubebuilder:validation:Enum=Pending;Running;Applied;Failed
+	State string `json:"state"`
+
+	// AppliedAt is when the migration Job completed
+	// +optional
+	AppliedAt *metav1.Time `json:"appliedAt,omitempty"`
+
+	// Message explains a pending or failed migration, or a script changed after it was applied
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// Condition types reported on App.Status.Conditions
+const (
+	// AppConditionReady is True when every service and database is ready
+	AppConditionReady = "Ready"
+	// AppConditionProgressing is True while any component is still rolling out or blocked on dependencies
+	AppConditionProgressing = "Progressing"
+	// AppConditionDegraded is True when any component has failed (image pull, crash loop, failed migration)
+	AppConditionDegraded = "Degraded"
+	// AppConditionDrifted is True when workloads changed out of band were reverted since the last spec change
+	AppConditionDrifted = "Drifted"
+	// AppConditionPaused is True while spec.paused holds reconciliation of the workloads
+	AppConditionPaused = "Paused"
+	// AppConditionTemplateRendered is False when spec.template cannot be rendered; the App keeps its last rendering
+	AppConditionTemplateRendered = "TemplateRendered"
+)
+
+// Component kinds and phases reported in AppStatus.Components
+const (
+	ComponentKindService  = "Service"
+	ComponentKindDatabase = "Database"
+
+	ComponentPhaseReady       = "Ready"
+	ComponentPhaseProgressing = "Progressing"
+	ComponentPhaseDegraded    = "Degraded"
+	ComponentPhaseBlocked     = "Blocked"
+)
+
+// Rollout phases reported in ComponentStatus.Rollout
+const (
+	// RolloutPhaseAwaitingPromotion: the new version runs next to the stable one, waiting for promote or abort
+	RolloutPhaseAwaitingPromotion = "AwaitingPromotion"
+	// RolloutPhasePromoting: a blue/green Service serves the preview while the stable Deployment catches up
+	RolloutPhasePromoting = "Promoting"
+	// RolloutPhaseAborted: the new version was abandoned and the stable one serves alone until the spec changes
+	RolloutPhaseAborted = "Aborted"
+)
+
+// RolloutStatus reports a canary or blue/green rollout of a service
+type RolloutStatus struct {
+	// Strategy of the rollout
+	// +kubebuilder:validation:Enum=Canary;BlueGreen
+	Strategy string `json:"strategy"`
+
+	// Phase of the rollout
+	// +kubebuilder:validation:Enum=AwaitingPromotion;Promoting;Aborted
+	Phase string `json:"phase"`
+
+	// StableVersion is the version of the stable Deployment
+	// +optional
+	StableVersion string `json:"stableVersion,omitempty"`
+
+	// NewVersion is the version being rolled out
+	// +optional
+	NewVersion string `json:"newVersion,omitempty"`
+
+	// NewReplicas is the replica count of the canary or preview Deployment
+	// +optional
+	NewReplicas int32 `json:"newReplicas,omitempty"`
+
+	// NewReadyReplicas is the number of ready canary or preview pods
+	// +optional
+	NewReadyReplicas int32 `json:"newReadyReplicas,omitempty"`
+}
+
+// ComponentStatus is the observed readiness of a single service or database
+type ComponentStatus struct {
+	// Name of the service or database as declared in the spec
+	Name string `json:"name"`
+
+	// Kind of the component
+	// +kubebuilder:validation:Enum=Service;Database
+	Kind string `json:"kind"`
+
+	// Phase summarizes the component state
+	// +kubebuilder:validation:Enum=Ready;Progressing;Degraded;Blocked
+	Phase string `json:"phase"`
+
+	// Ready is true when all desired replicas are ready and all migrations are applied
+	Ready bool `json:"ready"`
+
+	// DesiredReplicas is the replica count requested for the workload
+	DesiredReplicas int32 `json:"desiredReplicas"`
+
+	// ReadyReplicas is the number of pods passing readiness
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// UpdatedReplicas is the number of pods running the latest pod template
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+
+	// Version is the version the service is being rolled to
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// ObservedVersion is the version every ready replica was last seen running.
+	// It changes only once a rollout completes.
+	// +optional
+	ObservedVersion string `json:"observedVersion,omitempty"`
+
+	// Schema is the state of the database's schema migrations
+	// +kubebuilder:validation:Enum=Pending;Running;Applied;Failed
+	// +optional
+	Schema string `json:"schema,omitempty"`
+
+	// Rollout is set while a canary or blue/green rollout of the service is in progress or aborted
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+
+	// BlockedBy lists the dependencies that are holding back this component's rollout
+	// +optional
+	BlockedBy []string `json:"blockedBy,omitempty"`
+
+	// Message explains why the component is not ready
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // AppStatus defines the observed state of App.
@@ -68,7 +492,15 @@ type AppStatus struct {
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 
-	// LastChecked is the timestamp of the last successful health probe
+	// Components reports readiness per service and database
+	// +optional
+	Components []ComponentStatus `json:"components,omitempty"`
+
+	// Migrations records every migration run per database
+	// +optional
+	Migrations []MigrationStatus `json:"migrations,omitempty"`
+
+	// LastChecked is the timestamp of the last health evaluation
 	// +optional
 	LastChecked *metav1.Time `json:"lastChecked,omitempty"`
 
@@ -80,6 +512,10 @@ type AppStatus struct {
 	// +kubebuilder:validation:Enum=Healthy;Unhealthy;Unknown
 	// +optional
 	Health string `json:"health,omitempty"`
+
+	// TemplateHash identifies the services and databases last rendered from spec.template
+	// +optional
+	TemplateHash string `json:"templateHash,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -87,7 +523,10 @@ type AppStatus struct {
 // +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.spec.targetURL`
 // +kubebuilder:printcolumn:name="Env",type=string,JSONPath=`.spec.environment`
 // +kubebuilder:printcolumn:name="Health",type=string,JSONPath=`.status.health`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 // +kubebuilder:printcolumn:name="Endpoints",type=integer,JSONPath=`.status.endpointCount`
+// +kubebuilder:printcolumn:name="Paused",type=boolean,JSONPath=`.spec.paused`,priority=1
+// +kubebuilder:printcolumn:name="Template",type=string,JSONPath=`.spec.template.name`,priority=1
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // App is the Schema for the apps API
@@ -0,0 +1,79 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// TemplateParameter declares a value supplied by the Apps that use an AppTemplate
+type TemplateParameter struct {
+	// Name is referenced from string values of the template as ${name}. A value that is exactly
+	// ${{name}} is replaced by the parameter parsed as JSON, for replicas, ports and other non-strings.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z_][A-Za-z0-9_]*$`
+	Name string `json:"name"`
+
+	// Description of the parameter for template users
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Default is used when the App does not set the parameter
+	// +optional
+	Default *string `json:"default,omitempty"`
+
+	// Required parameters without a default must be set by every App; other unset parameters render as ""
+	// +optional
+	Required bool `json:"required,omitempty"`
+}
+
+// AppTemplateSpec defines the desired state of AppTemplate
+type AppTemplateSpec struct {
+	// Parameters that may be referenced from the template
+	// +optional
+	Parameters []TemplateParameter `json:"parameters,omitempty"`
+
+	// Template is an AppSpec (services and databases) whose string values may reference parameters
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	Template runtime.RawExtension `json:"template"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// AppTemplate is the Schema for the apptemplates API
+type AppTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AppTemplateSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AppTemplateList contains a list of AppTemplate
+type AppTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AppTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AppTemplate{}, &AppTemplateList{})
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CronTestRun concurrency policies
+const (
+	// ConcurrencyAllow starts a TestRun on schedule even while earlier ones still run
+	ConcurrencyAllow = "Allow"
+	// ConcurrencyForbid skips a scheduled TestRun while an earlier one still runs
+	ConcurrencyForbid = "Forbid"
+	// ConcurrencyReplace deletes the TestRuns still running before starting the scheduled one
+	ConcurrencyReplace = "Replace"
+)
+
+// CronTestRunSpec defines the desired state of CronTestRun
+type CronTestRunSpec struct {
+	// Schedule in cron format, such as "0 * * * *" or "@hourly". A CRON_TZ=<zone> prefix sets the
+	// time zone, which is the controller manager's (UTC) otherwise.
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// StartingDeadlineSeconds is how late a scheduled TestRun may still start; a run missed by more is
+	// skipped
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
+	// ConcurrencyPolicy decides what happens when a run is due while an earlier one still runs
+	// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+	// +kubebuilder:default=Allow
+	ConcurrencyPolicy string `json:"concurrencyPolicy,omitempty"`
+
+	// Suspend stops starting new TestRuns; those already running are left alone
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// SuccessfulRunsHistoryLimit is the number of passed TestRuns to keep
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=3
+	SuccessfulRunsHistoryLimit *int32 `json:"successfulRunsHistoryLimit,omitempty"`
+
+	// FailedRunsHistoryLimit is the number of failed TestRuns to keep
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	FailedRunsHistoryLimit *int32 `json:"failedRunsHistoryLimit,omitempty"`
+
+	// Template of the TestRuns to start
+	Template TestRunSpec `json:"template"`
+}
+
+// CronTestRunStatus defines the observed state of CronTestRun
+type CronTestRunStatus struct {
+	// Active lists the TestRuns started on schedule that are still running
+	// +optional
+	Active []string `json:"active,omitempty"`
+
+	// LastScheduleTime is when a TestRun was last started on schedule
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastRun is the most recently scheduled TestRun that finished
+	// +optional
+	LastRun string `json:"lastRun,omitempty"`
+
+	// LastState is the state LastRun finished in
+	// +optional
+	LastState string `json:"lastState,omitempty"`
+
+	// LastResult is the result of LastRun
+	// +optional
+	LastResult string `json:"lastResult,omitempty"`
+
+	// Message explains why no TestRun can be scheduled, such as an invalid schedule
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+// +kubebuilder:printcolumn:name="Suspend",type=boolean,JSONPath=`.spec.suspend`
+// +kubebuilder:printcolumn:name="Last Schedule",type=date,JSONPath=`.status.lastScheduleTime`
+// +kubebuilder:printcolumn:name="Last State",type=string,JSONPath=`.status.lastState`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// CronTestRun is the Schema for the crontestruns API
+type CronTestRun struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CronTestRunSpec   `json:"spec,omitempty"`
+	Status CronTestRunStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CronTestRunList contains a list of CronTestRun
+type CronTestRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CronTestRun `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CronTestRun{}, &CronTestRunList{})
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Ports of the fault-injection proxy run next to a service. The Service targets the proxy ports, which
+// forward to the service's own port and grpcPort, so a service may not listen on them itself.
+const (
+	// FaultProxyAdminPort serves the proxy's admin API: GET, PUT and DELETE /faults
+	FaultProxyAdminPort int32 = 15000
+	// FaultProxyHTTPPort fronts the service's port
+	FaultProxyHTTPPort int32 = 15001
+	// FaultProxyGRPCPort fronts the service's grpcPort
+	FaultProxyGRPCPort int32 = 15002
+)
+
+// FaultProxyContainerName names the proxy container added to the pods of a service with fault injection
+const FaultProxyContainerName = "fault-proxy"
+
+// FaultInjectionSpec puts a fault-injection proxy in front of a service. Tests change its faults at
+// runtime; they return to Faults when the TestRun ends.
+type FaultInjectionSpec struct {
+	// Faults applied from the start, and restored by chaos.reset
+	// +optional
+	Faults *FaultSpec `json:"faults,omitempty"`
+}
+
+// FaultSpec describes the faults the proxy injects into each request it forwards
+type FaultSpec struct {
+	// Latency holds every request back before it is forwarded
+	// +optional
+	Latency *metav1.Duration `json:"latency,omitempty"`
+
+	// Jitter adds up to this much random latency on top of Latency
+	// +optional
+	Jitter *metav1.Duration `json:"jitter,omitempty"`
+
+	// ErrorPercent of requests are answered by the proxy with ErrorStatus instead of being forwarded.
+	// gRPC calls fail with UNAVAILABLE.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ErrorPercent int32 `json:"errorPercent,omitempty"`
+
+	// ErrorStatus is the HTTP status of injected errors (default 503)
+	// +optional
+	ErrorStatus int32 `json:"errorStatus,omitempty"`
+
+	// ResetPercent of requests have their connection (or HTTP/2 stream) reset without a response
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ResetPercent int32 `json:"resetPercent,omitempty"`
+
+	// Bandwidth caps the bytes per second of each response, e.g. 64Ki
+	// +optional
+	Bandwidth *resource.Quantity `json:"bandwidth,omitempty"`
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Service kinds
+const (
+	// ServiceKindContainer runs the service's own image
+	ServiceKindContainer = "Container"
+	// ServiceKindMock runs the built-in Topas stub server in place of a third-party API
+	ServiceKindMock = "Mock"
+)
+
+// MockAdminPrefix is the path under which a mock's stub server serves its admin API (health, runtime
+// stubs and the request journal) instead of stubs
+const MockAdminPrefix = "/__topas/"
+
+// MockDescriptorsFile is the file a mock's stub server reads its gRPC FileDescriptorSet from
+const MockDescriptorsFile = "descriptors.pb"
+
+// MockSpec configures the stub server behind a mock service. Stubs are tried in order: those added at
+// runtime from Lua first, then Stubs, then StubsFrom.
+type MockSpec struct {
+	// Stubs are canned responses served from the start
+	// +optional
+	Stubs []StubSpec `json:"stubs,omitempty"`
+
+	// StubsFrom reads more stubs from a ConfigMap key holding a JSON or YAML list. Changes are picked
+	// up without restarting the mock.
+	// +optional
+	StubsFrom *corev1.ConfigMapKeySelector `json:"stubsFrom,omitempty"`
+
+	// DescriptorsFrom reads a binary FileDescriptorSet (protoc --descriptor_set_out --include_imports)
+	// from a ConfigMap key. It is needed to answer gRPC calls with messages, and is served over
+	// reflection so clients can discover the mocked services.
+	// +optional
+	DescriptorsFrom *corev1.ConfigMapKeySelector `json:"descriptorsFrom,omitempty"`
+}
+
+// StubSpec is one canned response of a mock service
+type StubSpec struct {
+	// Name identifies the stub in the request journal
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Request selects the calls this stub answers
+	Request StubRequest `json:"request"`
+
+	// Response is what the stub answers with
+	// +optional
+	Response StubResponse `json:"response,omitempty"`
+}
+
+// StubRequest matches an HTTP request or gRPC call. Every field that is set must match; an empty
+// matcher accepts any HTTP request.
+type StubRequest struct {
+	// Method is the HTTP method
+	// +optional
+	Method string `json:"method,omitempty"`
+
+	// Path must equal the request path
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// PathPattern is a regular expression the request path must match
+	// +optional
+	PathPattern string `json:"pathPattern,omitempty"`
+
+	// Query parameters that must be present with these values
+	// +optional
+	Query map[string]string `json:"query,omitempty"`
+
+	// Headers that must be present with these values
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// BodyContains must appear in the request body. For gRPC the body is the request message as JSON.
+	// +optional
+	BodyContains string `json:"bodyContains,omitempty"`
+
+	// GRPCMethod matches gRPC calls to a full method name such as /payments.v1.Payments/Charge.
+	// A stub with a GRPCMethod never matches HTTP requests.
+	// +optional
+	GRPCMethod string `json:"grpcMethod,omitempty"`
+}
+
+// StubResponse is a canned HTTP response or gRPC reply
+type StubResponse struct {
+	// Status is the HTTP status code (default 200)
+	// +optional
+	Status int32 `json:"status,omitempty"`
+
+	// Headers are set on the HTTP response, or sent as gRPC response metadata
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body is sent verbatim over HTTP. For gRPC it is the response message as JSON, or the error
+	// message when GRPCCode is set.
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// GRPCCode answers a gRPC call with this status code instead of a message
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=16
+	// +optional
+	GRPCCode int32 `json:"grpcCode,omitempty"`
+
+	// Delay holds the response back, e.g. to trip client timeouts
+	// +optional
+	Delay *metav1.Duration `json:"delay,omitempty"`
+}
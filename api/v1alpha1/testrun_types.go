@@ -33,11 +33,78 @@ type GitSource struct {
 	// +kubebuilder:validation:Required
 	Path string `json:"path"`
 
-	// Revision to checkout (branch, tag, sha)
-	// +kubebuilder:default=main
+	// Revision to checkout (branch, tag, sha); the repository's default branch when empty
+	// +optional
 	Revision string `json:"revision,omitempty"`
 }
 
+// Environment isolation modes for a TestRun's App clone
+const (
+	// IsolationNamespace clones the App into a fresh namespace created for the run
+	IsolationNamespace = "Namespace"
+	// IsolationSuffix clones the App next to the original under a name with a unique suffix
+	IsolationSuffix = "Suffix"
+)
+
+// App access modes of a TestRun
+const (
+	// AppAccessShared runs only read the App, alongside each other
+	AppAccessShared = "Shared"
+	// AppAccessExclusive runs may change the App and hold its lease while they run
+	AppAccessExclusive = "Exclusive"
+)
+
+// EnvironmentSpec asks for an ephemeral clone of the target App to run the test against
+type EnvironmentSpec struct {
+	// Isolation selects where the clone lives: a fresh namespace, or the TestRun's
+	// namespace under a suffixed name
+	// +kubebuilder:validation:Enum=Namespace;Suffix
+	// +kubebuilder:default=Namespace
+	Isolation string `json:"isolation,omitempty"`
+
+	// KeepOnFailure leaves the clone in place for debugging when the run does not pass.
+	// It is still removed when the TestRun is deleted.
+	// +optional
+	KeepOnFailure bool `json:"keepOnFailure,omitempty"`
+}
+
+// EnvironmentStatus records the App clone a TestRun runs against
+type EnvironmentStatus struct {
+	// AppName is the name of the cloned App
+	AppName string `json:"appName"`
+
+	// Namespace holds the cloned App
+	Namespace string `json:"namespace"`
+
+	// Retained is set when the clone was kept after a failed run
+	// +optional
+	Retained bool `json:"retained,omitempty"`
+}
+
+// Retry backoff policies
+const (
+	// BackoffFixed waits the same delay before every retry
+	BackoffFixed = "Fixed"
+	// BackoffExponential doubles the delay after every failed attempt, up to maxDelay
+	BackoffExponential = "Exponential"
+)
+
+// RetryBackoff spaces out the attempts of a failing run
+type RetryBackoff struct {
+	// Policy keeps the delay fixed or doubles it after every failed attempt
+	// +kubebuilder:validation:Enum=Fixed;Exponential
+	// +kubebuilder:default=Exponential
+	Policy string `json:"policy,omitempty"`
+
+	// Delay before the first retry
+	// +kubebuilder:default="10s"
+	Delay string `json:"delay,omitempty"`
+
+	// MaxDelay caps the exponential delay
+	// +kubebuilder:default="5m"
+	MaxDelay string `json:"maxDelay,omitempty"`
+}
+
 // TestRunSpec defines the desired state of TestRun
 type TestRunSpec struct {
 	// AppName is the name of the target App CR to test against
@@ -55,14 +122,77 @@ type TestRunSpec struct {
 	// Timeout for the test execution (default 60s)
 	// +kubebuilder:default="60s"
 	Timeout string `json:"timeout,omitempty"`
+
+	// Environment runs the test against an ephemeral clone of the App instead of the
+	// shared one, so parallel runs cannot corrupt each other's data
+	// +optional
+	Environment *EnvironmentSpec `json:"environment,omitempty"`
+
+	// Tags selects the test cases to run: a case runs when its name, or that of a describe block it
+	// is nested in, holds one of the tags as #tag. The others are reported Skipped. Scripts that do
+	// not declare test cases run as a whole.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+
+	// Retries is the number of times a failed run is executed again in a fresh runner pod. A run that
+	// passes after failing ends Flaky.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	Retries int32 `json:"retries,omitempty"`
+
+	// Backoff spaces out the retries; it defaults to an exponential backoff from 10s up to 5m
+	// +optional
+	Backoff *RetryBackoff `json:"backoff,omitempty"`
+
+	// AppAccess declares whether the run changes its App. Shared runs only read it and refuse sut.apply
+	// and the other sut calls that change it. Exclusive runs start once they hold the App's lease, while
+	// no other run uses the App, and keep others from starting until they finish. Runs against their own
+	// clone of the App need no lease.
+	// +kubebuilder:validation:Enum=Shared;Exclusive
+	// +kubebuilder:default=Shared
+	AppAccess string `json:"appAccess,omitempty"`
+
+	// Priority orders the queue of runs waiting for a concurrency slot: higher priorities start first,
+	// and runs of the same priority start in creation order
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// Params are handed to the script as the read-only params table
+	// +optional
+	Params map[string]string `json:"params,omitempty"`
+
+	// SecretRefs names the Secrets of the run's namespace the script may read with secrets.get. Their
+	// values are masked in the run's output and results.
+	// +optional
+	SecretRefs []SecretRef `json:"secretRefs,omitempty"`
+}
+
+// SecretRef selects a Secret, or some of its keys, for a script to read
+type SecretRef struct {
+	// Name of the Secret, in the namespace of the TestRun
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Keys limits the keys of the Secret the script may read; all of them when empty
+	// +optional
+	Keys []string `json:"keys,omitempty"`
 }
 
+// MaxConcurrentTestRunsAnnotation on a Namespace bounds the TestRuns of the namespace that run at once
+const MaxConcurrentTestRunsAnnotation = "topas.io/max-concurrent-testruns"
+
 // TestRunStatus defines the observed state of TestRun
 type TestRunStatus struct {
-	// State of the test execution
-	// +kubebuilder:validation:Enum=Pending;Running;Passed;Failed;Error
+	// State of the test execution. Flaky is a run that passed on a retry after failing.
+	// +kubebuilder:validation:Enum=Pending;Running;Passed;Flaky;Failed;Error
 	State string `json:"state,omitempty"`
 
+	// QueuePosition is the place of a Pending run in the queue of runs waiting for a concurrency slot,
+	// 1 being the next to start. It is unset once the run got its slot.
+	// +optional
+	QueuePosition int32 `json:"queuePosition,omitempty"`
+
 	// RunnerPod is the name of the pod executing the test
 	// +optional
 	RunnerPod string `json:"runnerPod,omitempty"`
@@ -78,12 +208,156 @@ type TestRunStatus struct {
 	// Result summary or error message
 	// +optional
 	Result string `json:"result,omitempty"`
+
+	// Environment is the App clone the runner was pointed at, when spec.environment is set
+	// +optional
+	Environment *EnvironmentStatus `json:"environment,omitempty"`
+
+	// Results are the test cases and assertions the runner reported when it finished
+	// +optional
+	Results *TestResults `json:"results,omitempty"`
+
+	// ReportConfigMap is the ConfigMap the runner writes its JUnit XML and JSON reports to
+	// +optional
+	ReportConfigMap string `json:"reportConfigMap,omitempty"`
+
+	// Attempts records the outcome of every runner pod of the run, the last one included
+	// +optional
+	Attempts []TestRunAttempt `json:"attempts,omitempty"`
+
+	// NextAttemptTime is when the next retry starts, while the run backs off after a failed attempt
+	// +optional
+	NextAttemptTime *metav1.Time `json:"nextAttemptTime,omitempty"`
+
+	// Conditions: Succeeded once the run finished, ResultsReported once the runner's results were read
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// TestRunAttempt is the outcome of one runner pod of a run
+type TestRunAttempt struct {
+	// Attempt counts from 1
+	Attempt int32 `json:"attempt"`
+
+	// RunnerPod that executed the attempt
+	RunnerPod string `json:"runnerPod"`
+
+	// +kubebuilder:validation:Enum=Passed;Failed;Error
+	State string `json:"state"`
+
+	// Reason of the Succeeded condition the attempt ended with, such as TestsFailed or Timeout
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Result summary or error message
+	// +optional
+	Result string `json:"result,omitempty"`
+
+	// FailedTestCases names the test cases that failed in the attempt
+	// +optional
+	FailedTestCases []string `json:"failedTestCases,omitempty"`
+
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// TestRun condition types
+const (
+	// TestRunConditionSucceeded is True when the run passed, possibly on a retry, False when it failed or
+	// could not run, and Unknown while it waits to be retried
+	TestRunConditionSucceeded = "Succeeded"
+	// TestRunConditionResultsReported is True when status.results holds the runner's results
+	TestRunConditionResultsReported = "ResultsReported"
+)
+
+// Test case states
+const (
+	TestCasePassed  = "Passed"
+	TestCaseFailed  = "Failed"
+	TestCaseSkipped = "Skipped"
+)
+
+// TestResults is the structured outcome of a run. The runner writes it as JSON to its termination message.
+type TestResults struct {
+	// Summary counts the test cases and assertions
+	Summary TestSummary `json:"summary"`
+
+	// TestCases in the order they ran
+	// +optional
+	TestCases []TestCaseResult `json:"testCases,omitempty"`
+
+	// Error is a failure of the script outside any test case, such as a syntax error
+	// +optional
+	Error *TestFailure `json:"error,omitempty"`
+
+	// Truncated is set when passing test cases were left out to fit the termination message
+	// +optional
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// TestSummary counts the outcome of a run
+type TestSummary struct {
+	Total      int32 `json:"total"`
+	Passed     int32 `json:"passed"`
+	Failed     int32 `json:"failed"`
+	Skipped    int32 `json:"skipped,omitempty"`
+	Assertions int32 `json:"assertions"`
+	// Duration of the whole script
+	Duration metav1.Duration `json:"duration"`
+}
+
+// TestCaseNameSeparator joins the names of the groups a test case is nested in and its own name
+const TestCaseNameSeparator = " > "
+
+// Keys of the report ConfigMap
+const (
+	ReportJSONKey  = "report.json"
+	ReportJUnitKey = "junit.xml"
+)
+
+// TestCaseResult is the outcome of one test case
+type TestCaseResult struct {
+	// Name of the test case, including the names of the groups it is nested in, joined by
+	// TestCaseNameSeparator
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Enum=Passed;Failed;Skipped
+	State string `json:"state"`
+
+	// Assertions checked by the test case
+	Assertions int32 `json:"assertions"`
+
+	Duration metav1.Duration `json:"duration"`
+
+	// Failure explains why the test case failed
+	// +optional
+	Failure *TestFailure `json:"failure,omitempty"`
+}
+
+// TestFailure locates a failed assertion or script error
+type TestFailure struct {
+	Message string `json:"message"`
+
+	// Location is the script position that raised the failure, as file:line
+	// +optional
+	Location string `json:"location,omitempty"`
+
+	// StackTrace is the Lua stack at the time of the failure
+	// +optional
+	StackTrace string `json:"stackTrace,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
 // +kubebuilder:printcolumn:name="Result",type=string,JSONPath=`.status.result`
+// +kubebuilder:printcolumn:name="Queue",type=integer,JSONPath=`.status.queuePosition`,priority=1
+// +kubebuilder:printcolumn:name="Attempts",type=integer,JSONPath=`.status.attempts[-1:].attempt`,priority=1
 // +kubebuilder:printcolumn:name="Pod",type=string,JSONPath=`.status.runnerPod`
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
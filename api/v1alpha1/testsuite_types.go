@@ -0,0 +1,196 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestSuite execution modes
+const (
+	// SuiteModeParallel runs the scripts at the same time, up to spec.maxParallel of them
+	SuiteModeParallel = "Parallel"
+	// SuiteModeSequential runs the scripts one after the other in the order they are listed
+	SuiteModeSequential = "Sequential"
+)
+
+// SuiteScript is one script of a suite: inline, or a path in a git repository. A git path holding glob
+// characters (*, ?, [) runs every matching file as a script of its own.
+type SuiteScript struct {
+	// Name identifies an inline script in the suite status; git scripts are identified by their path
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Script is an inline Lua script
+	// +optional
+	Script string `json:"script,omitempty"`
+
+	// Git source of the script, or of the scripts matching a glob path such as tests/*.lua
+	// +optional
+	Git *GitSource `json:"git,omitempty"`
+}
+
+// TestSuiteSpec defines the desired state of TestSuite
+type TestSuiteSpec struct {
+	// AppName is the name of the App every script runs against
+	// +kubebuilder:validation:Required
+	AppName string `json:"appName"`
+
+	// Scripts to run, each in a TestRun of its own
+	// +kubebuilder:validation:MinItems=1
+	Scripts []SuiteScript `json:"scripts"`
+
+	// Mode runs the scripts in parallel or sequentially
+	// +kubebuilder:validation:Enum=Parallel;Sequential
+	// +kubebuilder:default=Parallel
+	Mode string `json:"mode,omitempty"`
+
+	// MaxParallel bounds the TestRuns running at once in Parallel mode; unset runs them all at once
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxParallel *int32 `json:"maxParallel,omitempty"`
+
+	// FailFast stops starting scripts once one has not passed; the scripts left are reported Skipped
+	// +optional
+	FailFast bool `json:"failFast,omitempty"`
+
+	// Tags selects the test cases to run in every script, see TestRunSpec.Tags
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+
+	// Timeout of each TestRun
+	// +kubebuilder:default="60s"
+	Timeout string `json:"timeout,omitempty"`
+
+	// Environment runs each script against an App clone of its own
+	// +optional
+	Environment *EnvironmentSpec `json:"environment,omitempty"`
+
+	// Retries of each TestRun, see TestRunSpec.Retries
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	Retries int32 `json:"retries,omitempty"`
+
+	// Backoff between the retries of each TestRun
+	// +optional
+	Backoff *RetryBackoff `json:"backoff,omitempty"`
+
+	// AppAccess of each TestRun, see TestRunSpec.AppAccess
+	// +kubebuilder:validation:Enum=Shared;Exclusive
+	// +kubebuilder:default=Shared
+	AppAccess string `json:"appAccess,omitempty"`
+
+	// Params handed to every script, see TestRunSpec.Params
+	// +optional
+	Params map[string]string `json:"params,omitempty"`
+
+	// SecretRefs every script may read, see TestRunSpec.SecretRefs
+	// +optional
+	SecretRefs []SecretRef `json:"secretRefs,omitempty"`
+}
+
+// SuiteRunStatus is the state of one script of a suite
+type SuiteRunStatus struct {
+	// Script is the name of an inline script or the git path of the script
+	Script string `json:"script"`
+
+	// Source is the index of the spec.scripts entry the script comes from
+	Source int32 `json:"source"`
+
+	// TestRun running the script, once it was created
+	// +optional
+	TestRun string `json:"testRun,omitempty"`
+
+	// State of the TestRun, or Skipped when the suite stopped before starting it
+	// +optional
+	State string `json:"state,omitempty"`
+
+	// Result of the TestRun
+	// +optional
+	Result string `json:"result,omitempty"`
+}
+
+// TestSuiteSummary counts the scripts of a suite by outcome
+type TestSuiteSummary struct {
+	Total   int32 `json:"total"`
+	Passed  int32 `json:"passed"`
+	Failed  int32 `json:"failed"`
+	Skipped int32 `json:"skipped"`
+	Running int32 `json:"running"`
+	// Flaky counts the scripts that passed on a retry; they are counted as passed too
+	Flaky int32 `json:"flaky,omitempty"`
+}
+
+// TestSuiteStatus defines the observed state of TestSuite
+type TestSuiteStatus struct {
+	// State of the suite: Passed once every script passed, Failed once every script finished or was
+	// skipped and one did not pass, Error when the scripts could not be resolved or the name of one of
+	// its TestRuns is taken
+	// +kubebuilder:validation:Enum=Pending;Running;Passed;Failed;Error
+	State string `json:"state,omitempty"`
+
+	// Message explains an Error state
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Runs lists the scripts of the suite, globs expanded, in the order they run
+	// +optional
+	Runs []SuiteRunStatus `json:"runs,omitempty"`
+
+	// Summary counts the scripts by outcome
+	// +optional
+	Summary TestSuiteSummary `json:"summary,omitempty"`
+
+	// StartTime is when the first TestRun was created
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the last TestRun finished
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Passed",type=integer,JSONPath=`.status.summary.passed`
+// +kubebuilder:printcolumn:name="Failed",type=integer,JSONPath=`.status.summary.failed`
+// +kubebuilder:printcolumn:name="Total",type=integer,JSONPath=`.status.summary.total`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// TestSuite is the Schema for the testsuites API
+type TestSuite struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TestSuiteSpec   `json:"spec,omitempty"`
+	Status TestSuiteStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TestSuiteList contains a list of TestSuite
+type TestSuiteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TestSuite `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TestSuite{}, &TestSuiteList{})
+}
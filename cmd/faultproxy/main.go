@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+	"github.com/chakradharkondapalli/topas/pkg/faultproxy"
+)
+
+func main() {
+	adminPort := flag.Int("admin-port", int(appv1alpha1.FaultProxyAdminPort), "Port serving the /faults admin API")
+	faults := flag.String("faults", "", "Faults to inject from the start, as a JSON FaultSpec")
+	var routes []string
+	flag.Func("route", "Forward a port to a local one, as listen:target (repeatable)", func(v string) error {
+		if _, _, ok := strings.Cut(v, ":"); !ok {
+			return fmt.Errorf("route %q must be listen:target", v)
+		}
+		routes = append(routes, v)
+		return nil
+	})
+	flag.Parse()
+
+	// 1. Parse the baseline faults
+	var baseline appv1alpha1.FaultSpec
+	if *faults != "" {
+		if err := json.Unmarshal([]byte(*faults), &baseline); err != nil {
+			fmt.Printf("Invalid --faults: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	proxy := faultproxy.New(baseline)
+
+	// 2. Forward each route to the service on localhost
+	for _, route := range routes {
+		listen, target, _ := strings.Cut(route, ":")
+		srv := &http.Server{Addr: ":" + listen, Handler: proxy.Route("127.0.0.1:" + target), Protocols: faultproxy.Protocols()}
+		go func() {
+			log.Printf("Proxying :%s to :%s", listen, target)
+			if err := srv.ListenAndServe(); err != nil {
+				log.Fatalf("proxy on :%s failed: %v", listen, err)
+			}
+		}()
+	}
+
+	// 3. Serve the admin API
+	log.Printf("Starting fault admin API on :%d", *adminPort)
+	if err := http.ListenAndServe(fmt.Sprintf(":%d", *adminPort), proxy.Admin()); err != nil {
+		log.Fatalf("admin server failed: %v", err)
+	}
+}
@@ -1,23 +1,49 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	lua "github.com/yuin/gopher-lua"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
 	"github.com/chakradharkondapalli/topas/pkg/k8s"
+	lchaos "github.com/chakradharkondapalli/topas/pkg/lua/chaos"
 	ldb "github.com/chakradharkondapalli/topas/pkg/lua/db"
+	lexpect "github.com/chakradharkondapalli/topas/pkg/lua/expect"
 	lhttp "github.com/chakradharkondapalli/topas/pkg/lua/http"
+	lmock "github.com/chakradharkondapalli/topas/pkg/lua/mock"
 	lnet "github.com/chakradharkondapalli/topas/pkg/lua/net" // Added net module import
+	lparams "github.com/chakradharkondapalli/topas/pkg/lua/params"
 	lpm "github.com/chakradharkondapalli/topas/pkg/lua/postman"
+	lresults "github.com/chakradharkondapalli/topas/pkg/lua/results"
+	lsecrets "github.com/chakradharkondapalli/topas/pkg/lua/secrets"
 	lsut "github.com/chakradharkondapalli/topas/pkg/lua/sut"
-	lua "github.com/yuin/gopher-lua"
+	ltest "github.com/chakradharkondapalli/topas/pkg/lua/test"
+	"github.com/chakradharkondapalli/topas/pkg/report"
 )
 
 func main() {
 	scriptPath := flag.String("script", "", "Path to the Lua script")
 	appName := flag.String("app", "", "Name of the App resource")
 	namespace := flag.String("namespace", "default", "Namespace of the App")
+	resultsFile := flag.String("results-file", "", "Write the structured results as JSON to this file (the termination message in a TestRun)")
+	tags := flag.String("tags", "", "Comma-separated tags selecting the test cases to run")
+	reportConfigMap := flag.String("report-configmap", "", "Store the JSON and JUnit reports in this ConfigMap, given as <namespace>/<name>")
+	appAccess := flag.String("app-access", appv1alpha1.AppAccessExclusive, "Access to the App: Shared runs may not change it")
+	appLease := flag.String("app-lease", "", "Lease on the App the run must hold to change it, given as <namespace>/<name>")
+	testRun := flag.String("testrun", "", "Name of the TestRun, the holder of the App lease")
+	secretsDir := flag.String("secrets-dir", "", "Directory holding the Secrets the script may read, one directory per Secret")
+	params := paramFlag{}
+	flag.Var(params, "param", "Param handed to the script as key=value; may be repeated")
 	flag.Parse()
 
 	if *scriptPath == "" || *appName == "" {
@@ -32,19 +58,53 @@ func main() {
 		os.Exit(1)
 	}
 
-	// 2. Initialize Lua State
+	// 2. Mask the secret values the script reads in everything the run prints
+	secretsMod := lsecrets.New(*secretsDir)
+	restoreStdout, err := secretsMod.MaskFile(&os.Stdout)
+	if err != nil {
+		fmt.Printf("Failed to mask output: %v\n", err)
+		os.Exit(1)
+	}
+	restoreStderr, err := secretsMod.MaskFile(&os.Stderr)
+	if err != nil {
+		fmt.Printf("Failed to mask output: %v\n", err)
+		os.Exit(1)
+	}
+	exit := func(code int) {
+		restoreStderr()
+		restoreStdout()
+		os.Exit(code)
+	}
+
+	// 3. Initialize Lua State
 	L := lua.NewState()
 	defer L.Close()
 	L.OpenLibs()
+	recorder := lresults.NewRecorder()
+	recorder.Mask = secretsMod.Mask
+	recorder.OpenAssert(L)
+	recorder.OpenPrint(L)
 
-	// 3. Register Modules
+	// 4. Register Modules
 	sutMod := lsut.New(k8sClient, *appName, *namespace)
+	sutMod.Access = *appAccess
+	if *appLease != "" {
+		leaseNamespace, leaseName, ok := strings.Cut(*appLease, "/")
+		if !ok {
+			fmt.Printf("Invalid --app-lease %q, expected <namespace>/<name>\n", *appLease)
+			exit(1)
+		}
+		sutMod.Lease = &types.NamespacedName{Namespace: leaseNamespace, Name: leaseName}
+		sutMod.Holder = *testRun
+	}
 	L.PreloadModule("sut", sutMod.Loader)
 
 	httpMod := lhttp.New()
+	httpMod.Results = recorder
 	L.PreloadModule("http", httpMod.Loader)
 
 	dbMod := ldb.New()
+	dbMod.Results = recorder
 	L.PreloadModule("db", dbMod.Loader)
 
 	netMod := lnet.New() // Unified Network Client
@@ -53,11 +113,92 @@ func main() {
 	pmMod := lpm.New()
 	L.PreloadModule("postman", pmMod.Loader)
 
-	// 4. Execute Script
+	mockMod := lmock.New(k8sClient, *appName, *namespace)
+	L.PreloadModule("mock", mockMod.Loader)
+
+	chaosMod := lchaos.New(k8sClient, *appName, *namespace)
+	L.PreloadModule("chaos", chaosMod.Loader)
+
+	expectMod := lexpect.New()
+	expectMod.Results = recorder
+	L.PreloadModule("expect", expectMod.Loader)
+
+	testMod := ltest.New()
+	testMod.Results = recorder
+	if *tags != "" {
+		testMod.Tags = strings.Split(*tags, ",")
+	}
+	L.PreloadModule("test", testMod.Loader)
+
+	L.PreloadModule("secrets", secretsMod.Loader)
+
+	paramsMod := lparams.New(params)
+	paramsMod.Open(L)
+	L.PreloadModule("params", paramsMod.Loader)
+
+	// 5. Execute Script, then the test cases it declared
 	fmt.Printf("Executing script: %s for App: %s/%s\n", *scriptPath, *namespace, *appName)
-	if err := L.DoFile(*scriptPath); err != nil {
-		fmt.Printf("Error executing script: %v\n", err)
-		os.Exit(1)
+	scriptErr := L.DoFile(*scriptPath)
+	if scriptErr != nil {
+		fmt.Printf("Error executing script: %v\n", scriptErr)
+	} else {
+		testMod.Run(L)
+	}
+
+	// 6. Report Results
+	results := recorder.Results(filepath.Base(*scriptPath), scriptErr)
+	if *resultsFile != "" {
+		if err := lresults.Write(*resultsFile, results); err != nil {
+			fmt.Printf("Failed to write results: %v\n", err)
+		}
+	}
+	if *reportConfigMap != "" {
+		if err := storeReport(k8sClient, *reportConfigMap, recorder.Report(filepath.Base(*scriptPath), scriptErr)); err != nil {
+			fmt.Printf("Failed to store report: %v\n", err)
+		}
+	}
+	summary := results.Summary
+	fmt.Printf("%d passed, %d failed, %d skipped, %d assertions in %s\n",
+		summary.Passed, summary.Failed, summary.Skipped, summary.Assertions, summary.Duration.Round(time.Millisecond))
+	if scriptErr != nil || summary.Failed > 0 {
+		exit(1)
 	}
 	fmt.Println("Script execution finished successfully")
+	exit(0)
+}
+
+// paramFlag collects the repeated --param key=value flags.
+type paramFlag map[string]string
+
+func (p paramFlag) String() string {
+	return fmt.Sprint(map[string]string(p))
+}
+
+func (p paramFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	p[key] = val
+	return nil
+}
+
+// storeReport writes the reports into the ConfigMap the controller created for them.
+func storeReport(c client.Client, ref string, rep report.Report) error {
+	namespace, name, ok := strings.Cut(ref, "/")
+	if !ok {
+		return fmt.Errorf("invalid ConfigMap %q, expected <namespace>/<name>", ref)
+	}
+	ctx := context.Background()
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, cm); err != nil {
+		return err
+	}
+	rep.TestRun = cm.Labels["testrun"]
+	data, err := report.ConfigMapData(rep)
+	if err != nil {
+		return err
+	}
+	cm.Data = data
+	return c.Update(ctx, cm)
 }
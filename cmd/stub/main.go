@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/chakradharkondapalli/topas/pkg/stub"
+)
+
+func main() {
+	httpPort := flag.Int("http-port", 8080, "Port serving HTTP stubs and the /__topas/ admin API")
+	grpcPort := flag.Int("grpc-port", 0, "Port serving gRPC stubs (0 disables gRPC)")
+	configDir := flag.String("config-dir", "", "Directory holding stub lists and an optional descriptors.pb")
+	flag.Parse()
+
+	// 1. Load the configured stubs and gRPC descriptors
+	srv := stub.New(*configDir)
+	if err := srv.Load(); err != nil {
+		fmt.Printf("Failed to load stubs: %v\n", err)
+		os.Exit(1)
+	}
+	if err := srv.LoadDescriptors(); err != nil {
+		fmt.Printf("Failed to load descriptors: %v\n", err)
+		os.Exit(1)
+	}
+	go srv.Watch(context.Background(), 5*time.Second)
+
+	// 2. Serve gRPC stubs
+	if *grpcPort != 0 {
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", *grpcPort))
+		if err != nil {
+			log.Fatalf("failed to listen on :%d: %v", *grpcPort, err)
+		}
+		go func() {
+			log.Printf("Starting gRPC stub server on :%d", *grpcPort)
+			if err := srv.GRPCServer().Serve(lis); err != nil {
+				log.Fatalf("gRPC server failed: %v", err)
+			}
+		}()
+	}
+
+	// 3. Serve HTTP stubs
+	log.Printf("Starting HTTP stub server on :%d", *httpPort)
+	if err := http.ListenAndServe(fmt.Sprintf(":%d", *httpPort), srv); err != nil {
+		log.Fatalf("HTTP server failed: %v", err)
+	}
+}
@@ -1,19 +1,24 @@
 -- db-test.lua
 -- Integration test: seed items via db.seed(), verify via db.expect() and http.expect().
+-- The database credentials come from the Secret the controller keeps for mock-app's database, and the
+-- host from the db_host param; see db-testrun.yaml for the matching secretRefs and params.
 
-local db   = require("db")
-local http = require("http")
+local db      = require("db")
+local http    = require("http")
+local secrets = require("secrets")
+
+local credentials = "mock-app-db-credentials"
 
 print("=== TOPAS DB Integration Test ===")
 
 -- 1. Connect to the database directly
 print("[1/5] Connecting to database...")
 db.connect({
-    host     = "mock-app-db.default.svc.cluster.local",
+    host     = params.db_host or "mock-app-db.default.svc.cluster.local",
     port     = "5432",
-    user     = "topas",
-    password = "topas",
-    dbname   = "testdb"
+    user     = secrets.get(credentials, "user"),
+    password = secrets.get(credentials, "password"),
+    dbname   = secrets.get(credentials, "dbname")
 })
 print("  ✓ Database connected")
 
@@ -1,4 +1,6 @@
 -- Example Test Script
+-- It changes the App with sut.apply, so it must run as an Exclusive TestRun:
+--   kctrl test schedule --script examples/test.lua --app my-app --exclusive
 local sut = require("sut")
 local http = require("http")
 local db = require("db")
@@ -52,25 +52,35 @@ podman build --no-cache -t "$IMG" "$ROOT"
 info "Loading image into Minikube..."
 podman save "$IMG" | minikube image load --daemon=false -
 
-# ─── 5. Install CRDs ──────────────────────────────────────────────────
+# ─── 5. Install cert-manager (serves the admission webhook certificates) ─
+CERT_MANAGER_VERSION="${CERT_MANAGER_VERSION:-v1.19.2}"
+if kubectl get deployment cert-manager-webhook -n cert-manager >/dev/null 2>&1; then
+    info "cert-manager already installed."
+else
+    info "Installing cert-manager ${CERT_MANAGER_VERSION}..."
+    kubectl apply -f "https://github.com/cert-manager/cert-manager/releases/download/${CERT_MANAGER_VERSION}/cert-manager.yaml"
+fi
+kubectl wait deployment.apps/cert-manager-webhook -n cert-manager --for condition=Available --timeout=5m
+
+# ─── 6. Install CRDs ──────────────────────────────────────────────────
 info "Installing CRDs..."
 make -C "$ROOT" install
 
-# ─── 6. Deploy controller ─────────────────────────────────────────────
+# ─── 7. Deploy controller ─────────────────────────────────────────────
 info "Deploying controller..."
 make -C "$ROOT" deploy CONTAINER_TOOL=podman IMG="$IMG"
 
-# ─── 7. Patch imagePullPolicy ─────────────────────────────────────────
+# ─── 8. Patch imagePullPolicy ─────────────────────────────────────────
 info "Setting imagePullPolicy=Never for local image..."
 kubectl patch deployment topas-controller-manager -n topas-system \
     --type=json \
     -p='[{"op":"replace","path":"/spec/template/spec/containers/0/imagePullPolicy","value":"Never"}]'
 
-# ─── 8. Wait for rollout ──────────────────────────────────────────────
+# ─── 9. Wait for rollout ──────────────────────────────────────────────
 info "Waiting for controller rollout..."
 kubectl rollout status deployment/topas-controller-manager -n topas-system --timeout=90s
 
-# ─── 9. Verify ─────────────────────────────────────────────────────────
+# ─── 10. Verify ─────────────────────────────────────────────────────────
 echo ""
 green "✅ TOPAS deployed successfully!"
 echo ""
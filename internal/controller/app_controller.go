@@ -19,34 +19,53 @@ package controller
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	appsv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
 )
 
+// versionAnnotation records the service version a Deployment and its pods were built for
+const versionAnnotation = "topas.io/version"
+
 // AppReconciler reconciles a App object
 type AppReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder events.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=apps.example.com,resources=apps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.example.com,resources=apps/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=apps.example.com,resources=apps/finalizers,verbs=update
+// +kubebuilder:rbac:groups=apps.example.com,resources=apptemplates,verbs=get;list;watch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;delete
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
 func (r *AppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
@@ -57,74 +76,196 @@ func (r *AppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	// Apply database volume retention before the App and its owned objects go away
+	if !app.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(&app, storageFinalizer) {
+			return ctrl.Result{}, nil
+		}
+		if err := r.applyRetentionPolicy(ctx, &app); err != nil {
+			log.Error(err, "Failed to apply database volume retention policy")
+			return ctrl.Result{}, err
+		}
+		controllerutil.RemoveFinalizer(&app, storageFinalizer)
+		return ctrl.Result{}, r.Update(ctx, &app)
+	}
+
+	// 2-4. Reconcile workloads, unless a test has paused the App to break them on purpose
+	blocked := map[string][]string{}
+	if app.Spec.Paused {
+		log.Info("App is paused, leaving workloads as they are", "name", app.Name)
+		meta.SetStatusCondition(&app.Status.Conditions, metav1.Condition{
+			Type:               appsv1alpha1.AppConditionPaused,
+			Status:             metav1.ConditionTrue,
+			Reason:             "Paused",
+			Message:            "Workloads are not reconciled while spec.paused is set",
+			ObservedGeneration: app.Generation,
+		})
+	} else {
+		meta.RemoveStatusCondition(&app.Status.Conditions, appsv1alpha1.AppConditionPaused)
+		prevDrift := beginDriftCheck(&app)
+		var err error
+		if blocked, err = r.reconcileComponents(ctx, &app); err != nil {
+			return ctrl.Result{}, err
+		}
+		finishDriftCheck(&app, prevDrift)
+	}
+
+	// 5. Update status from the observed Deployments and migrations
+	components, err := r.observeComponents(ctx, &app, blocked)
+	if err != nil {
+		log.Error(err, "Failed to observe App components")
+		return ctrl.Result{}, err
+	}
+	app.Status.Components = components
+	app.Status.EndpointCount = int32(len(app.Spec.Services) + len(app.Spec.Databases))
+	setAppHealth(&app)
+	now := metav1.Now()
+	app.Status.LastChecked = &now
+	if err := r.Status().Update(ctx, &app); err != nil {
+		log.Error(err, "Failed to update App status")
+		return ctrl.Result{}, err
+	}
+
+	log.Info("App reconciled successfully", "name", app.Name, "health", app.Status.Health)
+
+	// Pod failures (crash loops, image pulls) don't always touch the Deployment, so keep polling until ready
+	if app.Status.Health != "Healthy" {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// reconcileComponents brings databases and services in line with the App spec and returns the services
+// held back on their dependencies.
+func (r *AppReconciler) reconcileComponents(ctx context.Context, app *appsv1alpha1.App) (map[string][]string, error) {
+	log := logf.FromContext(ctx)
+
+	// Render spec.template into services and databases before anything reads them
+	if err := r.renderAppTemplate(ctx, app); err != nil {
+		log.Error(err, "Failed to render App template")
+		return nil, err
+	}
+
+	if hasPersistentDatabase(app) != controllerutil.ContainsFinalizer(app, storageFinalizer) {
+		if hasPersistentDatabase(app) {
+			controllerutil.AddFinalizer(app, storageFinalizer)
+		} else {
+			controllerutil.RemoveFinalizer(app, storageFinalizer)
+		}
+		// Keep the template outcome recorded above
+		if err := r.updateKeepingStatus(ctx, app); err != nil {
+			return nil, err
+		}
+	}
+
 	log.Info("Reconciling App", "name", app.Name, "services", len(app.Spec.Services), "databases", len(app.Spec.Databases))
 
 	managedResources := make(map[string]bool)
 
 	// 2. Reconcile databases first (services may depend on them)
+	declared := map[string]bool{}
+	for _, db := range app.Spec.Databases {
+		declared[db.Name] = true
+	}
+	app.Status.Migrations = slices.DeleteFunc(app.Status.Migrations, func(st appsv1alpha1.MigrationStatus) bool {
+		return !declared[st.Database]
+	})
 	for _, db := range app.Spec.Databases {
 		name := fmt.Sprintf("%s-%s", app.Name, db.Name)
 		managedResources[name] = true
 
-		if err := r.reconcileDatabase(ctx, &app, db, name); err != nil {
+		if err := r.reconcileDatabase(ctx, app, db, name); err != nil {
 			log.Error(err, "Failed to reconcile Database", "name", name)
-			return ctrl.Result{}, err
+			return nil, err
 		}
 	}
 
-	// 3. Reconcile each service → Deployment + Service
+	// 3. Reconcile each service → Deployment + Service, holding back Deployments whose dependencies aren't ready
+	blocked := map[string][]string{}
 	for _, svc := range app.Spec.Services {
 		name := fmt.Sprintf("%s-%s", app.Name, svc.Name)
 		managedResources[name] = true
+		managedResources[rolloutName(name, appsv1alpha1.RolloutStrategyCanary)] = true
+		managedResources[rolloutName(name, appsv1alpha1.RolloutStrategyBlueGreen)] = true
+
+		// Mocks read their spec stubs from a ConfigMap, written before the Deployment that mounts it
+		if svc.IsMock() {
+			if err := r.reconcileStubs(ctx, app, svc, name); err != nil {
+				log.Error(err, "Failed to reconcile stubs", "name", name)
+				return nil, err
+			}
+		} else if err := r.deleteIfExists(ctx, &corev1.ConfigMap{}, stubsConfigMapName(name), app.Namespace); err != nil {
+			return nil, err
+		}
 
-		if err := r.reconcileDeployment(ctx, &app, svc, name); err != nil {
+		waitingOn, err := r.unreadyDependencies(ctx, app, svc)
+		if err != nil {
+			return nil, err
+		}
+		if len(waitingOn) > 0 {
+			log.Info("Holding back Deployment until dependencies are ready", "name", name, "waitingOn", waitingOn)
+			blocked[svc.Name] = waitingOn
+		} else if err := r.reconcileDeployment(ctx, app, svc, name); err != nil {
 			log.Error(err, "Failed to reconcile Deployment", "name", name)
-			return ctrl.Result{}, err
+			return nil, err
 		}
-		if err := r.reconcileService(ctx, &app, svc, name); err != nil {
+		if err := r.reconcileService(ctx, app, svc, name); err != nil {
 			log.Error(err, "Failed to reconcile Service", "name", name)
-			return ctrl.Result{}, err
+			return nil, err
 		}
 	}
 
-	// 4. Clean up orphaned Deployments (services removed from spec)
+	// 4. Clean up orphaned Deployments and StatefulSets (components removed from spec)
 	var depList appsv1.DeploymentList
 	if err := r.List(ctx, &depList, client.InNamespace(app.Namespace), client.MatchingLabels{
 		"app.kubernetes.io/managed-by": "topas",
 		"app.kubernetes.io/part-of":    app.Name,
 	}); err != nil {
-		return ctrl.Result{}, err
+		return nil, err
 	}
 	for i := range depList.Items {
 		dep := &depList.Items[i]
 		if !managedResources[dep.Name] {
 			log.Info("Deleting orphaned Deployment", "name", dep.Name)
 			if err := r.Delete(ctx, dep); err != nil && !errors.IsNotFound(err) {
-				return ctrl.Result{}, err
+				return nil, err
 			}
 			orphanSvc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: dep.Name, Namespace: app.Namespace}}
 			if err := r.Delete(ctx, orphanSvc); err != nil && !errors.IsNotFound(err) {
-				return ctrl.Result{}, err
+				return nil, err
+			}
+			if err := r.deleteIfExists(ctx, &corev1.ConfigMap{}, stubsConfigMapName(dep.Name), app.Namespace); err != nil {
+				return nil, err
 			}
 		}
 	}
 
-	// 5. Update status
-	app.Status.EndpointCount = int32(len(app.Spec.Services) + len(app.Spec.Databases))
-	app.Status.Health = "Healthy"
-	if err := r.Status().Update(ctx, &app); err != nil {
-		log.Error(err, "Failed to update App status")
-		return ctrl.Result{}, err
+	var stsList appsv1.StatefulSetList
+	if err := r.List(ctx, &stsList, client.InNamespace(app.Namespace), client.MatchingLabels{
+		"app.kubernetes.io/managed-by": "topas",
+		"app.kubernetes.io/part-of":    app.Name,
+	}); err != nil {
+		return nil, err
+	}
+	for i := range stsList.Items {
+		sts := &stsList.Items[i]
+		if !managedResources[sts.Name] {
+			log.Info("Deleting orphaned StatefulSet", "name", sts.Name)
+			if err := r.Delete(ctx, sts); err != nil && !errors.IsNotFound(err) {
+				return nil, err
+			}
+			orphanSvc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: sts.Name, Namespace: app.Namespace}}
+			if err := r.Delete(ctx, orphanSvc); err != nil && !errors.IsNotFound(err) {
+				return nil, err
+			}
+		}
 	}
 
-	log.Info("App reconciled successfully", "name", app.Name)
-	return ctrl.Result{}, nil
+	return blocked, nil
 }
 
-// reconcileDatabase creates a Deployment + Service for a database, and a schema init Job if InitSQL is set.
+// reconcileDatabase creates a Deployment + Service for a database, then applies its pending migrations.
 func (r *AppReconciler) reconcileDatabase(ctx context.Context, app *appsv1alpha1.App, db appsv1alpha1.DatabaseSpec, name string) error {
-	log := logf.FromContext(ctx)
-
 	labels := map[string]string{
 		"app":                          db.Name,
 		"app.kubernetes.io/name":       db.Name,
@@ -133,61 +274,58 @@ func (r *AppReconciler) reconcileDatabase(ctx context.Context, app *appsv1alpha1
 		"app.kubernetes.io/component":  "database",
 	}
 
-	// Build env vars from credentials
-	envVars := []corev1.EnvVar{}
-	if user, ok := db.Credentials["user"]; ok {
-		envVars = append(envVars, corev1.EnvVar{Name: "POSTGRES_USER", Value: user})
-	}
-	if pass, ok := db.Credentials["password"]; ok {
-		envVars = append(envVars, corev1.EnvVar{Name: "POSTGRES_PASSWORD", Value: pass})
-	}
-	if dbname, ok := db.Credentials["dbname"]; ok {
-		envVars = append(envVars, corev1.EnvVar{Name: "POSTGRES_DB", Value: dbname})
+	engine := engineFor(db)
+	port := databasePort(db)
+
+	secretName, creds, err := r.reconcileCredentials(ctx, app, db, name, labels)
+	if err != nil {
+		return err
 	}
 
-	replicas := int32(1)
-	desired := &appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: app.Namespace,
-			Labels:    labels,
-		},
-		Spec: appsv1.DeploymentSpec{
-			Replicas: &replicas,
-			Selector: &metav1.LabelSelector{MatchLabels: labels},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{Labels: labels},
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{{
-						Name:            db.Name,
-						Image:           db.Image,
-						ImagePullPolicy: corev1.PullIfNotPresent,
-						Env:             envVars,
-						Ports: []corev1.ContainerPort{{
-							ContainerPort: db.Port,
-						}},
-					}},
+	persistent := db.Storage != nil
+	env := engine.Env(secretName, creds)
+	if persistent {
+		env = append(env, engine.StorageEnv...)
+	}
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: labels},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:            db.Name,
+				Image:           db.Image,
+				ImagePullPolicy: corev1.PullIfNotPresent,
+				Env:             env,
+				Ports: []corev1.ContainerPort{{
+					ContainerPort: port,
+				}},
+				ReadinessProbe: &corev1.Probe{
+					ProbeHandler: corev1.ProbeHandler{
+						Exec: &corev1.ExecAction{Command: []string{"sh", "-c", engine.ReadyCheck(port)}},
+					},
+					PeriodSeconds:  5,
+					TimeoutSeconds: 3,
 				},
-			},
+			}},
 		},
 	}
-
-	if err := ctrl.SetControllerReference(app, desired, r.Scheme); err != nil {
-		return err
+	if engine.Args != nil {
+		template.Spec.Containers[0].Args = engine.Args(creds, persistent)
 	}
+	applyWorkload(&template.Spec, db.WorkloadSpec, port, nil)
 
-	var existing appsv1.Deployment
-	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: app.Namespace}, &existing)
-	if errors.IsNotFound(err) {
-		if err := r.Create(ctx, desired); err != nil {
+	// Databases with storage run as a StatefulSet; switching modes replaces the other workload
+	if persistent {
+		if err := r.reconcileDatabaseStatefulSet(ctx, app, db, name, labels, template); err != nil {
+			return err
+		}
+		if err := r.deleteIfExists(ctx, &appsv1.Deployment{}, name, app.Namespace); err != nil {
 			return err
 		}
-	} else if err != nil {
-		return err
 	} else {
-		existing.Spec = desired.Spec
-		existing.Labels = desired.Labels
-		if err := r.Update(ctx, &existing); err != nil {
+		if err := r.reconcileDatabaseDeployment(ctx, app, name, labels, template); err != nil {
+			return err
+		}
+		if err := r.deleteIfExists(ctx, &appsv1.StatefulSet{}, name, app.Namespace); err != nil {
 			return err
 		}
 	}
@@ -202,8 +340,8 @@ func (r *AppReconciler) reconcileDatabase(ctx context.Context, app *appsv1alpha1
 		Spec: corev1.ServiceSpec{
 			Selector: labels,
 			Ports: []corev1.ServicePort{{
-				Port:       db.Port,
-				TargetPort: intstr.FromInt32(db.Port),
+				Port:       port,
+				TargetPort: intstr.FromInt32(port),
 				Protocol:   corev1.ProtocolTCP,
 			}},
 		},
@@ -216,12 +354,18 @@ func (r *AppReconciler) reconcileDatabase(ctx context.Context, app *appsv1alpha1
 	var existingSvc corev1.Service
 	err = r.Get(ctx, types.NamespacedName{Name: name, Namespace: app.Namespace}, &existingSvc)
 	if errors.IsNotFound(err) {
+		if err := markApplied(svcDesired, serviceManagedSpec(svcDesired)); err != nil {
+			return err
+		}
 		if err := r.Create(ctx, svcDesired); err != nil {
 			return err
 		}
 	} else if err != nil {
 		return err
 	} else {
+		if err := r.correctDrift(app, &existingSvc, "Service", serviceManagedSpec(svcDesired), serviceManagedSpec(&existingSvc)); err != nil {
+			return err
+		}
 		existingSvc.Spec.Ports = svcDesired.Spec.Ports
 		existingSvc.Spec.Selector = svcDesired.Spec.Selector
 		existingSvc.Labels = svcDesired.Labels
@@ -230,61 +374,61 @@ func (r *AppReconciler) reconcileDatabase(ctx context.Context, app *appsv1alpha1
 		}
 	}
 
-	// Schema init Job (only create if InitSQL is set and Job doesn't exist yet)
-	if db.InitSQL != "" {
-		jobName := name + "-init"
-		var existingJob batchv1.Job
-		err := r.Get(ctx, types.NamespacedName{Name: jobName, Namespace: app.Namespace}, &existingJob)
-		if errors.IsNotFound(err) {
-			log.Info("Creating schema init Job", "name", jobName)
-
-			user := db.Credentials["user"]
-			pass := db.Credentials["password"]
-			dbname := db.Credentials["dbname"]
-			host := name // Service name = DB hostname
-
-			// psql command to run init SQL
-			psqlCmd := fmt.Sprintf(
-				`until pg_isready -h %s -p %d -U %s; do echo "waiting for db..."; sleep 2; done; PGPASSWORD=%s psql -h %s -p %d -U %s -d %s -c '%s'`,
-				host, db.Port, user,
-				pass, host, db.Port, user, dbname,
-				db.InitSQL,
-			)
-
-			backoffLimit := int32(3)
-			job := &batchv1.Job{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      jobName,
-					Namespace: app.Namespace,
-					Labels:    labels,
-				},
-				Spec: batchv1.JobSpec{
-					BackoffLimit: &backoffLimit,
-					Template: corev1.PodTemplateSpec{
-						Spec: corev1.PodSpec{
-							RestartPolicy: corev1.RestartPolicyOnFailure,
-							Containers: []corev1.Container{{
-								Name:    "init-schema",
-								Image:   "postgres:16-alpine",
-								Command: []string{"sh", "-c", psqlCmd},
-							}},
-						},
-					},
-				},
-			}
+	// Schema migrations, applied in order once the database is reachable
+	return r.reconcileMigrations(ctx, app, db, name, labels, secretName, creds)
+}
 
-			if err := ctrl.SetControllerReference(app, job, r.Scheme); err != nil {
-				return err
-			}
-			if err := r.Create(ctx, job); err != nil {
-				return err
-			}
-		} else if err != nil {
+// reconcileDatabaseDeployment runs a database without persistent storage as a single-replica Deployment.
+func (r *AppReconciler) reconcileDatabaseDeployment(ctx context.Context, app *appsv1alpha1.App, name string, labels map[string]string, template corev1.PodTemplateSpec) error {
+	replicas := int32(1)
+	desired := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: app.Namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: template,
+		},
+	}
+
+	if err := ctrl.SetControllerReference(app, desired, r.Scheme); err != nil {
+		return err
+	}
+	return r.applyDeployment(ctx, app, desired)
+}
+
+// applyDeployment creates desired, or brings the existing Deployment in line with it and reports
+// whatever was changed behind our back.
+func (r *AppReconciler) applyDeployment(ctx context.Context, app *appsv1alpha1.App, desired *appsv1.Deployment) error {
+	var existing appsv1.Deployment
+	err := r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, &existing)
+	if errors.IsNotFound(err) {
+		if err := markApplied(desired, &desired.Spec); err != nil {
 			return err
 		}
+		return r.Create(ctx, desired)
 	}
-
-	return nil
+	if err != nil {
+		return err
+	}
+	if err := r.correctDrift(app, &existing, "Deployment", &desired.Spec, &existing.Spec); err != nil {
+		return err
+	}
+	existing.Spec = desired.Spec
+	existing.Labels = desired.Labels
+	for _, key := range rolloutAnnotations {
+		if _, ok := desired.Annotations[key]; !ok {
+			delete(existing.Annotations, key)
+		}
+	}
+	if existing.Annotations == nil {
+		existing.Annotations = map[string]string{}
+	}
+	maps.Copy(existing.Annotations, desired.Annotations)
+	return r.Update(ctx, &existing)
 }
 
 func (r *AppReconciler) reconcileDeployment(ctx context.Context, app *appsv1alpha1.App, svc appsv1alpha1.ServiceSpec, name string) error {
@@ -300,29 +444,43 @@ func (r *AppReconciler) reconcileDeployment(ctx context.Context, app *appsv1alph
 		"app.kubernetes.io/part-of":    app.Name,
 	}
 
-	// Build env vars from ServiceSpec
+	// Build env vars from ServiceSpec; Env entries come last so their valueFrom refs win on name clashes
 	envVars := []corev1.EnvVar{}
-	for k, v := range svc.EnvVars {
-		envVars = append(envVars, corev1.EnvVar{Name: k, Value: v})
+	for _, k := range slices.Sorted(maps.Keys(svc.EnvVars)) {
+		envVars = append(envVars, corev1.EnvVar{Name: k, Value: svc.EnvVars[k]})
+	}
+	envVars = append(envVars, svc.Env...)
+
+	// The version is recorded on the Deployment and its pods, but kept out of the immutable selector
+	version := svc.DesiredVersion()
+	podLabels := maps.Clone(labels)
+	if len(validation.IsValidLabelValue(version)) == 0 {
+		podLabels["app.kubernetes.io/version"] = version
+	}
+	annotations := map[string]string{versionAnnotation: version}
+	if svc.RolloutStrategyType() == appsv1alpha1.RolloutStrategyBlueGreen {
+		podLabels[trackLabel] = trackStable
 	}
 
 	desired := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: app.Namespace,
-			Labels:    labels,
+			Name:        name,
+			Namespace:   app.Namespace,
+			Labels:      podLabels,
+			Annotations: annotations,
 		},
 		Spec: appsv1.DeploymentSpec{
 			Replicas: &replicas,
 			Selector: &metav1.LabelSelector{MatchLabels: labels},
 			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels, Annotations: annotations},
 				Spec: corev1.PodSpec{
 					Containers: []corev1.Container{{
 						Name:            svc.Name,
-						Image:           svc.Image,
+						Image:           svc.ImageRef(),
 						ImagePullPolicy: corev1.PullIfNotPresent,
 						Env:             envVars,
+						EnvFrom:         svc.EnvFrom,
 						Ports: []corev1.ContainerPort{{
 							ContainerPort: svc.Port,
 						}},
@@ -338,26 +496,40 @@ func (r *AppReconciler) reconcileDeployment(ctx context.Context, app *appsv1alph
 			Name:          "grpc",
 		})
 	}
+	applyWorkload(&desired.Spec.Template.Spec, svc.WorkloadSpec, svc.Port, svc.GrpcPort)
+	if svc.IsMock() {
+		applyMock(&desired.Spec.Template.Spec, svc, name)
+	}
+	if svc.FaultInjection != nil {
+		if err := applyFaultProxy(&desired.Spec.Template.Spec, svc); err != nil {
+			return err
+		}
+	}
 
 	// Set owner reference for garbage collection
 	if err := ctrl.SetControllerReference(app, desired, r.Scheme); err != nil {
 		return err
 	}
 
-	// Check if Deployment exists
-	var existing appsv1.Deployment
-	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: app.Namespace}, &existing)
-	if errors.IsNotFound(err) {
-		return r.Create(ctx, desired)
+	// Canary and blue/green services keep the stable pod template until the rollout is promoted
+	if svc.RolloutStrategyType() != appsv1alpha1.RolloutStrategyRollingUpdate {
+		return r.reconcileRollout(ctx, app, svc, desired)
 	}
+	hash, err := templateHash(desired.Spec.Template)
 	if err != nil {
 		return err
 	}
-
-	// Update existing Deployment
-	existing.Spec = desired.Spec
-	existing.Labels = desired.Labels
-	return r.Update(ctx, &existing)
+	annotations[templateHashAnnotation] = hash
+	if err := r.applyDeployment(ctx, app, desired); err != nil {
+		return err
+	}
+	// Switching back to rolling updates mid-rollout drops the canary or preview
+	for _, track := range []string{trackCanary, trackPreview} {
+		if err := r.deleteIfExists(ctx, &appsv1.Deployment{}, name+"-"+track, app.Namespace); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *AppReconciler) reconcileService(ctx context.Context, app *appsv1alpha1.App, svc appsv1alpha1.ServiceSpec, name string) error {
@@ -368,22 +540,43 @@ func (r *AppReconciler) reconcileService(ctx context.Context, app *appsv1alpha1.
 		"app.kubernetes.io/part-of":    app.Name,
 	}
 
+	// Services with fault injection send their traffic through the proxy in each pod
+	httpTarget := svc.Port
+	if svc.FaultInjection != nil {
+		httpTarget = appsv1alpha1.FaultProxyHTTPPort
+	}
+
 	ports := []corev1.ServicePort{{
 		Name:       "http",
 		Port:       svc.Port,
-		TargetPort: intstr.FromInt32(svc.Port),
+		TargetPort: intstr.FromInt32(httpTarget),
 		Protocol:   corev1.ProtocolTCP,
 	}}
 
 	if svc.GrpcPort != nil {
+		grpcTarget := *svc.GrpcPort
+		if svc.FaultInjection != nil {
+			grpcTarget = appsv1alpha1.FaultProxyGRPCPort
+		}
 		ports = append(ports, corev1.ServicePort{
 			Name:       "grpc",
 			Port:       *svc.GrpcPort,
-			TargetPort: intstr.FromInt32(*svc.GrpcPort),
+			TargetPort: intstr.FromInt32(grpcTarget),
 			Protocol:   corev1.ProtocolTCP,
 		})
 	}
 
+	// Blue/green Services select one track at a time; canaries share the Service with the stable pods
+	selector := labels
+	if svc.RolloutStrategyType() == appsv1alpha1.RolloutStrategyBlueGreen {
+		track, err := r.blueGreenTrack(ctx, app.Namespace, name)
+		if err != nil {
+			return err
+		}
+		selector = maps.Clone(labels)
+		selector[trackLabel] = track
+	}
+
 	desired := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -391,7 +584,7 @@ func (r *AppReconciler) reconcileService(ctx context.Context, app *appsv1alpha1.
 			Labels:    labels,
 		},
 		Spec: corev1.ServiceSpec{
-			Selector: labels,
+			Selector: selector,
 			Ports:    ports,
 		},
 	}
@@ -405,6 +598,9 @@ func (r *AppReconciler) reconcileService(ctx context.Context, app *appsv1alpha1.
 	var existing corev1.Service
 	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: app.Namespace}, &existing)
 	if errors.IsNotFound(err) {
+		if err := markApplied(desired, serviceManagedSpec(desired)); err != nil {
+			return err
+		}
 		return r.Create(ctx, desired)
 	}
 	if err != nil {
@@ -412,6 +608,9 @@ func (r *AppReconciler) reconcileService(ctx context.Context, app *appsv1alpha1.
 	}
 
 	// Update existing: preserve ClusterIP
+	if err := r.correctDrift(app, &existing, "Service", serviceManagedSpec(desired), serviceManagedSpec(&existing)); err != nil {
+		return err
+	}
 	existing.Spec.Ports = desired.Spec.Ports
 	existing.Spec.Selector = desired.Spec.Selector
 	existing.Labels = desired.Labels
@@ -423,7 +622,12 @@ func (r *AppReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&appsv1alpha1.App{}).
 		Owns(&appsv1.Deployment{}).
+		Owns(&appsv1.StatefulSet{}).
 		Owns(&corev1.Service{}).
+		Owns(&batchv1.Job{}).
+		Owns(&corev1.Secret{}).
+		Owns(&corev1.ConfigMap{}).
+		Watches(&appsv1alpha1.AppTemplate{}, handler.EnqueueRequestsFromMapFunc(r.appsForTemplate)).
 		Named("app").
 		Complete(r)
 }
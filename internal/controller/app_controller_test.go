@@ -18,11 +18,19 @@ package controller
 
 import (
 	"context"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -89,5 +97,449 @@ var _ = Describe("App Controller", func() {
 			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
+		It("should report component health in status", func() {
+			controllerReconciler := &AppReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking that the unrolled Deployment is reported as progressing")
+			updated := &appsv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.LastChecked).NotTo(BeNil())
+			Expect(updated.Status.Components).To(HaveLen(1))
+			Expect(updated.Status.Components[0].Name).To(Equal("test-service"))
+			Expect(updated.Status.Components[0].Phase).To(Equal(appsv1alpha1.ComponentPhaseProgressing))
+			Expect(updated.Status.Components[0].Ready).To(BeFalse())
+			Expect(meta.IsStatusConditionFalse(updated.Status.Conditions, appsv1alpha1.AppConditionReady)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, appsv1alpha1.AppConditionProgressing)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(updated.Status.Conditions, appsv1alpha1.AppConditionDegraded)).To(BeTrue())
+			Expect(updated.Status.Health).To(Equal("Unknown"))
+		})
+		It("should generate a credentials Secret for a database without credentials", func() {
+			By("adding a database without credentials to the App")
+			resource := &appsv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Databases = []appsv1alpha1.DatabaseSpec{{
+				Name:    "db",
+				Image:   "postgres:16-alpine",
+				InitSQL: "CREATE TABLE items (id serial PRIMARY KEY);",
+			}}
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &AppReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking the generated Secret")
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-db-credentials", Namespace: "default"}, secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKey("user"))
+			Expect(secret.Data).To(HaveKey("password"))
+			Expect(secret.Data).To(HaveKey("dbname"))
+
+			By("checking that the init Job reads credentials from the Secret")
+			job := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-db-init", Namespace: "default"}, job)).To(Succeed())
+			container := job.Spec.Template.Spec.Containers[0]
+			Expect(container.Command[2]).NotTo(ContainSubstring(string(secret.Data["password"])))
+			Expect(container.Env).To(ContainElement(HaveField("ValueFrom.SecretKeyRef.Name", resourceName+"-db-credentials")))
+		})
+		It("should tag an untagged image with the service version", func() {
+			controllerReconciler := &AppReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			dep := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-test-service", Namespace: "default"}, dep)).To(Succeed())
+			Expect(dep.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:1.14.2"))
+			Expect(dep.Spec.Template.Labels).To(HaveKeyWithValue("app.kubernetes.io/version", "1.14.2"))
+			Expect(dep.Spec.Selector.MatchLabels).NotTo(HaveKey("app.kubernetes.io/version"))
+
+			updated := &appsv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Components[0].Version).To(Equal("1.14.2"))
+			Expect(updated.Status.Components[0].ObservedVersion).To(BeEmpty())
+		})
+		It("should carry probes, resources and scheduling onto the Deployment", func() {
+			resource := &appsv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Services[0].WorkloadSpec = appsv1alpha1.WorkloadSpec{
+				ReadinessProbe: &corev1.Probe{
+					ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz"}},
+				},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: apiresource.MustParse("100m")},
+				},
+				NodeSelector: map[string]string{"pool": "ci"},
+			}
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &AppReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			dep := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-test-service", Namespace: "default"}, dep)).To(Succeed())
+			container := dep.Spec.Template.Spec.Containers[0]
+			Expect(container.ReadinessProbe).NotTo(BeNil())
+			Expect(container.ReadinessProbe.HTTPGet.Port.IntValue()).To(Equal(80))
+			Expect(container.Resources.Requests.Cpu().String()).To(Equal("100m"))
+			Expect(dep.Spec.Template.Spec.NodeSelector).To(HaveKeyWithValue("pool", "ci"))
+		})
+		It("should hold back a service until its dependencies are ready", func() {
+			resource := &appsv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Databases = []appsv1alpha1.DatabaseSpec{{Name: "store", Image: "postgres:16-alpine"}}
+			resource.Spec.Services = append(resource.Spec.Services, appsv1alpha1.ServiceSpec{
+				Name: "gated", Image: "nginx", Version: "1.14.2", Port: 80, DependsOn: []string{"store"},
+			})
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &AppReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking that no Deployment was created for the gated service")
+			dep := &appsv1.Deployment{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-gated", Namespace: "default"}, dep)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			updated := &appsv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Components).To(ContainElement(And(
+				HaveField("Name", "gated"),
+				HaveField("Phase", appsv1alpha1.ComponentPhaseBlocked),
+				HaveField("BlockedBy", ConsistOf("store")),
+			)))
+		})
+		It("should run migrations one at a time and track them in status", func() {
+			resource := &appsv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Databases = []appsv1alpha1.DatabaseSpec{{
+				Name:  "db",
+				Image: "postgres:16-alpine",
+				Migrations: []appsv1alpha1.MigrationSpec{
+					{Version: "001", SQL: "CREATE TABLE items (id serial PRIMARY KEY);"},
+					{Version: "002", SQL: "ALTER TABLE items ADD COLUMN name text;"},
+				},
+			}}
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &AppReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking that only the first migration has a Job")
+			jobs := &batchv1.JobList{}
+			Expect(k8sClient.List(ctx, jobs, client.InNamespace("default"))).To(Succeed())
+			var migrationJobs []batchv1.Job
+			for _, job := range jobs.Items {
+				if strings.HasPrefix(job.Name, resourceName+"-db-migrate-") {
+					migrationJobs = append(migrationJobs, job)
+				}
+			}
+			Expect(migrationJobs).To(HaveLen(1))
+			Expect(migrationJobs[0].Spec.Template.Spec.Volumes[0].ConfigMap.Name).To(Equal(resourceName + "-db-migrations"))
+
+			updated := &appsv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Migrations).To(HaveLen(2))
+			Expect(updated.Status.Migrations[0].Version).To(Equal("001"))
+			Expect(updated.Status.Migrations[0].Hash).NotTo(BeEmpty())
+			Expect(updated.Status.Migrations[1].State).To(Equal(appsv1alpha1.MigrationStatePending))
+		})
+		It("should run a database with storage as a StatefulSet guarded by the retention finalizer", func() {
+			resource := &appsv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Databases = []appsv1alpha1.DatabaseSpec{{
+				Name:    "db",
+				Image:   "postgres:16-alpine",
+				Storage: &appsv1alpha1.DatabaseStorageSpec{Size: apiresource.MustParse("1Gi")},
+			}}
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &AppReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking that the database runs as a StatefulSet with a volume claim template")
+			sts := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-db", Namespace: "default"}, sts)).To(Succeed())
+			Expect(sts.Spec.VolumeClaimTemplates).To(HaveLen(1))
+			Expect(sts.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests.Storage().String()).To(Equal("1Gi"))
+			Expect(sts.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(HaveField("MountPath", "/var/lib/postgresql/data")))
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-db", Namespace: "default"}, &appsv1.Deployment{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			updated := &appsv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updated)).To(Succeed())
+			Expect(updated.Finalizers).To(ContainElement(storageFinalizer))
+
+			By("dropping the finalizer once no database requests storage")
+			updated.Spec.Databases[0].Storage = nil
+			Expect(k8sClient.Update(ctx, updated)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updated)).To(Succeed())
+			Expect(updated.Finalizers).NotTo(ContainElement(storageFinalizer))
+		})
+		It("should render services and databases from an AppTemplate", func() {
+			defaultReplicas := "2"
+			tmpl := &appsv1alpha1.AppTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-template", Namespace: "default"},
+				Spec: appsv1alpha1.AppTemplateSpec{
+					Parameters: []appsv1alpha1.TemplateParameter{
+						{Name: "version", Required: true},
+						{Name: "replicas", Default: &defaultReplicas},
+					},
+					Template: runtime.RawExtension{Raw: []byte(`{"services":[{"name":"web","image":"nginx",` +
+						`"version":"${version}","replicas":"${{replicas}}","port":80}]}`)},
+				},
+			}
+			Expect(k8sClient.Create(ctx, tmpl)).To(Succeed())
+			DeferCleanup(func() { Expect(k8sClient.Delete(ctx, tmpl)).To(Succeed()) })
+
+			resource := &appsv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Template = &appsv1alpha1.AppTemplateRef{
+				Name:       "test-template",
+				Parameters: map[string]string{"version": "1.25"},
+			}
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &AppReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking that the rendered service replaced the inline one")
+			updated := &appsv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updated)).To(Succeed())
+			Expect(updated.Spec.Services).To(HaveLen(1))
+			Expect(updated.Spec.Services[0].Name).To(Equal("web"))
+			Expect(updated.Spec.Services[0].Version).To(Equal("1.25"))
+			Expect(*updated.Spec.Services[0].Replicas).To(Equal(int32(2)))
+			Expect(updated.Status.TemplateHash).NotTo(BeEmpty())
+			Expect(updated.Annotations).To(HaveKeyWithValue(appsv1alpha1.TemplateHashAnnotation, updated.Status.TemplateHash))
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, appsv1alpha1.AppConditionTemplateRendered)).To(BeTrue())
+
+			dep := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-web", Namespace: "default"}, dep)).To(Succeed())
+			Expect(dep.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:1.25"))
+		})
+		It("should revert and report drift, and leave workloads alone while paused", func() {
+			controllerReconciler := &AppReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("scaling the Deployment behind the controller's back")
+			depName := types.NamespacedName{Name: resourceName + "-test-service", Namespace: "default"}
+			dep := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, depName, dep)).To(Succeed())
+			scaled := int32(3)
+			dep.Spec.Replicas = &scaled
+			Expect(k8sClient.Update(ctx, dep)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, depName, dep)).To(Succeed())
+			Expect(*dep.Spec.Replicas).To(Equal(int32(1)))
+
+			updated := &appsv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updated)).To(Succeed())
+			drifted := meta.FindStatusCondition(updated.Status.Conditions, appsv1alpha1.AppConditionDrifted)
+			Expect(drifted).NotTo(BeNil())
+			Expect(drifted.Status).To(Equal(metav1.ConditionTrue))
+			Expect(drifted.Message).To(ContainSubstring("spec.replicas"))
+
+			By("pausing the App and scaling again")
+			updated.Spec.Paused = true
+			Expect(k8sClient.Update(ctx, updated)).To(Succeed())
+			Expect(k8sClient.Get(ctx, depName, dep)).To(Succeed())
+			dep.Spec.Replicas = &scaled
+			Expect(k8sClient.Update(ctx, dep)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, depName, dep)).To(Succeed())
+			Expect(*dep.Spec.Replicas).To(Equal(int32(3)))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updated)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, appsv1alpha1.AppConditionPaused)).To(BeTrue())
+		})
+		It("should run a canary next to the stable Deployment until promoted", func() {
+			controllerReconciler := &AppReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			resource := &appsv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			replicas, weight := int32(5), int32(40)
+			resource.Spec.Services[0].Replicas = &replicas
+			resource.Spec.Services[0].Strategy = &appsv1alpha1.RolloutStrategy{
+				Type:         appsv1alpha1.RolloutStrategyCanary,
+				CanaryWeight: &weight,
+			}
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("changing the version")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Services[0].Version = "1.15.0"
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			stableName := types.NamespacedName{Name: resourceName + "-test-service", Namespace: "default"}
+			canaryName := types.NamespacedName{Name: resourceName + "-test-service-canary", Namespace: "default"}
+			stable, canary := &appsv1.Deployment{}, &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, stableName, stable)).To(Succeed())
+			Expect(k8sClient.Get(ctx, canaryName, canary)).To(Succeed())
+			Expect(stable.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:1.14.2"))
+			Expect(*stable.Spec.Replicas).To(Equal(int32(3)))
+			Expect(canary.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:1.15.0"))
+			Expect(*canary.Spec.Replicas).To(Equal(int32(2)))
+
+			updated := &appsv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Components[0].Rollout).NotTo(BeNil())
+			Expect(updated.Status.Components[0].Rollout.Phase).To(Equal(appsv1alpha1.RolloutPhaseAwaitingPromotion))
+
+			By("promoting the canary")
+			updated.Annotations = map[string]string{
+				appsv1alpha1.RolloutRequestAnnotationPrefix + "test-service": appsv1alpha1.RolloutRequestPromote,
+			}
+			Expect(k8sClient.Update(ctx, updated)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, stableName, stable)).To(Succeed())
+			Expect(stable.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:1.15.0"))
+			Expect(*stable.Spec.Replicas).To(Equal(int32(5)))
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, canaryName, canary))).To(BeTrue())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updated)).To(Succeed())
+			Expect(updated.Annotations).NotTo(HaveKey(appsv1alpha1.RolloutRequestAnnotationPrefix + "test-service"))
+		})
+		It("should run mock services on the stub server fed from a ConfigMap", func() {
+			controllerReconciler := &AppReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			resource := &appsv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Services = append(resource.Spec.Services, appsv1alpha1.ServiceSpec{
+				Name: "payments",
+				Kind: appsv1alpha1.ServiceKindMock,
+				Port: 8080,
+				Mock: &appsv1alpha1.MockSpec{Stubs: []appsv1alpha1.StubSpec{{
+					Name:     "charge",
+					Request:  appsv1alpha1.StubRequest{Method: "POST", Path: "/charges"},
+					Response: appsv1alpha1.StubResponse{Status: 201, Body: `{"id":"ch_1"}`},
+				}}},
+			})
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			dep := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-payments", Namespace: "default"}, dep)).To(Succeed())
+			container := dep.Spec.Template.Spec.Containers[0]
+			Expect(container.Image).To(Equal(stubImage()))
+			Expect(container.Args).To(ContainElements("--http-port=8080", "--config-dir="+stubConfigDir))
+			Expect(container.ReadinessProbe.HTTPGet.Path).To(Equal(appsv1alpha1.MockAdminPrefix + "health"))
+
+			stubs := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-payments-stubs", Namespace: "default"}, stubs)).To(Succeed())
+			Expect(stubs.Data[specStubsKey]).To(ContainSubstring(`"path":"/charges"`))
+		})
+		It("should route a service with fault injection through the proxy sidecar", func() {
+			controllerReconciler := &AppReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			resource := &appsv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Services[0].Strategy = nil
+			resource.Spec.Services[0].FaultInjection = &appsv1alpha1.FaultInjectionSpec{
+				Faults: &appsv1alpha1.FaultSpec{ErrorPercent: 10},
+			}
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			name := types.NamespacedName{Name: resourceName + "-test-service", Namespace: "default"}
+			dep := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, name, dep)).To(Succeed())
+			containers := dep.Spec.Template.Spec.Containers
+			Expect(containers).To(HaveLen(2))
+			Expect(containers[1].Name).To(Equal(appsv1alpha1.FaultProxyContainerName))
+			Expect(containers[1].Args).To(ContainElements("--route=15001:80", `--faults={"errorPercent":10}`))
+
+			svc := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, name, svc)).To(Succeed())
+			Expect(svc.Spec.Ports[0].Port).To(Equal(int32(80)))
+			Expect(svc.Spec.Ports[0].TargetPort.IntValue()).To(Equal(int(appsv1alpha1.FaultProxyHTTPPort)))
+		})
 	})
 })
@@ -0,0 +1,410 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// degradedWaitingReasons are container waiting reasons that will not resolve without a spec change.
+var degradedWaitingReasons = map[string]bool{
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CrashLoopBackOff":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+// observeComponents builds the per-component status list from the workloads and migrations of the App.
+// blocked maps held-back services to the dependencies they are waiting on.
+func (r *AppReconciler) observeComponents(ctx context.Context, app *appsv1alpha1.App, blocked map[string][]string) ([]appsv1alpha1.ComponentStatus, error) {
+	components := make([]appsv1alpha1.ComponentStatus, 0, len(app.Spec.Databases)+len(app.Spec.Services))
+
+	for _, db := range app.Spec.Databases {
+		status, err := r.observeDatabase(ctx, app, db)
+		if err != nil {
+			return nil, err
+		}
+		status.Name = db.Name
+		status.Kind = appsv1alpha1.ComponentKindDatabase
+		status.Ready = status.Phase == appsv1alpha1.ComponentPhaseReady
+		components = append(components, status)
+	}
+
+	for _, svc := range app.Spec.Services {
+		name := fmt.Sprintf("%s-%s", app.Name, svc.Name)
+		status, err := r.observeDeployment(ctx, app.Namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		status.Name = svc.Name
+		status.Kind = appsv1alpha1.ComponentKindService
+		status.Version = svc.DesiredVersion()
+		if status.ObservedVersion == "" {
+			// Mid-rollout: keep reporting what was running before
+			status.ObservedVersion = previousObservedVersion(app.Status.Components, svc.Name)
+		}
+		if err := r.observeRollout(ctx, app, svc, name, &status); err != nil {
+			return nil, err
+		}
+		if waitingOn := blocked[svc.Name]; len(waitingOn) > 0 && status.Phase != appsv1alpha1.ComponentPhaseDegraded {
+			status.Phase = appsv1alpha1.ComponentPhaseBlocked
+			status.BlockedBy = waitingOn
+			status.Message = "waiting for " + strings.Join(waitingOn, ", ")
+		}
+		status.Ready = status.Phase == appsv1alpha1.ComponentPhaseReady
+		components = append(components, status)
+	}
+
+	return components, nil
+}
+
+// observeDeployment derives a component phase from a Deployment's rollout state and its pods.
+func (r *AppReconciler) observeDeployment(ctx context.Context, namespace, name string) (appsv1alpha1.ComponentStatus, error) {
+	status := appsv1alpha1.ComponentStatus{Phase: appsv1alpha1.ComponentPhaseProgressing}
+
+	var dep appsv1.Deployment
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &dep); err != nil {
+		if errors.IsNotFound(err) {
+			status.Message = "Deployment not found"
+			return status, nil
+		}
+		return status, err
+	}
+
+	desired := int32(1)
+	if dep.Spec.Replicas != nil {
+		desired = *dep.Spec.Replicas
+	}
+	status.DesiredReplicas = desired
+	status.ReadyReplicas = dep.Status.ReadyReplicas
+	status.UpdatedReplicas = dep.Status.UpdatedReplicas
+
+	// Rollout failures reported by the Deployment controller itself
+	for _, c := range dep.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Reason == "ProgressDeadlineExceeded" {
+			status.Phase = appsv1alpha1.ComponentPhaseDegraded
+			status.Message = c.Message
+			return status, nil
+		}
+		if c.Type == appsv1.DeploymentReplicaFailure && c.Status == corev1.ConditionTrue {
+			status.Phase = appsv1alpha1.ComponentPhaseDegraded
+			status.Message = c.Message
+			return status, nil
+		}
+	}
+
+	// Pod-level failures (image pulls, crash loops) never surface on the Deployment
+	if dep.Spec.Selector != nil {
+		var pods corev1.PodList
+		if err := r.List(ctx, &pods, client.InNamespace(namespace), client.MatchingLabels(dep.Spec.Selector.MatchLabels)); err != nil {
+			return status, err
+		}
+		if reason := podFailureReason(pods.Items); reason != "" {
+			status.Phase = appsv1alpha1.ComponentPhaseDegraded
+			status.Message = reason
+			return status, nil
+		}
+	}
+
+	if deploymentRolledOut(&dep) {
+		status.Phase = appsv1alpha1.ComponentPhaseReady
+		status.ObservedVersion = dep.Spec.Template.Annotations[versionAnnotation]
+		return status, nil
+	}
+
+	status.Message = fmt.Sprintf("%d/%d replicas ready, %d updated", dep.Status.ReadyReplicas, desired, dep.Status.UpdatedReplicas)
+	return status, nil
+}
+
+// deploymentRolledOut reports whether every desired replica of a Deployment runs its current pod template and is ready.
+func deploymentRolledOut(dep *appsv1.Deployment) bool {
+	desired := int32(1)
+	if dep.Spec.Replicas != nil {
+		desired = *dep.Spec.Replicas
+	}
+	return dep.Status.ObservedGeneration >= dep.Generation &&
+		dep.Status.UpdatedReplicas == desired &&
+		dep.Status.Replicas == desired &&
+		dep.Status.ReadyReplicas >= desired
+}
+
+// observeStatefulSet derives a component phase from a StatefulSet's rollout state and its pods.
+func (r *AppReconciler) observeStatefulSet(ctx context.Context, namespace, name string) (appsv1alpha1.ComponentStatus, error) {
+	status := appsv1alpha1.ComponentStatus{Phase: appsv1alpha1.ComponentPhaseProgressing}
+
+	var sts appsv1.StatefulSet
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &sts); err != nil {
+		if errors.IsNotFound(err) {
+			status.Message = "StatefulSet not found"
+			return status, nil
+		}
+		return status, err
+	}
+
+	desired := int32(1)
+	if sts.Spec.Replicas != nil {
+		desired = *sts.Spec.Replicas
+	}
+	status.DesiredReplicas = desired
+	status.ReadyReplicas = sts.Status.ReadyReplicas
+	status.UpdatedReplicas = sts.Status.UpdatedReplicas
+
+	if sts.Spec.Selector != nil {
+		var pods corev1.PodList
+		if err := r.List(ctx, &pods, client.InNamespace(namespace), client.MatchingLabels(sts.Spec.Selector.MatchLabels)); err != nil {
+			return status, err
+		}
+		if reason := podFailureReason(pods.Items); reason != "" {
+			status.Phase = appsv1alpha1.ComponentPhaseDegraded
+			status.Message = reason
+			return status, nil
+		}
+	}
+
+	rolledOut := sts.Status.ObservedGeneration >= sts.Generation &&
+		sts.Status.UpdatedReplicas == desired
+	if rolledOut && sts.Status.ReadyReplicas >= desired {
+		status.Phase = appsv1alpha1.ComponentPhaseReady
+		return status, nil
+	}
+
+	// A claim that cannot bind leaves the pod Pending with no container status to report
+	if sts.Status.ReadyReplicas == 0 {
+		var pvc corev1.PersistentVolumeClaim
+		err := r.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("%s-%s-0", dataVolumeName, name), Namespace: namespace}, &pvc)
+		if err != nil && !errors.IsNotFound(err) {
+			return status, err
+		}
+		if err == nil && pvc.Status.Phase == corev1.ClaimPending {
+			status.Message = fmt.Sprintf("waiting for PersistentVolumeClaim %s to bind", pvc.Name)
+			return status, nil
+		}
+	}
+
+	status.Message = fmt.Sprintf("%d/%d replicas ready, %d updated", sts.Status.ReadyReplicas, desired, sts.Status.UpdatedReplicas)
+	return status, nil
+}
+
+// observeDatabase reports a database from its Deployment or, with storage, its StatefulSet, and its migrations.
+func (r *AppReconciler) observeDatabase(ctx context.Context, app *appsv1alpha1.App, db appsv1alpha1.DatabaseSpec) (appsv1alpha1.ComponentStatus, error) {
+	name := fmt.Sprintf("%s-%s", app.Name, db.Name)
+	observe := r.observeDeployment
+	if db.Storage != nil {
+		observe = r.observeStatefulSet
+	}
+	status, err := observe(ctx, app.Namespace, name)
+	if err != nil {
+		return status, err
+	}
+	applySchemaState(&status, app.Status.Migrations, db.Name)
+	return status, nil
+}
+
+// podFailureReason returns the first unrecoverable container state found across the pods, or "".
+func podFailureReason(pods []corev1.Pod) string {
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		statuses := append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...)
+		statuses = append(statuses, pod.Status.ContainerStatuses...)
+		for _, cs := range statuses {
+			if cs.State.Waiting != nil && degradedWaitingReasons[cs.State.Waiting.Reason] {
+				return fmt.Sprintf("pod %s container %s: %s", pod.Name, cs.Name, cs.State.Waiting.Reason)
+			}
+		}
+	}
+	return ""
+}
+
+// previousObservedVersion returns the last observed version recorded for a service.
+func previousObservedVersion(components []appsv1alpha1.ComponentStatus, name string) string {
+	for _, c := range components {
+		if c.Kind == appsv1alpha1.ComponentKindService && c.Name == name {
+			return c.ObservedVersion
+		}
+	}
+	return ""
+}
+
+// unreadyDependencies returns the dependencies of svc that are not yet ready. A database is ready once its
+// workload is rolled out and all of its migrations are applied.
+func (r *AppReconciler) unreadyDependencies(ctx context.Context, app *appsv1alpha1.App, svc appsv1alpha1.ServiceSpec) ([]string, error) {
+	var waitingOn []string
+	for _, dep := range svc.DependsOn {
+		var (
+			status appsv1alpha1.ComponentStatus
+			err    error
+		)
+		if i := slices.IndexFunc(app.Spec.Databases, func(db appsv1alpha1.DatabaseSpec) bool { return db.Name == dep }); i >= 0 {
+			status, err = r.observeDatabase(ctx, app, app.Spec.Databases[i])
+		} else {
+			status, err = r.observeDeployment(ctx, app.Namespace, fmt.Sprintf("%s-%s", app.Name, dep))
+		}
+		if err != nil {
+			return nil, err
+		}
+		if status.Phase != appsv1alpha1.ComponentPhaseReady {
+			waitingOn = append(waitingOn, dep)
+		}
+	}
+	return waitingOn, nil
+}
+
+// applySchemaState folds a database's migration records into its component status.
+func applySchemaState(status *appsv1alpha1.ComponentStatus, migrations []appsv1alpha1.MigrationStatus, dbName string) {
+	for _, m := range migrations {
+		if m.Database != dbName {
+			continue
+		}
+		if status.Schema == "" {
+			status.Schema = appsv1alpha1.MigrationStateApplied
+		}
+		switch m.State {
+		case appsv1alpha1.MigrationStateFailed:
+			status.Schema = m.State
+			status.Phase = appsv1alpha1.ComponentPhaseDegraded
+			status.Message = fmt.Sprintf("migration %s: %s", m.Version, m.Message)
+			return
+		case appsv1alpha1.MigrationStatePending, appsv1alpha1.MigrationStateRunning:
+			status.Schema = m.State
+			if status.Phase == appsv1alpha1.ComponentPhaseReady {
+				status.Phase = appsv1alpha1.ComponentPhaseProgressing
+				status.Message = "applying migration " + m.Version
+				if m.Message != "" {
+					status.Message += ": " + m.Message
+				}
+			}
+			return
+		}
+	}
+}
+
+// observeJob returns a Job's state (Pending, Running, Succeeded, Failed) and a failure message.
+func (r *AppReconciler) observeJob(ctx context.Context, namespace, name string) (string, string, error) {
+	var job batchv1.Job
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &job); err != nil {
+		if errors.IsNotFound(err) {
+			return "Pending", "", nil
+		}
+		return "", "", err
+	}
+
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			return "Succeeded", "", nil
+		case batchv1.JobFailed:
+			return "Failed", fmt.Sprintf("Job %s failed: %s", name, c.Message), nil
+		}
+	}
+	if job.Status.Succeeded > 0 {
+		return "Succeeded", "", nil
+	}
+	if job.Status.Active > 0 {
+		return "Running", "", nil
+	}
+	return "Pending", "", nil
+}
+
+// setAppHealth summarizes the component list into Ready/Progressing/Degraded conditions and the Health field.
+func setAppHealth(app *appsv1alpha1.App) {
+	var notReady, progressing, degraded []string
+	for _, c := range app.Status.Components {
+		switch c.Phase {
+		case appsv1alpha1.ComponentPhaseDegraded:
+			degraded = append(degraded, fmt.Sprintf("%s: %s", c.Name, c.Message))
+		case appsv1alpha1.ComponentPhaseProgressing:
+			progressing = append(progressing, c.Name)
+		case appsv1alpha1.ComponentPhaseBlocked:
+			progressing = append(progressing, c.Name+" (blocked)")
+		}
+		if !c.Ready {
+			notReady = append(notReady, c.Name)
+		}
+	}
+
+	ready := metav1.Condition{
+		Type:               appsv1alpha1.AppConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "AllComponentsReady",
+		Message:            "All services and databases are ready",
+		ObservedGeneration: app.Generation,
+	}
+	if len(notReady) > 0 {
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "ComponentsNotReady"
+		ready.Message = "Not ready: " + strings.Join(notReady, ", ")
+	}
+	meta.SetStatusCondition(&app.Status.Conditions, ready)
+
+	prog := metav1.Condition{
+		Type:               appsv1alpha1.AppConditionProgressing,
+		Status:             metav1.ConditionFalse,
+		Reason:             "RolloutComplete",
+		Message:            "No rollouts in progress",
+		ObservedGeneration: app.Generation,
+	}
+	if len(progressing) > 0 {
+		prog.Status = metav1.ConditionTrue
+		prog.Reason = "RollingOut"
+		prog.Message = "Rolling out: " + strings.Join(progressing, ", ")
+	}
+	meta.SetStatusCondition(&app.Status.Conditions, prog)
+
+	deg := metav1.Condition{
+		Type:               appsv1alpha1.AppConditionDegraded,
+		Status:             metav1.ConditionFalse,
+		Reason:             "NoFailures",
+		Message:            "No component failures detected",
+		ObservedGeneration: app.Generation,
+	}
+	if len(degraded) > 0 {
+		deg.Status = metav1.ConditionTrue
+		deg.Reason = "ComponentFailure"
+		deg.Message = strings.Join(degraded, "; ")
+	}
+	meta.SetStatusCondition(&app.Status.Conditions, deg)
+
+	switch {
+	case len(degraded) > 0:
+		app.Status.Health = "Unhealthy"
+	case len(notReady) > 0:
+		app.Status.Health = "Unknown"
+	default:
+		app.Status.Health = "Healthy"
+	}
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+var (
+	// templateValueParam matches a string that is a single ${{name}} reference, replaced by a JSON value
+	templateValueParam = regexp.MustCompile(`^\$\{\{([A-Za-z_][A-Za-z0-9_]*)\}\}$`)
+	// templateStringParam matches ${name} references interpolated into strings
+	templateStringParam = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+)
+
+// renderAppTemplate renders spec.template into the App's services and databases. The App spec is only
+// rewritten when the rendering differs from the last one, so edits made in between (sut.apply_service)
+// survive until the template or its parameters change. Failures are reported on the TemplateRendered
+// condition and leave the last rendering running.
+func (r *AppReconciler) renderAppTemplate(ctx context.Context, app *appsv1alpha1.App) error {
+	ref := app.Spec.Template
+	if ref == nil {
+		meta.RemoveStatusCondition(&app.Status.Conditions, appsv1alpha1.AppConditionTemplateRendered)
+		app.Status.TemplateHash = ""
+		if _, ok := app.Annotations[appsv1alpha1.TemplateHashAnnotation]; ok {
+			delete(app.Annotations, appsv1alpha1.TemplateHashAnnotation)
+			return r.updateKeepingStatus(ctx, app)
+		}
+		return nil
+	}
+
+	var tmpl appsv1alpha1.AppTemplate
+	if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: app.Namespace}, &tmpl); err != nil {
+		if errors.IsNotFound(err) {
+			setTemplateCondition(app, metav1.ConditionFalse, "TemplateNotFound", fmt.Sprintf("AppTemplate %q not found", ref.Name))
+			return nil
+		}
+		return err
+	}
+
+	rendered, err := renderTemplate(&tmpl, ref.Parameters)
+	if err != nil {
+		setTemplateCondition(app, metav1.ConditionFalse, "RenderFailed", err.Error())
+		return nil
+	}
+	hash, err := hashRendered(rendered)
+	if err != nil {
+		return err
+	}
+
+	// The annotation is written with the rendered spec; Apps rendered before it existed only have the
+	// status field
+	last, ok := app.Annotations[appsv1alpha1.TemplateHashAnnotation]
+	if !ok {
+		last = app.Status.TemplateHash
+	}
+	if hash != last {
+		logf.FromContext(ctx).Info("Rendering App from template", "template", ref.Name, "hash", hash)
+		previous := app.DeepCopy()
+		app.Spec.Services, app.Spec.Databases = rendered.Services, rendered.Databases
+		if app.Annotations == nil {
+			app.Annotations = map[string]string{}
+		}
+		app.Annotations[appsv1alpha1.TemplateHashAnnotation] = hash
+		if err := r.updateKeepingStatus(ctx, app); err != nil {
+			app.Spec, app.Annotations = previous.Spec, previous.Annotations
+			if errors.IsInvalid(err) {
+				setTemplateCondition(app, metav1.ConditionFalse, "RenderedSpecInvalid", err.Error())
+				return nil
+			}
+			return err
+		}
+	}
+	app.Status.TemplateHash = hash
+
+	setTemplateCondition(app, metav1.ConditionTrue, "Rendered", fmt.Sprintf("Rendered from AppTemplate %q", ref.Name))
+	return nil
+}
+
+// updateKeepingStatus updates the App's metadata and spec. Update returns the stored status, which would
+// drop the conditions the reconcile has set in memory so far.
+func (r *AppReconciler) updateKeepingStatus(ctx context.Context, app *appsv1alpha1.App) error {
+	status := app.Status
+	err := r.Update(ctx, app)
+	app.Status = status
+	return err
+}
+
+// setTemplateCondition records the outcome of rendering spec.template.
+func setTemplateCondition(app *appsv1alpha1.App, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&app.Status.Conditions, metav1.Condition{
+		Type:               appsv1alpha1.AppConditionTemplateRendered,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: app.Generation,
+	})
+}
+
+// renderTemplate substitutes parameter values into an AppTemplate and decodes the result as an AppSpec.
+func renderTemplate(tmpl *appsv1alpha1.AppTemplate, values map[string]string) (appsv1alpha1.AppSpec, error) {
+	var spec appsv1alpha1.AppSpec
+
+	params := map[string]string{}
+	for _, p := range tmpl.Spec.Parameters {
+		switch v, ok := values[p.Name]; {
+		case ok:
+			params[p.Name] = v
+		case p.Default != nil:
+			params[p.Name] = *p.Default
+		case p.Required:
+			return spec, fmt.Errorf("parameter %q is required by AppTemplate %q", p.Name, tmpl.Name)
+		default:
+			params[p.Name] = ""
+		}
+	}
+	for name := range values {
+		if _, ok := params[name]; !ok {
+			return spec, fmt.Errorf("parameter %q is not declared by AppTemplate %q", name, tmpl.Name)
+		}
+	}
+
+	var doc any
+	if err := json.Unmarshal(tmpl.Spec.Template.Raw, &doc); err != nil {
+		return spec, fmt.Errorf("template is not valid JSON: %w", err)
+	}
+	doc, err := substituteParams(doc, params)
+	if err != nil {
+		return spec, err
+	}
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return spec, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return spec, fmt.Errorf("rendered template is not an AppSpec: %w", err)
+	}
+	if spec.Template != nil {
+		return spec, fmt.Errorf("template may not reference another AppTemplate")
+	}
+	return spec, nil
+}
+
+// substituteParams replaces parameter references in every string of a decoded JSON document.
+func substituteParams(v any, params map[string]string) (any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, elem := range v {
+			out, err := substituteParams(elem, params)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = out
+		}
+		return v, nil
+	case []any:
+		for i, elem := range v {
+			out, err := substituteParams(elem, params)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = out
+		}
+		return v, nil
+	case string:
+		if m := templateValueParam.FindStringSubmatch(v); m != nil {
+			value, ok := params[m[1]]
+			if !ok {
+				return nil, fmt.Errorf("undeclared parameter %q", m[1])
+			}
+			var out any
+			if err := json.Unmarshal([]byte(value), &out); err != nil {
+				return nil, fmt.Errorf("parameter %q: %q is not a JSON value", m[1], value)
+			}
+			return out, nil
+		}
+		var missing string
+		out := templateStringParam.ReplaceAllStringFunc(v, func(ref string) string {
+			name := templateStringParam.FindStringSubmatch(ref)[1]
+			value, ok := params[name]
+			if !ok && missing == "" {
+				missing = name
+			}
+			return value
+		})
+		if missing != "" {
+			return nil, fmt.Errorf("undeclared parameter %q", missing)
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+// hashRendered identifies a rendering of the services and databases.
+func hashRendered(spec appsv1alpha1.AppSpec) (string, error) {
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// appsForTemplate enqueues the Apps that instantiate an AppTemplate so they re-render when it changes.
+func (r *AppReconciler) appsForTemplate(ctx context.Context, obj client.Object) []reconcile.Request {
+	var apps appsv1alpha1.AppList
+	if err := r.List(ctx, &apps, client.InNamespace(obj.GetNamespace())); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list Apps for AppTemplate", "name", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, app := range apps.Items {
+		if app.Spec.Template != nil && app.Spec.Template.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&app)})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+const (
+	// cronTestRunLabel marks the TestRuns started by a CronTestRun
+	cronTestRunLabel = "topas.io/crontestrun"
+	// scheduledTimeAnnotation records the time a TestRun of a CronTestRun was scheduled for
+	scheduledTimeAnnotation = "topas.io/scheduled-at"
+	// maxMissedSchedules bounds the schedule times walked to find the last one missed
+	maxMissedSchedules = 100
+	// templateRejected starts the status message of a CronTestRun whose last TestRun was rejected; it is
+	// kept, as is a name collision, until a TestRun starts
+	templateRejected = "TestRun template rejected: "
+)
+
+// CronTestRunReconciler reconciles a CronTestRun object
+type CronTestRunReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder events.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=apps.example.com,resources=crontestruns,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=apps.example.com,resources=crontestruns/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps.example.com,resources=crontestruns/finalizers,verbs=update
+// +kubebuilder:rbac:groups=apps.example.com,resources=testruns,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
+
+// Reconcile starts the TestRuns of a CronTestRun on schedule, records the outcome of the last one and
+// prunes those past the history limits.
+func (r *CronTestRunReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var cronRun appv1alpha1.CronTestRun
+	if err := r.Get(ctx, req.NamespacedName, &cronRun); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// 1. Sort the TestRuns started so far into running, passed and failed
+	var children appv1alpha1.TestRunList
+	if err := r.List(ctx, &children, client.InNamespace(cronRun.Namespace), client.MatchingLabels{cronTestRunLabel: cronRun.Name}); err != nil {
+		return ctrl.Result{}, err
+	}
+	var active, passed, failed []*appv1alpha1.TestRun
+	var last *appv1alpha1.TestRun
+	for i := range children.Items {
+		child := &children.Items[i]
+		if !metav1.IsControlledBy(child, &cronRun) {
+			// Labelled by hand or left by an earlier CronTestRun of the same name: not ours to prune
+			continue
+		}
+		switch child.Status.State {
+		case "Passed", "Flaky":
+			passed = append(passed, child)
+		case "Failed", "Error":
+			failed = append(failed, child)
+		default:
+			active = append(active, child)
+			continue
+		}
+		if last == nil || scheduledTime(child).After(scheduledTime(last)) {
+			last = child
+		}
+	}
+
+	// 2. Record the running TestRuns and the outcome of the last one
+	cronRun.Status.Active = nil
+	for _, child := range active {
+		cronRun.Status.Active = append(cronRun.Status.Active, child.Name)
+	}
+	sort.Strings(cronRun.Status.Active)
+	if last != nil {
+		cronRun.Status.LastRun = last.Name
+		cronRun.Status.LastState = last.Status.State
+		cronRun.Status.LastResult = last.Status.Result
+	}
+
+	// 3. Delete the oldest finished TestRuns past the history limits
+	for _, history := range []struct {
+		runs  []*appv1alpha1.TestRun
+		limit *int32
+	}{
+		{passed, cronRun.Spec.SuccessfulRunsHistoryLimit},
+		{failed, cronRun.Spec.FailedRunsHistoryLimit},
+	} {
+		if history.limit == nil {
+			continue
+		}
+		sort.Slice(history.runs, func(i, j int) bool {
+			return scheduledTime(history.runs[i]).Before(scheduledTime(history.runs[j]))
+		})
+		for i := 0; i < len(history.runs)-int(*history.limit); i++ {
+			if err := r.Delete(ctx, history.runs[i], client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+				return ctrl.Result{}, err
+			}
+			log.Info("Deleted TestRun past the history limit", "testRun", history.runs[i].Name)
+		}
+	}
+
+	// 4. Find the latest schedule time missed and when the next one is due
+	now := time.Now()
+	missed, next, err := r.schedule(&cronRun, now)
+	if err != nil {
+		// Wait for the spec to change rather than retry a schedule that cannot work
+		log.Error(err, "Cannot schedule CronTestRun")
+		cronRun.Status.Message = err.Error()
+		return ctrl.Result{}, r.Status().Update(ctx, &cronRun)
+	}
+	if !strings.HasPrefix(cronRun.Status.Message, templateRejected) && !strings.HasPrefix(cronRun.Status.Message, nameCollisionPrefix) {
+		cronRun.Status.Message = ""
+	}
+	result := ctrl.Result{RequeueAfter: next.Sub(now)}
+
+	if cronRun.Spec.Suspend || missed.IsZero() {
+		return result, r.Status().Update(ctx, &cronRun)
+	}
+	if d := cronRun.Spec.StartingDeadlineSeconds; d != nil && missed.Add(time.Duration(*d)*time.Second).Before(now) {
+		log.Info("Skipping TestRun missed past its starting deadline", "scheduledAt", missed)
+		return result, r.Status().Update(ctx, &cronRun)
+	}
+
+	// 5. Apply the concurrency policy to the TestRuns still running
+	switch cronRun.Spec.ConcurrencyPolicy {
+	case appv1alpha1.ConcurrencyForbid:
+		if len(active) > 0 {
+			log.Info("Skipping TestRun while an earlier one runs", "scheduledAt", missed, "active", cronRun.Status.Active)
+			return result, r.Status().Update(ctx, &cronRun)
+		}
+	case appv1alpha1.ConcurrencyReplace:
+		for _, child := range active {
+			if err := r.Delete(ctx, child, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+				return ctrl.Result{}, err
+			}
+			log.Info("Deleted running TestRun to replace it", "testRun", child.Name)
+		}
+		cronRun.Status.Active = nil
+	}
+
+	// 6. Start the TestRun for the missed schedule time
+	child := r.defineTestRun(&cronRun, missed)
+	if err := ctrl.SetControllerReference(&cronRun, child, r.Scheme); err != nil {
+		return ctrl.Result{}, err
+	}
+	err = r.Create(ctx, child)
+	if errors.IsAlreadyExists(err) {
+		// The TestRun of this schedule time was created by an earlier reconcile, unless something else
+		// took its name; skip this schedule time then rather than adopt it
+		collision, err := nameCollision(ctx, r.Client, child, &cronRun, cronTestRunLabel)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if collision != "" {
+			log.Info("Skipping TestRun whose name is taken", "testRun", child.Name, "scheduledAt", missed)
+			cronRun.Status.Message = collision
+			cronRun.Status.LastScheduleTime = &metav1.Time{Time: missed}
+			if r.Recorder != nil {
+				r.Recorder.Eventf(&cronRun, nil, corev1.EventTypeWarning, "NameCollision", "CreateTestRun", "%s", collision)
+			}
+			return result, r.Status().Update(ctx, &cronRun)
+		}
+	} else if err != nil {
+		if !errors.IsInvalid(err) {
+			return ctrl.Result{}, err
+		}
+		// The TestRun webhook rejected the template (the CronTestRun webhook catches this at admission
+		// when enabled); skip this schedule time and try again at the next one
+		log.Error(err, "TestRun template rejected", "scheduledAt", missed)
+		cronRun.Status.Message = templateRejected + err.Error()
+		cronRun.Status.LastScheduleTime = &metav1.Time{Time: missed}
+		if r.Recorder != nil {
+			r.Recorder.Eventf(&cronRun, nil, corev1.EventTypeWarning, "InvalidTemplate", "CreateTestRun", "%s", err.Error())
+		}
+		return result, r.Status().Update(ctx, &cronRun)
+	}
+	log.Info("Started scheduled TestRun", "testRun", child.Name, "scheduledAt", missed)
+	cronRun.Status.Message = ""
+	cronRun.Status.Active = append(cronRun.Status.Active, child.Name)
+	cronRun.Status.LastScheduleTime = &metav1.Time{Time: missed}
+	return result, r.Status().Update(ctx, &cronRun)
+}
+
+// schedule returns the latest schedule time missed since the last TestRun was started, or zero when
+// none was, and the next schedule time after now.
+func (r *CronTestRunReconciler) schedule(cronRun *appv1alpha1.CronTestRun, now time.Time) (time.Time, time.Time, error) {
+	sched, err := cron.ParseStandard(cronRun.Spec.Schedule)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid schedule %q: %w", cronRun.Spec.Schedule, err)
+	}
+
+	earliest := cronRun.CreationTimestamp.Time
+	if cronRun.Status.LastScheduleTime != nil {
+		earliest = cronRun.Status.LastScheduleTime.Time
+	}
+	if d := cronRun.Spec.StartingDeadlineSeconds; d != nil {
+		// Times missed past the deadline could not start anyway
+		if deadline := now.Add(-time.Duration(*d) * time.Second); deadline.After(earliest) {
+			earliest = deadline
+		}
+	}
+
+	var missed time.Time
+	count := 0
+	for t := sched.Next(earliest); !t.After(now); t = sched.Next(t) {
+		missed = t
+		if count++; count > maxMissedSchedules {
+			return time.Time{}, time.Time{}, fmt.Errorf("more than %d schedule times missed, set startingDeadlineSeconds", maxMissedSchedules)
+		}
+	}
+	return missed, sched.Next(now), nil
+}
+
+// defineTestRun creates the TestRun of a CronTestRun for a schedule time. The name derives from the time
+// so that a retried reconcile does not start it twice.
+func (r *CronTestRunReconciler) defineTestRun(cronRun *appv1alpha1.CronTestRun, scheduled time.Time) *appv1alpha1.TestRun {
+	return &appv1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-%d", cronRun.Name, scheduled.Unix()/60),
+			Namespace:   cronRun.Namespace,
+			Labels:      map[string]string{cronTestRunLabel: cronRun.Name},
+			Annotations: map[string]string{scheduledTimeAnnotation: scheduled.UTC().Format(time.RFC3339)},
+		},
+		Spec: *cronRun.Spec.Template.DeepCopy(),
+	}
+}
+
+// scheduledTime returns the time a TestRun of a CronTestRun was scheduled for, falling back to its
+// creation time.
+func scheduledTime(run *appv1alpha1.TestRun) time.Time {
+	if t, err := time.Parse(time.RFC3339, run.Annotations[scheduledTimeAnnotation]); err == nil {
+		return t
+	}
+	return run.CreationTimestamp.Time
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CronTestRunReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&appv1alpha1.CronTestRun{}).
+		Owns(&appv1alpha1.TestRun{}).
+		Named("crontestrun").
+		Complete(r)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+var _ = Describe("CronTestRun Controller", func() {
+	Context("When a scheduled run is due", func() {
+		const resourceName = "test-cron"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind CronTestRun")
+			err := k8sClient.Get(ctx, typeNamespacedName, &appv1alpha1.CronTestRun{})
+			if err != nil && errors.IsNotFound(err) {
+				resource := &appv1alpha1.CronTestRun{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: "default",
+					},
+					Spec: appv1alpha1.CronTestRunSpec{
+						Schedule:          "*/5 * * * *",
+						ConcurrencyPolicy: appv1alpha1.ConcurrencyForbid,
+						Template: appv1alpha1.TestRunSpec{
+							AppName: "test-resource",
+							Script:  "assert(true)",
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &appv1alpha1.CronTestRun{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+
+			By("Cleanup the specific resource instance CronTestRun")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should start a TestRun and skip the next one while it runs", func() {
+			By("pretending the last run was scheduled an hour ago")
+			resource := &appv1alpha1.CronTestRun{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Status.LastScheduleTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+			Expect(k8sClient.Status().Update(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &CronTestRunReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("<=", 5*time.Minute))
+
+			var runs appv1alpha1.TestRunList
+			Expect(k8sClient.List(ctx, &runs, client.InNamespace("default"), client.MatchingLabels{cronTestRunLabel: resourceName})).To(Succeed())
+			Expect(runs.Items).To(HaveLen(1))
+			run := runs.Items[0]
+			Expect(run.Spec.Script).To(Equal("assert(true)"))
+			Expect(run.Annotations).To(HaveKey(scheduledTimeAnnotation))
+			Expect(run.OwnerReferences).To(HaveLen(1))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Active).To(Equal([]string{run.Name}))
+			Expect(resource.Status.LastScheduleTime.Time).To(BeTemporally(">", time.Now().Add(-5*time.Minute)))
+
+			By("skipping the next run while the first one has not finished")
+			resource.Status.LastScheduleTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+			Expect(k8sClient.Status().Update(ctx, resource)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.List(ctx, &runs, client.InNamespace("default"), client.MatchingLabels{cronTestRunLabel: resourceName})).To(Succeed())
+			Expect(runs.Items).To(HaveLen(1))
+
+			By("recording the result once it finishes")
+			run.Status.State = "Passed"
+			run.Status.Result = "1 of 1 test cases passed"
+			Expect(k8sClient.Status().Update(ctx, &run)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.LastRun).To(Equal(run.Name))
+			Expect(resource.Status.LastState).To(Equal("Passed"))
+			Expect(resource.Status.LastResult).To(Equal("1 of 1 test cases passed"))
+		})
+	})
+})
@@ -0,0 +1,126 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// credentialsSecretName is the Secret the controller manages for a database without CredentialsSecretRef.
+func credentialsSecretName(name string) string {
+	return name + "-credentials"
+}
+
+// reconcileCredentials resolves the Secret holding a database's credentials and returns its name and values.
+// A referenced Secret is used as is. Otherwise inline credentials are copied into a managed Secret, or
+// credentials are generated once and kept for the lifetime of the App.
+func (r *AppReconciler) reconcileCredentials(ctx context.Context, app *appsv1alpha1.App, db appsv1alpha1.DatabaseSpec, name string, labels map[string]string) (string, map[string]string, error) {
+	log := logf.FromContext(ctx)
+
+	if db.CredentialsSecretRef != nil {
+		var secret corev1.Secret
+		if err := r.Get(ctx, types.NamespacedName{Name: db.CredentialsSecretRef.Name, Namespace: app.Namespace}, &secret); err != nil {
+			return "", nil, fmt.Errorf("credentials Secret %q: %w", db.CredentialsSecretRef.Name, err)
+		}
+		return secret.Name, secretCredentials(&secret), nil
+	}
+
+	secretName := credentialsSecretName(name)
+	var existing corev1.Secret
+	err := r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: app.Namespace}, &existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return "", nil, err
+	}
+	found := err == nil
+
+	creds := db.Credentials
+	if len(creds) == 0 {
+		// Keep generated credentials stable: the database was initialized with them
+		if found {
+			return secretName, secretCredentials(&existing), nil
+		}
+		password, err := randomPassword()
+		if err != nil {
+			return "", nil, err
+		}
+		creds = engineFor(db).DefaultCredentials(strings.ReplaceAll(db.Name, "-", "_"), password)
+		log.Info("Generating database credentials", "secret", secretName)
+	}
+
+	desired := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: app.Namespace,
+			Labels:    labels,
+		},
+		Data: map[string][]byte{},
+	}
+	for k, v := range creds {
+		desired.Data[k] = []byte(v)
+	}
+	if err := ctrl.SetControllerReference(app, desired, r.Scheme); err != nil {
+		return "", nil, err
+	}
+
+	if !found {
+		if err := r.Create(ctx, desired); err != nil {
+			return "", nil, err
+		}
+	} else if !maps.EqualFunc(existing.Data, desired.Data, bytes.Equal) {
+		existing.Data = desired.Data
+		existing.Labels = desired.Labels
+		if err := r.Update(ctx, &existing); err != nil {
+			return "", nil, err
+		}
+	}
+	return secretName, creds, nil
+}
+
+// secretCredentials reads the user, password and dbname keys of a credentials Secret.
+func secretCredentials(secret *corev1.Secret) map[string]string {
+	creds := map[string]string{}
+	for _, key := range []string{"user", "password", "dbname"} {
+		if v, ok := secret.Data[key]; ok {
+			creds[key] = string(v)
+		}
+	}
+	return creds
+}
+
+// randomPassword returns 32 hex characters from crypto/rand.
+func randomPassword() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
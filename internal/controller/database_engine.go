@@ -0,0 +1,211 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	appsv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// dbEngine describes how the controller runs, probes and initializes one database engine.
+// Credential values never appear in generated specs; they are read from the database's
+// credentials Secret through env vars.
+type dbEngine struct {
+	// ClientImage runs the schema init Job
+	ClientImage string
+	// Env maps credential keys onto the engine image's configuration variables.
+	// creds holds the resolved values so engines can choose a startup mode.
+	Env func(secretName string, creds map[string]string) []corev1.EnvVar
+	// Args overrides the container args (nil keeps the image default)
+	Args func(creds map[string]string, persistent bool) []string
+	// DataDir is where the engine keeps its data, and where persistent storage is mounted
+	DataDir string
+	// StorageEnv is added to Env when DataDir is on persistent storage
+	StorageEnv []corev1.EnvVar
+	// ReadyCheck is a shell command run inside the database container for its readiness probe
+	ReadyCheck func(port int32) string
+	// ClientEnv maps credential keys onto the env vars MigrateCommand reads
+	ClientEnv map[string]string
+	// MigrateCommand waits for the database at host:port and then runs the script at file
+	MigrateCommand func(host string, port int32, file string) string
+	// DefaultCredentials returns the credentials generated for a database that declares none
+	DefaultCredentials func(dbName, password string) map[string]string
+}
+
+var dbEngines = map[string]dbEngine{
+	appsv1alpha1.DatabaseEnginePostgres: {
+		ClientImage: "postgres:16-alpine",
+		Env: func(secretName string, creds map[string]string) []corev1.EnvVar {
+			return secretEnv(secretName, creds, map[string]string{
+				"user":     "POSTGRES_USER",
+				"password": "POSTGRES_PASSWORD",
+				"dbname":   "POSTGRES_DB",
+			})
+		},
+		DataDir: "/var/lib/postgresql/data",
+		// initdb refuses a mount point that holds lost+found, so keep the cluster in a subdirectory
+		StorageEnv: []corev1.EnvVar{{Name: "PGDATA", Value: "/var/lib/postgresql/data/pgdata"}},
+		ReadyCheck: func(port int32) string {
+			return fmt.Sprintf(`pg_isready -h 127.0.0.1 -p %d`, port)
+		},
+		// libpq reads these natively
+		ClientEnv: map[string]string{"user": "PGUSER", "password": "PGPASSWORD", "dbname": "PGDATABASE"},
+		MigrateCommand: func(host string, port int32, file string) string {
+			return fmt.Sprintf(
+				`until pg_isready -h %s -p %d; do echo "waiting for db..."; sleep 2; done; psql -h %s -p %d -v ON_ERROR_STOP=1 --single-transaction -f %s`,
+				host, port, host, port, file,
+			)
+		},
+		DefaultCredentials: func(dbName, password string) map[string]string {
+			return map[string]string{"user": "postgres", "password": password, "dbname": dbName}
+		},
+	},
+	appsv1alpha1.DatabaseEngineMySQL: {
+		ClientImage: "mysql:8.4",
+		Env: func(secretName string, creds map[string]string) []corev1.EnvVar {
+			envVars := secretEnv(secretName, creds, map[string]string{"dbname": "MYSQL_DATABASE"})
+			// The mysql image refuses to start without a root password policy
+			switch {
+			case creds["user"] == "root":
+				envVars = append(envVars, secretEnv(secretName, creds, map[string]string{"password": "MYSQL_ROOT_PASSWORD"})...)
+			case creds["password"] == "":
+				envVars = append(envVars, secretEnv(secretName, creds, map[string]string{"user": "MYSQL_USER"})...)
+				envVars = append(envVars, corev1.EnvVar{Name: "MYSQL_ALLOW_EMPTY_PASSWORD", Value: "yes"})
+			default:
+				envVars = append(envVars, secretEnv(secretName, creds, map[string]string{"user": "MYSQL_USER", "password": "MYSQL_PASSWORD"})...)
+				envVars = append(envVars, corev1.EnvVar{Name: "MYSQL_RANDOM_ROOT_PASSWORD", Value: "yes"})
+			}
+			return envVars
+		},
+		DataDir: "/var/lib/mysql",
+		ReadyCheck: func(port int32) string {
+			return fmt.Sprintf(`mysqladmin ping -h 127.0.0.1 -P %d --silent`, port)
+		},
+		ClientEnv: map[string]string{"user": "DB_USER", "password": "MYSQL_PWD", "dbname": "DB_NAME"},
+		MigrateCommand: func(host string, port int32, file string) string {
+			return fmt.Sprintf(
+				`until mysqladmin ping -h %s -P %d --silent; do echo "waiting for db..."; sleep 2; done; mysql -h %s -P %d -u "$DB_USER" "$DB_NAME" < %s`,
+				host, port, host, port, file,
+			)
+		},
+		DefaultCredentials: func(dbName, password string) map[string]string {
+			return map[string]string{"user": "app", "password": password, "dbname": dbName}
+		},
+	},
+	appsv1alpha1.DatabaseEngineRedis: {
+		ClientImage: "redis:7-alpine",
+		Env: func(secretName string, creds map[string]string) []corev1.EnvVar {
+			return secretEnv(secretName, creds, map[string]string{"password": "REDIS_PASSWORD"})
+		},
+		Args: func(creds map[string]string, persistent bool) []string {
+			var args []string
+			if creds["password"] != "" {
+				args = append(args, "--requirepass", "$(REDIS_PASSWORD)")
+			}
+			if persistent {
+				args = append(args, "--appendonly", "yes")
+			}
+			if len(args) == 0 {
+				return nil
+			}
+			return append([]string{"redis-server"}, args...)
+		},
+		DataDir: "/data",
+		ReadyCheck: func(port int32) string {
+			return fmt.Sprintf(`REDISCLI_AUTH="$REDIS_PASSWORD" redis-cli -p %d ping | grep -q PONG`, port)
+		},
+		ClientEnv: map[string]string{"password": "REDISCLI_AUTH"},
+		MigrateCommand: func(host string, port int32, file string) string {
+			return fmt.Sprintf(
+				`until redis-cli -h %s -p %d ping | grep -q PONG; do echo "waiting for db..."; sleep 2; done; redis-cli -h %s -p %d < %s`,
+				host, port, host, port, file,
+			)
+		},
+		DefaultCredentials: func(_, password string) map[string]string {
+			return map[string]string{"password": password}
+		},
+	},
+	appsv1alpha1.DatabaseEngineMongoDB: {
+		ClientImage: "mongo:7",
+		Env: func(secretName string, creds map[string]string) []corev1.EnvVar {
+			return secretEnv(secretName, creds, map[string]string{
+				"user":     "MONGO_INITDB_ROOT_USERNAME",
+				"password": "MONGO_INITDB_ROOT_PASSWORD",
+				"dbname":   "MONGO_INITDB_DATABASE",
+			})
+		},
+		DataDir: "/data/db",
+		ReadyCheck: func(port int32) string {
+			return fmt.Sprintf(`mongosh --quiet --port %d --eval "db.adminCommand('ping').ok"`, port)
+		},
+		ClientEnv: map[string]string{"user": "DB_USER", "password": "DB_PASSWORD", "dbname": "DB_NAME"},
+		MigrateCommand: func(host string, port int32, file string) string {
+			return fmt.Sprintf(
+				`until mongosh --quiet --host %s --port %d --eval "db.adminCommand('ping').ok"; do echo "waiting for db..."; sleep 2; done; `+
+					`if [ -n "$DB_USER" ]; then set -- --username "$DB_USER" --password "$DB_PASSWORD" --authenticationDatabase admin; fi; `+
+					`mongosh --quiet --host %s --port %d "$@" "$DB_NAME" --file %s`,
+				host, port, host, port, file,
+			)
+		},
+		DefaultCredentials: func(dbName, password string) map[string]string {
+			return map[string]string{"user": "root", "password": password, "dbname": dbName}
+		},
+	},
+}
+
+// engineFor returns the engine definition for a database, defaulting to Postgres.
+func engineFor(db appsv1alpha1.DatabaseSpec) dbEngine {
+	if e, ok := dbEngines[db.Engine]; ok {
+		return e
+	}
+	return dbEngines[appsv1alpha1.DatabaseEnginePostgres]
+}
+
+// databasePort returns the configured port or the engine default.
+func databasePort(db appsv1alpha1.DatabaseSpec) int32 {
+	if db.Port != 0 {
+		return db.Port
+	}
+	return appsv1alpha1.DefaultDatabasePort(db.Engine)
+}
+
+// secretEnv maps present credential keys to env vars sourced from the credentials Secret, in a stable order.
+func secretEnv(secretName string, creds map[string]string, names map[string]string) []corev1.EnvVar {
+	envVars := []corev1.EnvVar{}
+	for _, key := range []string{"user", "password", "dbname"} {
+		name, ok := names[key]
+		if !ok {
+			continue
+		}
+		if _, ok := creds[key]; !ok {
+			continue
+		}
+		envVars = append(envVars, corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+					Key:                  key,
+				},
+			},
+		})
+	}
+	return envVars
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+const (
+	// initSQLVersion is the migration version InitSQL is applied under
+	initSQLVersion = "initSQL"
+	// migrationMountPath is where the migration Job finds its script
+	migrationMountPath = "/migrations"
+	migrationFile      = "script"
+)
+
+// resolvedMigration is a migration whose script has been located in a ConfigMap and hashed.
+type resolvedMigration struct {
+	Version   string
+	Hash      string
+	ConfigMap string
+	Key       string
+	// Unresolved explains why the script could not be read yet
+	Unresolved string
+}
+
+// migrationsConfigMapName is the ConfigMap the controller manages for a database's inline scripts.
+func migrationsConfigMapName(name string) string {
+	return name + "-migrations"
+}
+
+// migrationJobName is unique per script so an edited, not yet applied migration gets a fresh Job.
+// InitSQL keeps the name of the one-shot init Job it replaced so existing Apps are not re-initialized.
+func migrationJobName(name string, m resolvedMigration) string {
+	if m.Version == initSQLVersion {
+		return name + "-init"
+	}
+	return fmt.Sprintf("%s-migrate-%s", name, m.Hash[:10])
+}
+
+// databaseMigrations returns the ordered migrations of a database, with InitSQL first.
+func databaseMigrations(db appsv1alpha1.DatabaseSpec) []appsv1alpha1.MigrationSpec {
+	if db.InitSQL == "" {
+		return db.Migrations
+	}
+	return append([]appsv1alpha1.MigrationSpec{{Version: initSQLVersion, SQL: db.InitSQL}}, db.Migrations...)
+}
+
+func hashScript(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
+
+// reconcileMigrations applies a database's migrations in order, one Job at a time, and records their
+// state in app.Status.Migrations. An applied migration is never run again, even if its script changes.
+func (r *AppReconciler) reconcileMigrations(ctx context.Context, app *appsv1alpha1.App, db appsv1alpha1.DatabaseSpec, name string, labels map[string]string, secretName string, creds map[string]string) error {
+	migrations := databaseMigrations(db)
+	if len(migrations) == 0 {
+		setDatabaseMigrations(app, db.Name, nil)
+		return nil
+	}
+
+	resolved, err := r.resolveMigrations(ctx, app, migrations, name, labels)
+	if err != nil {
+		return err
+	}
+
+	previous := map[string]appsv1alpha1.MigrationStatus{}
+	for _, st := range app.Status.Migrations {
+		if st.Database == db.Name {
+			previous[st.Version] = st
+		}
+	}
+
+	statuses := []appsv1alpha1.MigrationStatus{}
+	// Only the first migration that isn't applied runs; the rest wait behind it
+	blocked := false
+	for _, m := range resolved {
+		st := appsv1alpha1.MigrationStatus{Database: db.Name, Version: m.Version, Hash: m.Hash, State: appsv1alpha1.MigrationStatePending}
+		if prev, ok := previous[m.Version]; ok && prev.State == appsv1alpha1.MigrationStateApplied {
+			st = prev
+			st.Message = ""
+			if m.Hash != "" && m.Hash != prev.Hash {
+				st.Message = "script changed after it was applied and will not be rerun; add a new migration instead"
+			}
+			delete(previous, m.Version)
+			statuses = append(statuses, st)
+			continue
+		}
+		delete(previous, m.Version)
+		if blocked {
+			statuses = append(statuses, st)
+			continue
+		}
+		blocked = true
+		if m.Unresolved != "" {
+			st.Message = m.Unresolved
+			statuses = append(statuses, st)
+			continue
+		}
+
+		jobName := migrationJobName(name, m)
+		if err := r.ensureMigrationJob(ctx, app, db, name, jobName, labels, secretName, creds, m); err != nil {
+			return err
+		}
+		state, message, err := r.observeJob(ctx, app.Namespace, jobName)
+		if err != nil {
+			return err
+		}
+		switch state {
+		case "Succeeded":
+			now := metav1.Now()
+			st.State = appsv1alpha1.MigrationStateApplied
+			st.AppliedAt = &now
+			blocked = false
+		case "Failed":
+			st.State = appsv1alpha1.MigrationStateFailed
+			st.Message = message
+		case "Running":
+			st.State = appsv1alpha1.MigrationStateRunning
+		}
+		statuses = append(statuses, st)
+	}
+
+	// Keep the record of applied migrations that were dropped from the spec so re-adding them is a no-op
+	for _, prev := range app.Status.Migrations {
+		if _, dropped := previous[prev.Version]; dropped && prev.Database == db.Name && prev.State == appsv1alpha1.MigrationStateApplied {
+			statuses = append(statuses, prev)
+		}
+	}
+
+	setDatabaseMigrations(app, db.Name, statuses)
+	return nil
+}
+
+// resolveMigrations hashes every script, copying inline scripts into the managed migrations ConfigMap.
+func (r *AppReconciler) resolveMigrations(ctx context.Context, app *appsv1alpha1.App, migrations []appsv1alpha1.MigrationSpec, name string, labels map[string]string) ([]resolvedMigration, error) {
+	resolved := make([]resolvedMigration, 0, len(migrations))
+	inline := map[string]string{}
+
+	for _, m := range migrations {
+		if m.ConfigMapRef == nil {
+			hash := hashScript(m.SQL)
+			key := hash + ".sql"
+			inline[key] = m.SQL
+			resolved = append(resolved, resolvedMigration{Version: m.Version, Hash: hash, ConfigMap: migrationsConfigMapName(name), Key: key})
+			continue
+		}
+
+		ref := m.ConfigMapRef
+		rm := resolvedMigration{Version: m.Version, ConfigMap: ref.Name, Key: ref.Key}
+		var cm corev1.ConfigMap
+		err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: app.Namespace}, &cm)
+		switch {
+		case errors.IsNotFound(err):
+			rm.Unresolved = fmt.Sprintf("waiting for ConfigMap %s", ref.Name)
+		case err != nil:
+			return nil, err
+		default:
+			if script, ok := cm.Data[ref.Key]; ok {
+				rm.Hash = hashScript(script)
+			} else {
+				rm.Unresolved = fmt.Sprintf("ConfigMap %s has no key %s", ref.Name, ref.Key)
+			}
+		}
+		resolved = append(resolved, rm)
+	}
+
+	if len(inline) == 0 {
+		return resolved, nil
+	}
+
+	desired := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      migrationsConfigMapName(name),
+			Namespace: app.Namespace,
+			Labels:    labels,
+		},
+		Data: inline,
+	}
+	if err := ctrl.SetControllerReference(app, desired, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	var existing corev1.ConfigMap
+	err := r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: app.Namespace}, &existing)
+	if errors.IsNotFound(err) {
+		return resolved, r.Create(ctx, desired)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !maps.Equal(existing.Data, desired.Data) {
+		existing.Data = desired.Data
+		existing.Labels = desired.Labels
+		if err := r.Update(ctx, &existing); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// ensureMigrationJob creates the Job that applies one migration, mounting its script as a file.
+func (r *AppReconciler) ensureMigrationJob(ctx context.Context, app *appsv1alpha1.App, db appsv1alpha1.DatabaseSpec, name, jobName string, labels map[string]string, secretName string, creds map[string]string, m resolvedMigration) error {
+	var existing batchv1.Job
+	err := r.Get(ctx, types.NamespacedName{Name: jobName, Namespace: app.Namespace}, &existing)
+	if err == nil || !errors.IsNotFound(err) {
+		return err
+	}
+
+	logf.FromContext(ctx).Info("Creating migration Job", "name", jobName, "version", m.Version)
+
+	engine := engineFor(db)
+	host := name // Service name = DB hostname
+	migrateCmd := engine.MigrateCommand(host, databasePort(db), migrationMountPath+"/"+migrationFile)
+
+	backoffLimit := int32(3)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: app.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyOnFailure,
+					Containers: []corev1.Container{{
+						Name:    "migrate",
+						Image:   engine.ClientImage,
+						Command: []string{"sh", "-c", migrateCmd},
+						// Credentials reach the shell through env, never the command line
+						Env: secretEnv(secretName, creds, engine.ClientEnv),
+						VolumeMounts: []corev1.VolumeMount{{
+							Name:      "migration",
+							MountPath: migrationMountPath,
+							ReadOnly:  true,
+						}},
+					}},
+					Volumes: []corev1.Volume{{
+						Name: "migration",
+						VolumeSource: corev1.VolumeSource{
+							ConfigMap: &corev1.ConfigMapVolumeSource{
+								LocalObjectReference: corev1.LocalObjectReference{Name: m.ConfigMap},
+								Items:                []corev1.KeyToPath{{Key: m.Key, Path: migrationFile}},
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	if err := ctrl.SetControllerReference(app, job, r.Scheme); err != nil {
+		return err
+	}
+	return r.Create(ctx, job)
+}
+
+// setDatabaseMigrations replaces the migration records of one database in the App status.
+func setDatabaseMigrations(app *appsv1alpha1.App, dbName string, statuses []appsv1alpha1.MigrationStatus) {
+	kept := []appsv1alpha1.MigrationStatus{}
+	for _, st := range app.Status.Migrations {
+		if st.Database != dbName {
+			kept = append(kept, st)
+		}
+	}
+	app.Status.Migrations = append(kept, statuses...)
+}
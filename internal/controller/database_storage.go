@@ -0,0 +1,152 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// storageFinalizer holds App deletion until database volumes are deleted or retained per their policy
+const storageFinalizer = "topas.io/storage-retention"
+
+// dataVolumeName is the volume claim template of database StatefulSets; claims are named data-<app>-<db>-0
+const dataVolumeName = "data"
+
+// hasPersistentDatabase reports whether any database of the App requests storage.
+func hasPersistentDatabase(app *appsv1alpha1.App) bool {
+	for _, db := range app.Spec.Databases {
+		if db.Storage != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileDatabaseStatefulSet runs a database as a single-replica StatefulSet with its data on a PersistentVolumeClaim.
+func (r *AppReconciler) reconcileDatabaseStatefulSet(ctx context.Context, app *appsv1alpha1.App, db appsv1alpha1.DatabaseSpec, name string, labels map[string]string, template corev1.PodTemplateSpec) error {
+	template.Spec.Containers[0].VolumeMounts = append(template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      dataVolumeName,
+		MountPath: engineFor(db).DataDir,
+	})
+
+	replicas := int32(1)
+	desired := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: app.Namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			Selector:    &metav1.LabelSelector{MatchLabels: labels},
+			ServiceName: name,
+			Template:    template,
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: dataVolumeName, Labels: labels},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					StorageClassName: db.Storage.StorageClassName,
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: db.Storage.Size},
+					},
+				},
+			}},
+			// Claims outlive the StatefulSet; the App finalizer applies the retention policy
+			PersistentVolumeClaimRetentionPolicy: &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+				WhenDeleted: appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+				WhenScaled:  appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+			},
+		},
+	}
+
+	if err := ctrl.SetControllerReference(app, desired, r.Scheme); err != nil {
+		return err
+	}
+
+	var existing appsv1.StatefulSet
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: app.Namespace}, &existing)
+	if errors.IsNotFound(err) {
+		if err := markApplied(desired, statefulSetManagedSpec(desired)); err != nil {
+			return err
+		}
+		return r.Create(ctx, desired)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Selector, service name and claim templates are immutable; only the pod template rolls
+	if err := r.correctDrift(app, &existing, "StatefulSet", statefulSetManagedSpec(desired), statefulSetManagedSpec(&existing)); err != nil {
+		return err
+	}
+	existing.Spec.Replicas = desired.Spec.Replicas
+	existing.Spec.Template = desired.Spec.Template
+	existing.Labels = desired.Labels
+	return r.Update(ctx, &existing)
+}
+
+// statefulSetManagedSpec is the part of a database StatefulSet that is updated in place.
+func statefulSetManagedSpec(sts *appsv1.StatefulSet) *appsv1.StatefulSetSpec {
+	return &appsv1.StatefulSetSpec{Replicas: sts.Spec.Replicas, Template: sts.Spec.Template}
+}
+
+// applyRetentionPolicy deletes the volume claims of databases whose retention policy is Delete.
+func (r *AppReconciler) applyRetentionPolicy(ctx context.Context, app *appsv1alpha1.App) error {
+	log := logf.FromContext(ctx)
+
+	for _, db := range app.Spec.Databases {
+		if db.Storage == nil || db.Storage.RetentionPolicy == appsv1alpha1.RetentionPolicyRetain {
+			continue
+		}
+
+		var claims corev1.PersistentVolumeClaimList
+		if err := r.List(ctx, &claims, client.InNamespace(app.Namespace), client.MatchingLabels{
+			"app":                         db.Name,
+			"app.kubernetes.io/part-of":   app.Name,
+			"app.kubernetes.io/component": "database",
+		}); err != nil {
+			return err
+		}
+		for i := range claims.Items {
+			log.Info("Deleting database volume", "name", claims.Items[i].Name, "database", db.Name)
+			if err := r.Delete(ctx, &claims.Items[i]); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deleteIfExists deletes the named object of obj's kind, if there is one.
+func (r *AppReconciler) deleteIfExists(ctx context.Context, obj client.Object, name, namespace string) error {
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	logf.FromContext(ctx).Info("Deleting replaced workload", "name", name)
+	return client.IgnoreNotFound(r.Delete(ctx, obj))
+}
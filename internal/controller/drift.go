@@ -0,0 +1,182 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// appliedSpecAnnotation holds a hash of the managed fields the controller last applied to an object.
+// Drift is only reported while the desired state still matches it, so App spec changes are not drift.
+const appliedSpecAnnotation = "topas.io/applied-spec"
+
+// markApplied records desired as the state applied to obj.
+func markApplied(obj client.Object, desired any) error {
+	hash, err := hashSpec(desired)
+	if err != nil {
+		return err
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[appliedSpecAnnotation] = hash
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// correctDrift compares the managed fields of an existing object with the desired ones before they are
+// overwritten. Fields changed out of band since the last apply are reported on the App's Drifted
+// condition and as a Warning event, and existing is marked with the new applied state.
+func (r *AppReconciler) correctDrift(app *appsv1alpha1.App, existing client.Object, kind string, desired, actual any) error {
+	hash, err := hashSpec(desired)
+	if err != nil {
+		return err
+	}
+	if existing.GetAnnotations()[appliedSpecAnnotation] == hash {
+		want, err := runtime.DefaultUnstructuredConverter.ToUnstructured(desired)
+		if err != nil {
+			return err
+		}
+		got, err := runtime.DefaultUnstructuredConverter.ToUnstructured(actual)
+		if err != nil {
+			return err
+		}
+		if fields := driftedFields("spec", want, got, nil); len(fields) > 0 {
+			entry := fmt.Sprintf("%s %s: %s", kind, existing.GetName(), strings.Join(fields, ", "))
+			r.event(app, corev1.EventTypeWarning, "DriftCorrected", "Correct", "Reverting out-of-band changes to "+entry)
+			addDrift(app, entry)
+		}
+	}
+	return markApplied(existing, desired)
+}
+
+// driftedFields lists the paths where actual differs from desired. Only fields set in desired are
+// compared, so values defaulted by the API server are not reported.
+func driftedFields(path string, desired, actual any, out []string) []string {
+	switch d := desired.(type) {
+	case map[string]any:
+		a, _ := actual.(map[string]any)
+		for _, k := range slices.Sorted(maps.Keys(d)) {
+			child := path + "." + k
+			if strings.ContainsAny(k, "./") {
+				child = path + "[" + k + "]"
+			}
+			out = driftedFields(child, d[k], a[k], out)
+		}
+	case []any:
+		a, _ := actual.([]any)
+		if len(a) != len(d) {
+			return append(out, path)
+		}
+		for i := range d {
+			out = driftedFields(fmt.Sprintf("%s[%d]", path, i), d[i], a[i], out)
+		}
+	default:
+		if !reflect.DeepEqual(desired, actual) {
+			out = append(out, path)
+		}
+	}
+	return out
+}
+
+// beginDriftCheck takes the Drifted condition out of the App so this reconcile's findings replace it,
+// and returns the previous one for finishDriftCheck.
+func beginDriftCheck(app *appsv1alpha1.App) *metav1.Condition {
+	prev := meta.FindStatusCondition(app.Status.Conditions, appsv1alpha1.AppConditionDrifted)
+	if prev != nil {
+		prev = prev.DeepCopy()
+	}
+	meta.RemoveStatusCondition(&app.Status.Conditions, appsv1alpha1.AppConditionDrifted)
+	return prev
+}
+
+// addDrift appends a corrected object to this reconcile's Drifted condition.
+func addDrift(app *appsv1alpha1.App, entry string) {
+	msg := "Reverted out-of-band changes: " + entry
+	if c := meta.FindStatusCondition(app.Status.Conditions, appsv1alpha1.AppConditionDrifted); c != nil {
+		msg = c.Message + "; " + entry
+	}
+	meta.SetStatusCondition(&app.Status.Conditions, metav1.Condition{
+		Type:               appsv1alpha1.AppConditionDrifted,
+		Status:             metav1.ConditionTrue,
+		Reason:             "DriftCorrected",
+		Message:            msg,
+		ObservedGeneration: app.Generation,
+	})
+}
+
+// finishDriftCheck settles the Drifted condition when this reconcile found nothing: a correction stays
+// reported until the App spec next changes.
+func finishDriftCheck(app *appsv1alpha1.App, prev *metav1.Condition) {
+	if meta.FindStatusCondition(app.Status.Conditions, appsv1alpha1.AppConditionDrifted) != nil {
+		return
+	}
+	if prev != nil && prev.Status == metav1.ConditionTrue && prev.ObservedGeneration == app.Generation {
+		app.Status.Conditions = append(app.Status.Conditions, *prev)
+		return
+	}
+	cond := metav1.Condition{
+		Type:               appsv1alpha1.AppConditionDrifted,
+		Status:             metav1.ConditionFalse,
+		Reason:             "NoDrift",
+		Message:            "No out-of-band changes detected",
+		ObservedGeneration: app.Generation,
+	}
+	if prev != nil && prev.Status == metav1.ConditionFalse {
+		cond.LastTransitionTime = prev.LastTransitionTime
+	}
+	meta.SetStatusCondition(&app.Status.Conditions, cond)
+}
+
+// serviceManagedSpec is the part of a Service the controller owns; the cluster IP and other allocated
+// fields are left alone.
+func serviceManagedSpec(svc *corev1.Service) *corev1.ServiceSpec {
+	return &corev1.ServiceSpec{Ports: svc.Spec.Ports, Selector: svc.Spec.Selector}
+}
+
+// hashSpec identifies a desired state.
+func hashSpec(desired any) (string, error) {
+	raw, err := json.Marshal(desired)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// event records an event on the App when the reconciler has a recorder.
+func (r *AppReconciler) event(app *appsv1alpha1.App, eventtype, reason, action, note string) {
+	if r.Recorder != nil {
+		r.Recorder.Eventf(app, nil, eventtype, reason, action, "%s", note)
+	}
+}
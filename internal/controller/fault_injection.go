@@ -0,0 +1,134 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+	"github.com/chakradharkondapalli/topas/pkg/faultproxy"
+)
+
+// faultProxyImage returns the image of the fault-injection proxy run next to services with fault injection.
+func faultProxyImage() string {
+	if image := os.Getenv("FAULT_PROXY_IMAGE"); image != "" {
+		return image
+	}
+	return "localhost/topas-fault-proxy:latest"
+}
+
+// applyFaultProxy adds the fault-injection proxy to a service's pod. It listens on the fault proxy ports
+// the Service targets and forwards to the service's own ports on localhost.
+func applyFaultProxy(spec *corev1.PodSpec, svc appsv1alpha1.ServiceSpec) error {
+	args := []string{
+		fmt.Sprintf("--admin-port=%d", appsv1alpha1.FaultProxyAdminPort),
+		fmt.Sprintf("--route=%d:%d", appsv1alpha1.FaultProxyHTTPPort, svc.Port),
+	}
+	ports := []corev1.ContainerPort{
+		{Name: "fault-admin", ContainerPort: appsv1alpha1.FaultProxyAdminPort},
+		{Name: "fault-http", ContainerPort: appsv1alpha1.FaultProxyHTTPPort},
+	}
+	if svc.GrpcPort != nil {
+		args = append(args, fmt.Sprintf("--route=%d:%d", appsv1alpha1.FaultProxyGRPCPort, *svc.GrpcPort))
+		ports = append(ports, corev1.ContainerPort{Name: "fault-grpc", ContainerPort: appsv1alpha1.FaultProxyGRPCPort})
+	}
+	if f := svc.FaultInjection.Faults; f != nil {
+		raw, err := json.Marshal(f)
+		if err != nil {
+			return err
+		}
+		args = append(args, "--faults="+string(raw))
+	}
+
+	spec.Containers = append(spec.Containers, corev1.Container{
+		Name:            appsv1alpha1.FaultProxyContainerName,
+		Image:           faultProxyImage(),
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Args:            args,
+		Ports:           ports,
+	})
+	return nil
+}
+
+// faultProxyPods returns the running pods of a service that carry the fault-injection proxy.
+func faultProxyPods(ctx context.Context, c client.Client, namespace, appName, serviceName string) ([]corev1.Pod, error) {
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(namespace), client.MatchingLabels{
+		"app":                          serviceName,
+		"app.kubernetes.io/managed-by": "topas",
+		"app.kubernetes.io/part-of":    appName,
+	}); err != nil {
+		return nil, err
+	}
+	var running []corev1.Pod
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+		for _, c := range pod.Spec.Containers {
+			if c.Name == appsv1alpha1.FaultProxyContainerName {
+				running = append(running, pod)
+				break
+			}
+		}
+	}
+	return running, nil
+}
+
+// resetFaults returns the fault-injection proxies of the run's App to their configured faults, so what a
+// test injected does not outlive it. Unreachable proxies are logged and skipped.
+func (r *TestRunReconciler) resetFaults(ctx context.Context, run *appsv1alpha1.TestRun) error {
+	log := logf.FromContext(ctx)
+	appName, namespace := run.Spec.AppName, run.Namespace
+	if env := run.Status.Environment; env != nil {
+		appName, namespace = env.AppName, env.Namespace
+	}
+
+	var app appsv1alpha1.App
+	if err := r.Get(ctx, types.NamespacedName{Name: appName, Namespace: namespace}, &app); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	proxies := faultproxy.Client{HTTP: &http.Client{Timeout: 5 * time.Second}}
+	for _, svc := range app.Spec.Services {
+		if svc.FaultInjection == nil {
+			continue
+		}
+		pods, err := faultProxyPods(ctx, r.Client, namespace, appName, svc.Name)
+		if err != nil {
+			return err
+		}
+		for _, pod := range pods {
+			addr := net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(appsv1alpha1.FaultProxyAdminPort)))
+			if err := proxies.Reset(ctx, addr); err != nil {
+				log.Error(err, "Failed to reset faults", "service", svc.Name, "pod", pod.Name)
+			}
+		}
+	}
+	return nil
+}
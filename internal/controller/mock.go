@@ -0,0 +1,148 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	appsv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+const (
+	// stubConfigDir is where a mock's stub server reads its stub lists and descriptors
+	stubConfigDir = "/etc/topas/stubs"
+	// stubVolumeName is the projected volume holding a mock's stub configuration
+	stubVolumeName = "topas-stubs"
+	// specStubsKey holds the stubs declared in the App spec
+	specStubsKey = "spec.json"
+)
+
+// stubImage returns the image of the built-in stub server run for mock services.
+func stubImage() string {
+	if image := os.Getenv("STUB_IMAGE"); image != "" {
+		return image
+	}
+	return "localhost/topas-stub:latest"
+}
+
+// stubsConfigMapName names the ConfigMap holding the spec stubs of a mock service.
+func stubsConfigMapName(name string) string {
+	return name + "-stubs"
+}
+
+// reconcileStubs writes the stubs declared on a mock service to the ConfigMap its stub server reads.
+// The server reloads them on change, so stubs added at runtime survive a spec update.
+func (r *AppReconciler) reconcileStubs(ctx context.Context, app *appsv1alpha1.App, svc appsv1alpha1.ServiceSpec, name string) error {
+	stubs := []appsv1alpha1.StubSpec{}
+	if svc.Mock != nil && svc.Mock.Stubs != nil {
+		stubs = svc.Mock.Stubs
+	}
+	raw, err := json.Marshal(stubs)
+	if err != nil {
+		return err
+	}
+
+	desired := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      stubsConfigMapName(name),
+			Namespace: app.Namespace,
+			Labels: map[string]string{
+				"app":                          svc.Name,
+				"app.kubernetes.io/managed-by": "topas",
+				"app.kubernetes.io/part-of":    app.Name,
+			},
+		},
+		Data: map[string]string{specStubsKey: string(raw)},
+	}
+	if err := ctrl.SetControllerReference(app, desired, r.Scheme); err != nil {
+		return err
+	}
+
+	var existing corev1.ConfigMap
+	err = r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: app.Namespace}, &existing)
+	if errors.IsNotFound(err) {
+		return r.Create(ctx, desired)
+	}
+	if err != nil {
+		return err
+	}
+	if maps.Equal(existing.Data, desired.Data) {
+		return nil
+	}
+	existing.Data = desired.Data
+	existing.Labels = desired.Labels
+	return r.Update(ctx, &existing)
+}
+
+// applyMock turns a service's pod into the stub server, fed from the spec stubs and the ConfigMaps
+// the mock references.
+func applyMock(spec *corev1.PodSpec, svc appsv1alpha1.ServiceSpec, name string) {
+	sources := []corev1.VolumeProjection{{
+		ConfigMap: &corev1.ConfigMapProjection{
+			LocalObjectReference: corev1.LocalObjectReference{Name: stubsConfigMapName(name)},
+			Items:                []corev1.KeyToPath{{Key: specStubsKey, Path: specStubsKey}},
+		},
+	}}
+	if svc.Mock != nil && svc.Mock.StubsFrom != nil {
+		ref := svc.Mock.StubsFrom
+		sources = append(sources, corev1.VolumeProjection{ConfigMap: &corev1.ConfigMapProjection{
+			LocalObjectReference: ref.LocalObjectReference,
+			Items:                []corev1.KeyToPath{{Key: ref.Key, Path: "stubs-from.yaml"}},
+			Optional:             ref.Optional,
+		}})
+	}
+	if svc.Mock != nil && svc.Mock.DescriptorsFrom != nil {
+		ref := svc.Mock.DescriptorsFrom
+		sources = append(sources, corev1.VolumeProjection{ConfigMap: &corev1.ConfigMapProjection{
+			LocalObjectReference: ref.LocalObjectReference,
+			Items:                []corev1.KeyToPath{{Key: ref.Key, Path: appsv1alpha1.MockDescriptorsFile}},
+			Optional:             ref.Optional,
+		}})
+	}
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name:         stubVolumeName,
+		VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: sources}},
+	})
+
+	c := &spec.Containers[0]
+	c.Image = stubImage()
+	c.Args = []string{fmt.Sprintf("--http-port=%d", svc.Port), "--config-dir=" + stubConfigDir}
+	if svc.GrpcPort != nil {
+		c.Args = append(c.Args, fmt.Sprintf("--grpc-port=%d", *svc.GrpcPort))
+	}
+	c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{Name: stubVolumeName, MountPath: stubConfigDir, ReadOnly: true})
+	if c.ReadinessProbe == nil {
+		c.ReadinessProbe = &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{
+				Path: appsv1alpha1.MockAdminPrefix + "health",
+				Port: intstr.FromInt32(svc.Port),
+			}},
+			PeriodSeconds: 5,
+		}
+	}
+}
@@ -0,0 +1,337 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// Rollout state is kept on the Deployments themselves, so a rollout survives controller restarts.
+const (
+	// templateHashAnnotation identifies the pod template a service Deployment runs, ignoring its track
+	templateHashAnnotation = "topas.io/template-hash"
+	// abortedAnnotation on the stable Deployment holds the template hash of an aborted rollout
+	abortedAnnotation = "topas.io/aborted-template"
+	// abortedVersionAnnotation on the stable Deployment holds the version of an aborted rollout
+	abortedVersionAnnotation = "topas.io/aborted-version"
+	// promotedAnnotation marks a blue/green preview Deployment the Service has been flipped to
+	promotedAnnotation = "topas.io/promoted"
+
+	// trackLabel tells the pods of the stable, canary and preview Deployments apart
+	trackLabel = "topas.io/track"
+
+	trackStable  = "stable"
+	trackCanary  = "canary"
+	trackPreview = "preview"
+)
+
+// rolloutAnnotations are dropped from a Deployment when the desired state no longer carries them.
+var rolloutAnnotations = []string{templateHashAnnotation, abortedAnnotation, abortedVersionAnnotation, promotedAnnotation}
+
+// defaultCanaryWeight is the share of replicas, in percent, given to a canary when the spec sets none.
+const defaultCanaryWeight = 20
+
+// rolloutName returns the name of the Deployment that runs a service's new version during a rollout.
+func rolloutName(name, strategy string) string {
+	if strategy == appsv1alpha1.RolloutStrategyBlueGreen {
+		return name + "-" + trackPreview
+	}
+	return name + "-" + trackCanary
+}
+
+// templateHash identifies a pod template independently of the track it runs on.
+func templateHash(template corev1.PodTemplateSpec) (string, error) {
+	t := template.DeepCopy()
+	delete(t.Labels, trackLabel)
+	return hashSpec(t)
+}
+
+// canaryReplicas splits total replicas between the canary and the stable Deployment by weight.
+// Each side keeps at least one pod, so small services run one more pod than asked for.
+func canaryReplicas(total int32, strategy *appsv1alpha1.RolloutStrategy) (canary, stable int32) {
+	if total == 0 {
+		return 0, 0
+	}
+	weight := int32(defaultCanaryWeight)
+	if strategy != nil && strategy.CanaryWeight != nil {
+		weight = *strategy.CanaryWeight
+	}
+	canary = max((total*weight+50)/100, 1)
+	stable = max(total-canary, 1)
+	return canary, stable
+}
+
+// reconcileRollout rolls a service with a Canary or BlueGreen strategy towards desired. The stable
+// Deployment keeps its pod template while a changed one runs in a canary or preview Deployment, until
+// a promote or abort request is found on the App.
+func (r *AppReconciler) reconcileRollout(ctx context.Context, app *appsv1alpha1.App, svc appsv1alpha1.ServiceSpec, desired *appsv1.Deployment) error {
+	log := logf.FromContext(ctx)
+	strategy := svc.RolloutStrategyType()
+	name := desired.Name
+	newName := rolloutName(name, strategy)
+	total := *desired.Spec.Replicas
+
+	hash, err := templateHash(desired.Spec.Template)
+	if err != nil {
+		return err
+	}
+	desired.Annotations[templateHashAnnotation] = hash
+
+	var stable appsv1.Deployment
+	err = r.Get(ctx, types.NamespacedName{Name: name, Namespace: app.Namespace}, &stable)
+	if errors.IsNotFound(err) {
+		// First deployment: nothing to roll from
+		return r.applyDeployment(ctx, app, desired)
+	}
+	if err != nil {
+		return err
+	}
+	var current *appsv1.Deployment
+	var newDep appsv1.Deployment
+	if err := r.Get(ctx, types.NamespacedName{Name: newName, Namespace: app.Namespace}, &newDep); err == nil {
+		current = &newDep
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+
+	request := app.Annotations[appsv1alpha1.RolloutRequestAnnotationPrefix+svc.Name]
+	stableHash := stable.Annotations[templateHashAnnotation]
+
+	switch {
+	case stableHash == "" || stableHash == hash:
+		// Nothing to roll out, or the rollout was promoted or reverted in the spec
+		if err := r.applyDeployment(ctx, app, desired); err != nil {
+			return err
+		}
+		// A promoted preview keeps serving until the Service has been flipped back to the stable pods
+		if current == nil || current.Annotations[promotedAnnotation] == "" || r.serviceTrack(ctx, app.Namespace, name) != trackPreview {
+			if err := r.deleteIfExists(ctx, &appsv1.Deployment{}, newName, app.Namespace); err != nil {
+				return err
+			}
+		}
+
+	case stable.Annotations[abortedAnnotation] == hash:
+		// Aborted: the stable version serves alone until the spec changes
+		if err := r.applyDeployment(ctx, app, stableDeployment(desired, &stable, total, true)); err != nil {
+			return err
+		}
+		if err := r.deleteIfExists(ctx, &appsv1.Deployment{}, newName, app.Namespace); err != nil {
+			return err
+		}
+
+	case request == appsv1alpha1.RolloutRequestAbort:
+		log.Info("Aborting rollout", "name", name, "version", svc.DesiredVersion())
+		aborted := stableDeployment(desired, &stable, total, false)
+		aborted.Annotations[abortedAnnotation] = hash
+		aborted.Annotations[abortedVersionAnnotation] = svc.DesiredVersion()
+		if err := r.applyDeployment(ctx, app, aborted); err != nil {
+			return err
+		}
+		if err := r.deleteIfExists(ctx, &appsv1.Deployment{}, newName, app.Namespace); err != nil {
+			return err
+		}
+		r.event(app, corev1.EventTypeNormal, "RolloutAborted", "Abort",
+			fmt.Sprintf("Aborted %s rollout of %s to %s", strategy, svc.Name, svc.DesiredVersion()))
+
+	case request == appsv1alpha1.RolloutRequestPromote:
+		log.Info("Promoting rollout", "name", name, "version", svc.DesiredVersion())
+		if strategy == appsv1alpha1.RolloutStrategyBlueGreen {
+			// Flip traffic to the preview first; the stable Deployment catches up behind it
+			preview := newDeployment(desired, newName, trackPreview, total)
+			preview.Annotations[promotedAnnotation] = "true"
+			if err := r.applyDeployment(ctx, app, preview); err != nil {
+				return err
+			}
+		} else if err := r.deleteIfExists(ctx, &appsv1.Deployment{}, newName, app.Namespace); err != nil {
+			return err
+		}
+		if err := r.applyDeployment(ctx, app, desired); err != nil {
+			return err
+		}
+		r.event(app, corev1.EventTypeNormal, "RolloutPromoted", "Promote",
+			fmt.Sprintf("Promoted %s rollout of %s to %s", strategy, svc.Name, svc.DesiredVersion()))
+
+	case strategy == appsv1alpha1.RolloutStrategyCanary:
+		canary, rest := canaryReplicas(total, svc.Strategy)
+		if err := r.applyDeployment(ctx, app, stableDeployment(desired, &stable, rest, false)); err != nil {
+			return err
+		}
+		if err := r.applyDeployment(ctx, app, newDeployment(desired, newName, trackCanary, canary)); err != nil {
+			return err
+		}
+
+	default:
+		if err := r.applyDeployment(ctx, app, stableDeployment(desired, &stable, total, false)); err != nil {
+			return err
+		}
+		if err := r.applyDeployment(ctx, app, newDeployment(desired, newName, trackPreview, total)); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := app.Annotations[appsv1alpha1.RolloutRequestAnnotationPrefix+svc.Name]; ok {
+		return r.clearRolloutRequest(ctx, app, svc.Name)
+	}
+	return nil
+}
+
+// stableDeployment is desired held at the pod template the stable Deployment already runs.
+// keepAborted carries over the record of an aborted rollout.
+func stableDeployment(desired, stable *appsv1.Deployment, replicas int32, keepAborted bool) *appsv1.Deployment {
+	d := desired.DeepCopy()
+	d.Labels = maps.Clone(stable.Spec.Template.Labels)
+	d.Annotations = map[string]string{
+		versionAnnotation:      stable.Annotations[versionAnnotation],
+		templateHashAnnotation: stable.Annotations[templateHashAnnotation],
+	}
+	if keepAborted {
+		d.Annotations[abortedAnnotation] = stable.Annotations[abortedAnnotation]
+		d.Annotations[abortedVersionAnnotation] = stable.Annotations[abortedVersionAnnotation]
+	}
+	d.Spec.Template = *stable.Spec.Template.DeepCopy()
+	d.Spec.Replicas = &replicas
+	return d
+}
+
+// newDeployment is desired renamed to run on its own track, with a selector that keeps its pods apart.
+func newDeployment(desired *appsv1.Deployment, name, track string, replicas int32) *appsv1.Deployment {
+	d := desired.DeepCopy()
+	d.Name = name
+	d.Labels = maps.Clone(d.Labels)
+	d.Labels[trackLabel] = track
+	d.Spec.Selector.MatchLabels = maps.Clone(d.Spec.Selector.MatchLabels)
+	d.Spec.Selector.MatchLabels[trackLabel] = track
+	d.Spec.Template.Labels = maps.Clone(d.Spec.Template.Labels)
+	d.Spec.Template.Labels[trackLabel] = track
+	d.Spec.Replicas = &replicas
+	return d
+}
+
+// serviceTrack returns the track the Service of a blue/green service currently selects.
+func (r *AppReconciler) serviceTrack(ctx context.Context, namespace, name string) string {
+	var svc corev1.Service
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &svc); err != nil {
+		return ""
+	}
+	return svc.Spec.Selector[trackLabel]
+}
+
+// blueGreenTrack returns the track a blue/green Service should select: a promoted preview until the
+// stable Deployment has rolled out the same pod template, the stable Deployment otherwise.
+func (r *AppReconciler) blueGreenTrack(ctx context.Context, namespace, name string) (string, error) {
+	var preview appsv1.Deployment
+	if err := r.Get(ctx, types.NamespacedName{Name: name + "-" + trackPreview, Namespace: namespace}, &preview); err != nil {
+		if errors.IsNotFound(err) {
+			return trackStable, nil
+		}
+		return "", err
+	}
+	if preview.Annotations[promotedAnnotation] == "" {
+		return trackStable, nil
+	}
+	var stable appsv1.Deployment
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &stable); err != nil {
+		return trackPreview, client.IgnoreNotFound(err)
+	}
+	if stable.Annotations[templateHashAnnotation] == preview.Annotations[templateHashAnnotation] && deploymentRolledOut(&stable) {
+		return trackStable, nil
+	}
+	return trackPreview, nil
+}
+
+// clearRolloutRequest removes a handled promote or abort request from the App.
+func (r *AppReconciler) clearRolloutRequest(ctx context.Context, app *appsv1alpha1.App, service string) error {
+	delete(app.Annotations, appsv1alpha1.RolloutRequestAnnotationPrefix+service)
+	// Update returns the stored status; keep what this reconcile has recorded so far
+	status := app.Status
+	if err := r.Update(ctx, app); err != nil {
+		return err
+	}
+	app.Status = status
+	return nil
+}
+
+// observeRollout adds the state of a canary or blue/green rollout to a service's status. A component
+// whose stable Deployment is ready is still Progressing while a rollout waits for promotion, and
+// Degraded when its new version fails or was aborted.
+func (r *AppReconciler) observeRollout(ctx context.Context, app *appsv1alpha1.App, svc appsv1alpha1.ServiceSpec, name string, status *appsv1alpha1.ComponentStatus) error {
+	strategy := svc.RolloutStrategyType()
+	if strategy == appsv1alpha1.RolloutStrategyRollingUpdate {
+		return nil
+	}
+	var stable appsv1.Deployment
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: app.Namespace}, &stable); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	rollout := &appsv1alpha1.RolloutStatus{
+		Strategy:      strategy,
+		StableVersion: stable.Spec.Template.Annotations[versionAnnotation],
+	}
+
+	if stable.Annotations[abortedAnnotation] != "" {
+		rollout.Phase = appsv1alpha1.RolloutPhaseAborted
+		rollout.NewVersion = stable.Annotations[abortedVersionAnnotation]
+		status.Rollout = rollout
+		if status.Phase != appsv1alpha1.ComponentPhaseDegraded {
+			status.Phase = appsv1alpha1.ComponentPhaseDegraded
+			status.Message = fmt.Sprintf("rollout to %s aborted, %s still serving", rollout.NewVersion, rollout.StableVersion)
+		}
+		return nil
+	}
+
+	newName := rolloutName(name, strategy)
+	var newDep appsv1.Deployment
+	if err := r.Get(ctx, types.NamespacedName{Name: newName, Namespace: app.Namespace}, &newDep); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	rollout.Phase = appsv1alpha1.RolloutPhaseAwaitingPromotion
+	if newDep.Annotations[promotedAnnotation] != "" {
+		rollout.Phase = appsv1alpha1.RolloutPhasePromoting
+	}
+	rollout.NewVersion = newDep.Spec.Template.Annotations[versionAnnotation]
+	if newDep.Spec.Replicas != nil {
+		rollout.NewReplicas = *newDep.Spec.Replicas
+	}
+	rollout.NewReadyReplicas = newDep.Status.ReadyReplicas
+	status.Rollout = rollout
+
+	newStatus, err := r.observeDeployment(ctx, app.Namespace, newName)
+	if err != nil {
+		return err
+	}
+	switch {
+	case newStatus.Phase == appsv1alpha1.ComponentPhaseDegraded:
+		status.Phase = appsv1alpha1.ComponentPhaseDegraded
+		status.Message = newName + ": " + newStatus.Message
+	case status.Phase == appsv1alpha1.ComponentPhaseReady:
+		status.Phase = appsv1alpha1.ComponentPhaseProgressing
+		status.Message = fmt.Sprintf("%s %s at %d/%d ready replicas, awaiting promotion",
+			newName, rollout.NewVersion, rollout.NewReadyReplicas, rollout.NewReplicas)
+	}
+	return nil
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path"
+	"sort"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -13,6 +16,8 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
@@ -22,12 +27,20 @@ import (
 type TestRunReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// MaxConcurrentRuns bounds the TestRuns running at once in the cluster; 0 leaves them unbounded
+	MaxConcurrentRuns int
 }
 
 // +kubebuilder:rbac:groups=apps.example.com,resources=testruns,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.example.com,resources=testruns/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps.example.com,resources=apps,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=apps.example.com,resources=testruns/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;delete
 
 func (r *TestRunReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
@@ -37,27 +50,113 @@ func (r *TestRunReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	// 0. Tear down the App clone, kept or not, before the TestRun goes away
+	if !testRun.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(&testRun, environmentFinalizer) {
+			return ctrl.Result{}, nil
+		}
+		if err := r.teardownEnvironment(ctx, &testRun); err != nil {
+			return ctrl.Result{}, err
+		}
+		controllerutil.RemoveFinalizer(&testRun, environmentFinalizer)
+		return ctrl.Result{}, r.Update(ctx, &testRun)
+	}
+
 	// 1. Handle Pending State
 	if testRun.Status.State == "" || testRun.Status.State == "Pending" {
 		log.Info("Reconciling Pending TestRun", "name", testRun.Name)
 
-		// Check Concurrency
-		var activePods corev1.PodList
-		if err := r.List(ctx, &activePods, client.MatchingLabels{"runner-type": "topas"}); err != nil {
+		// Reject an unparseable timeout rather than running without a deadline
+		// (the validating webhook catches this at admission when enabled)
+		if testRun.Spec.Timeout != "" {
+			if _, err := time.ParseDuration(testRun.Spec.Timeout); err != nil {
+				finishRun(&testRun, "Error", "InvalidTimeout", fmt.Sprintf("invalid timeout %q: %v", testRun.Spec.Timeout, err))
+				return ctrl.Result{}, r.Status().Update(ctx, &testRun)
+			}
+		}
+
+		// Wait out the backoff before retrying a failed attempt
+		if next := testRun.Status.NextAttemptTime; next != nil {
+			if wait := time.Until(next.Time); wait > 0 {
+				return ctrl.Result{RequeueAfter: wait}, nil
+			}
+		}
+
+		// Wait in the queue for a slot under the global, namespace and App limits. Runs that finish
+		// or start requeue the waiting ones; the periodic requeue only refreshes the position.
+		if !holdsSlot(&testRun) {
+			position, reason, err := r.admit(ctx, &testRun)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if position > 0 {
+				result := fmt.Sprintf("queued at position %d: %s", position, reason)
+				if testRun.Status.State != "Pending" || testRun.Status.QueuePosition != position || testRun.Status.Result != result {
+					log.Info("TestRun queued", "position", position, "reason", reason)
+					testRun.Status.State = "Pending"
+					testRun.Status.QueuePosition = position
+					testRun.Status.Result = result
+					if err := r.Status().Update(ctx, &testRun); err != nil {
+						return ctrl.Result{}, err
+					}
+				}
+				return ctrl.Result{RequeueAfter: time.Minute}, nil
+			}
+		}
+
+		// Take the App's lease for an Exclusive run, or wait while an Exclusive run keeps the App
+		wait, err := r.acquireAppAccess(ctx, &testRun)
+		if err != nil {
 			return ctrl.Result{}, err
 		}
+		if wait != "" {
+			if testRun.Status.State != "Pending" || testRun.Status.Result != wait || testRun.Status.QueuePosition != 0 {
+				log.Info("TestRun waiting for App access", "reason", wait)
+				testRun.Status.State = "Pending"
+				testRun.Status.QueuePosition = 0
+				testRun.Status.Result = wait
+				if err := r.Status().Update(ctx, &testRun); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
 
-		runningCount := 0
-		for _, p := range activePods.Items {
-			if p.Status.Phase == corev1.PodRunning || p.Status.Phase == corev1.PodPending {
-				runningCount++
+		// Clone the App and hold the runner until the clone is healthy
+		if testRun.Spec.Environment != nil {
+			if controllerutil.AddFinalizer(&testRun, environmentFinalizer) {
+				if err := r.Update(ctx, &testRun); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+			ready, failure, err := r.ensureEnvironment(ctx, &testRun)
+			if err != nil {
+				log.Error(err, "Failed to provision environment")
+				return ctrl.Result{}, err
+			}
+			if failure != "" {
+				finishRun(&testRun, "Error", "EnvironmentFailed", failure)
+				return r.endAttempt(ctx, &testRun, nil)
+			}
+			if !ready {
+				testRun.Status.State = "Pending"
+				testRun.Status.QueuePosition = 0
+				testRun.Status.Result = fmt.Sprintf("waiting for environment %s/%s", testRun.Status.Environment.Namespace, testRun.Status.Environment.AppName)
+				if err := r.Status().Update(ctx, &testRun); err != nil {
+					return ctrl.Result{}, err
+				}
+				return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 			}
 		}
 
-		const MaxConcurrency = 5
-		if runningCount >= MaxConcurrency {
-			log.Info("Concurrency limit reached", "active", runningCount, "limit", MaxConcurrency)
-			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		// Fail rather than leave the runner pod waiting on a Secret it cannot mount
+		missing, err := r.missingSecret(ctx, &testRun)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if missing != "" {
+			finishRun(&testRun, "Error", "SecretNotFound", missing)
+			return r.endAttempt(ctx, &testRun, nil)
 		}
 
 		// Create ConfigMap for inline script
@@ -72,6 +171,16 @@ func (r *TestRunReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			}
 		}
 
+		// Create the ConfigMap the runner stores its reports in
+		report := r.defineReportConfigMap(&testRun)
+		if err := ctrl.SetControllerReference(&testRun, report, r.Scheme); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.Create(ctx, report); err != nil && !errors.IsAlreadyExists(err) {
+			log.Error(err, "Failed to create report ConfigMap")
+			return ctrl.Result{}, err
+		}
+
 		// Create Runner Pod
 		pod := r.defineRunnerPod(&testRun)
 		if err := ctrl.SetControllerReference(&testRun, pod, r.Scheme); err != nil {
@@ -85,9 +194,15 @@ func (r *TestRunReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 
 		// Update Status to Running
 		testRun.Status.State = "Running"
+		testRun.Status.QueuePosition = 0
+		testRun.Status.Result = ""
 		testRun.Status.RunnerPod = pod.Name
-		now := metav1.Now()
-		testRun.Status.StartTime = &now
+		testRun.Status.ReportConfigMap = report.Name
+		testRun.Status.NextAttemptTime = nil
+		if testRun.Status.StartTime == nil {
+			now := metav1.Now()
+			testRun.Status.StartTime = &now
+		}
 		if err := r.Status().Update(ctx, &testRun); err != nil {
 			return ctrl.Result{}, err
 		}
@@ -99,37 +214,63 @@ func (r *TestRunReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		var pod corev1.Pod
 		podName := types.NamespacedName{Name: testRun.Status.RunnerPod, Namespace: testRun.Namespace}
 		if err := r.Get(ctx, podName, &pod); err != nil {
-			if errors.IsNotFound(err) {
-				testRun.Status.State = "Error"
-				testRun.Status.Result = "Runner Pod not found"
-				r.Status().Update(ctx, &testRun)
+			if !errors.IsNotFound(err) {
+				return ctrl.Result{}, err
 			}
-			return ctrl.Result{}, err
+			finishRun(&testRun, "Error", "RunnerPodMissing", "Runner Pod not found")
+			return r.endAttempt(ctx, &testRun, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: podName.Name}})
 		}
 
 		// Check Pod Status
-		if pod.Status.Phase == corev1.PodSucceeded {
-			testRun.Status.State = "Passed"
-			testRun.Status.Result = "Success"
-			now := metav1.Now()
-			testRun.Status.CompletionTime = &now
-			r.Status().Update(ctx, &testRun)
-		} else if pod.Status.Phase == corev1.PodFailed {
-			testRun.Status.State = "Failed"
-			testRun.Status.Result = "Runner Pod Failed"
-			now := metav1.Now()
-			testRun.Status.CompletionTime = &now
-			r.Status().Update(ctx, &testRun)
-		} else {
-			// Pod still running — requeue to check again
-			log.Info("Runner pod still running, will recheck", "pod", pod.Name)
-			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			finishFromRunner(&testRun, &pod)
+			return r.endAttempt(ctx, &testRun, &pod)
+		}
+
+		// Pod still running — keep the App lease and requeue to check again
+		if err := r.renewAppLease(ctx, &testRun); err != nil {
+			return ctrl.Result{}, err
 		}
+		log.Info("Runner pod still running, will recheck", "pod", pod.Name)
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// endAttempt records the attempt finishRun just ended and either schedules a retry or finishes the run,
+// releasing what it held.
+func (r *TestRunReconciler) endAttempt(ctx context.Context, run *appv1alpha1.TestRun, pod *corev1.Pod) (ctrl.Result, error) {
+	recordAttempt(run, pod)
+
+	// Run a failed attempt again in a fresh pod while retries are left, on the same environment
+	// with the faults it injected reset; an environment that failed is replaced by a new one
+	if delay, retry := retryRun(run); retry {
+		logf.FromContext(ctx).Info("Retrying failed TestRun", "attempt", len(run.Status.Attempts)+1, "after", delay)
+		if err := r.resetFaults(ctx, run); err != nil {
+			return ctrl.Result{}, err
+		}
+		if run.Status.Attempts[len(run.Status.Attempts)-1].Reason == "EnvironmentFailed" {
+			if err := r.teardownEnvironment(ctx, run); err != nil {
+				return ctrl.Result{}, err
+			}
+			run.Status.Environment = nil
+		}
+		if err := r.Status().Update(ctx, run); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+	markFlaky(run)
+	if err := r.releaseAppLease(ctx, run); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.finishEnvironment(ctx, run); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, r.Status().Update(ctx, run)
+}
+
 // defineScriptConfigMap creates a ConfigMap containing the inline Lua script.
 func (r *TestRunReconciler) defineScriptConfigMap(run *appv1alpha1.TestRun) *corev1.ConfigMap {
 	return &corev1.ConfigMap{
@@ -144,8 +285,27 @@ func (r *TestRunReconciler) defineScriptConfigMap(run *appv1alpha1.TestRun) *cor
 	}
 }
 
+// reportConfigMapName names the ConfigMap holding a run's reports.
+func reportConfigMapName(run *appv1alpha1.TestRun) string {
+	return run.Name + "-report"
+}
+
+// defineReportConfigMap creates the empty ConfigMap the runner fills with its JSON and JUnit reports.
+func (r *TestRunReconciler) defineReportConfigMap(run *appv1alpha1.TestRun) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      reportConfigMapName(run),
+			Namespace: run.Namespace,
+			Labels:    map[string]string{"testrun": run.Name, "runner-type": "topas"},
+		},
+	}
+}
+
 func (r *TestRunReconciler) defineRunnerPod(run *appv1alpha1.TestRun) *corev1.Pod {
 	scriptPath := "/scripts/test.lua"
+	if run.Spec.Script == "" && run.Spec.Git != nil {
+		scriptPath = path.Join("/scripts", run.Spec.Git.Path)
+	}
 
 	// Parse timeout → activeDeadlineSeconds
 	var activeDeadline *int64
@@ -157,10 +317,13 @@ func (r *TestRunReconciler) defineRunnerPod(run *appv1alpha1.TestRun) *corev1.Po
 	}
 
 	// Determine app name and namespace for runner args
-	appName := run.Spec.AppName
+	appName, appNamespace := run.Spec.AppName, run.Namespace
 	if appName == "" {
 		appName = "unknown"
 	}
+	if env := run.Status.Environment; env != nil {
+		appName, appNamespace = env.AppName, env.Namespace
+	}
 
 	// Base Pod
 	runnerImage := os.Getenv("RUNNER_IMAGE")
@@ -170,7 +333,7 @@ func (r *TestRunReconciler) defineRunnerPod(run *appv1alpha1.TestRun) *corev1.Po
 
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      run.Name + "-runner",
+			Name:      runnerPodName(run),
 			Namespace: run.Namespace,
 			Labels:    map[string]string{"testrun": run.Name, "runner-type": "topas"},
 		},
@@ -178,14 +341,19 @@ func (r *TestRunReconciler) defineRunnerPod(run *appv1alpha1.TestRun) *corev1.Po
 			RestartPolicy:         corev1.RestartPolicyNever,
 			ActiveDeadlineSeconds: activeDeadline,
 			Containers: []corev1.Container{{
-				Name:            "runner",
+				Name:            runnerContainerName,
 				Image:           runnerImage,
 				ImagePullPolicy: corev1.PullNever,
 				Args: []string{
 					"--script", scriptPath,
 					"--app", appName,
-					"--namespace", run.Namespace,
+					"--namespace", appNamespace,
+					"--results-file", corev1.TerminationMessagePathDefault,
+					"--report-configmap", run.Namespace + "/" + reportConfigMapName(run),
 				},
+				// The runner writes its results as the termination message; a runner that dies before
+				// writing them leaves the tail of its log there instead
+				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 				VolumeMounts: []corev1.VolumeMount{{
 					Name:      "scripts",
 					MountPath: "/scripts",
@@ -194,6 +362,21 @@ func (r *TestRunReconciler) defineRunnerPod(run *appv1alpha1.TestRun) *corev1.Po
 		},
 	}
 
+	// The runner refuses to change the App unless the run holds it exclusively
+	access := run.Spec.AppAccess
+	if access == "" {
+		access = appv1alpha1.AppAccessShared
+	}
+	pod.Spec.Containers[0].Args = append(pod.Spec.Containers[0].Args, "--app-access", access)
+	if access == appv1alpha1.AppAccessExclusive && needsAppLease(run) {
+		pod.Spec.Containers[0].Args = append(pod.Spec.Containers[0].Args,
+			"--app-lease", run.Namespace+"/"+appLeaseName(run.Spec.AppName), "--testrun", run.Name)
+	}
+
+	if len(run.Spec.Tags) > 0 {
+		pod.Spec.Containers[0].Args = append(pod.Spec.Containers[0].Args, "--tags", strings.Join(run.Spec.Tags, ","))
+	}
+
 	// Mount script source
 	if run.Spec.Script != "" {
 		// Use ConfigMap volume for inline scripts (robust, handles all characters)
@@ -240,12 +423,75 @@ func (r *TestRunReconciler) defineRunnerPod(run *appv1alpha1.TestRun) *corev1.Po
 		}}
 	}
 
+	addParamsAndSecrets(pod, run)
+
 	return pod
 }
 
+// secretsDir is where the runner finds the Secrets a script may read, one directory per Secret.
+const secretsDir = "/secrets"
+
+// addParamsAndSecrets hands the run's params to the runner and mounts the Secrets it references.
+func addParamsAndSecrets(pod *corev1.Pod, run *appv1alpha1.TestRun) {
+	runner := &pod.Spec.Containers[0]
+
+	// Sorted, so the pod is the same on every reconcile
+	keys := make([]string, 0, len(run.Spec.Params))
+	for k := range run.Spec.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		runner.Args = append(runner.Args, "--param", k+"="+run.Spec.Params[k])
+	}
+
+	if len(run.Spec.SecretRefs) == 0 {
+		return
+	}
+	runner.Args = append(runner.Args, "--secrets-dir", secretsDir)
+	for i, ref := range run.Spec.SecretRefs {
+		name := fmt.Sprintf("secret-%d", i)
+		source := &corev1.SecretVolumeSource{SecretName: ref.Name}
+		for _, key := range ref.Keys {
+			source.Items = append(source.Items, corev1.KeyToPath{Key: key, Path: key})
+		}
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name:         name,
+			VolumeSource: corev1.VolumeSource{Secret: source},
+		})
+		runner.VolumeMounts = append(runner.VolumeMounts, corev1.VolumeMount{
+			Name:      name,
+			MountPath: secretsDir + "/" + ref.Name,
+			ReadOnly:  true,
+		})
+	}
+}
+
+// missingSecret describes the first Secret, or key of one, referenced by the run that does not exist, or
+// returns "" when they all do.
+func (r *TestRunReconciler) missingSecret(ctx context.Context, run *appv1alpha1.TestRun) (string, error) {
+	for _, ref := range run.Spec.SecretRefs {
+		var secret corev1.Secret
+		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: run.Namespace}, &secret); err != nil {
+			if errors.IsNotFound(err) {
+				return fmt.Sprintf("Secret %q referenced by spec.secretRefs not found", ref.Name), nil
+			}
+			return "", err
+		}
+		for _, key := range ref.Keys {
+			if _, ok := secret.Data[key]; !ok {
+				return fmt.Sprintf("Secret %q has no key %q referenced by spec.secretRefs", ref.Name, key), nil
+			}
+		}
+	}
+	return "", nil
+}
+
 func (r *TestRunReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&appv1alpha1.TestRun{}).
 		Owns(&corev1.Pod{}).
+		Owns(&appv1alpha1.App{}).
+		Watches(&appv1alpha1.TestRun{}, handler.EnqueueRequestsFromMapFunc(r.queuedRuns)).
 		Complete(r)
 }
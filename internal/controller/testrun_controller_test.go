@@ -0,0 +1,586 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+var _ = Describe("TestRun Controller", func() {
+	Context("When the runner pod finishes", func() {
+		const resourceName = "test-run"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind TestRun")
+			err := k8sClient.Get(ctx, typeNamespacedName, &appv1alpha1.TestRun{})
+			if err != nil && errors.IsNotFound(err) {
+				resource := &appv1alpha1.TestRun{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: "default",
+					},
+					Spec: appv1alpha1.TestRunSpec{
+						AppName: "test-resource",
+						Script:  "assert(false, 'boom')",
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &appv1alpha1.TestRun{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+
+			By("Cleanup the specific resource instance TestRun")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should copy the runner's results into status", func() {
+			controllerReconciler := &TestRunReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("failing the runner pod with results in its termination message")
+			pod := &corev1.Pod{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-runner", Namespace: "default"}, pod)).To(Succeed())
+			Expect(pod.Spec.Containers[0].TerminationMessagePolicy).To(Equal(corev1.TerminationMessageFallbackToLogsOnError))
+			Expect(pod.Spec.Containers[0].Args).To(ContainElements("--report-configmap", "default/"+resourceName+"-report"))
+
+			By("creating the ConfigMap the runner stores its reports in")
+			report := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-report", Namespace: "default"}, report)).To(Succeed())
+			Expect(report.OwnerReferences).To(HaveLen(1))
+			Expect(report.OwnerReferences[0].Name).To(Equal(resourceName))
+
+			pod.Status.Phase = corev1.PodFailed
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				Name:  runnerContainerName,
+				Image: pod.Spec.Containers[0].Image,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 1,
+					Message: `{"summary":{"total":1,"passed":0,"failed":1,"assertions":1,"duration":"5ms"},` +
+						`"testCases":[{"name":"test.lua","state":"Failed","assertions":1,"duration":"5ms",` +
+						`"failure":{"message":"boom","location":"/scripts/test.lua:1"}}]}`,
+				}},
+			}}
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			run := &appv1alpha1.TestRun{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, run)).To(Succeed())
+			Expect(run.Status.State).To(Equal("Failed"))
+			Expect(run.Status.ReportConfigMap).To(Equal(resourceName + "-report"))
+			Expect(run.Status.Result).To(ContainSubstring("boom (/scripts/test.lua:1)"))
+			Expect(run.Status.Results).NotTo(BeNil())
+			Expect(run.Status.Results.TestCases).To(HaveLen(1))
+			Expect(run.Status.Results.TestCases[0].Failure.Location).To(Equal("/scripts/test.lua:1"))
+			succeeded := meta.FindStatusCondition(run.Status.Conditions, appv1alpha1.TestRunConditionSucceeded)
+			Expect(succeeded).NotTo(BeNil())
+			Expect(succeeded.Reason).To(Equal("TestsFailed"))
+			Expect(meta.IsStatusConditionTrue(run.Status.Conditions, appv1alpha1.TestRunConditionResultsReported)).To(BeTrue())
+		})
+	})
+
+	Context("When a failed run has retries left", func() {
+		const resourceName = "test-run-retry"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating a TestRun retried without delay")
+			err := k8sClient.Get(ctx, typeNamespacedName, &appv1alpha1.TestRun{})
+			if err != nil && errors.IsNotFound(err) {
+				resource := &appv1alpha1.TestRun{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: "default",
+					},
+					Spec: appv1alpha1.TestRunSpec{
+						AppName: "test-resource",
+						Script:  "assert(math.random() > 0.5)",
+						Retries: 1,
+						Backoff: &appv1alpha1.RetryBackoff{Policy: appv1alpha1.BackoffFixed, Delay: "0s"},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &appv1alpha1.TestRun{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+
+			By("Cleanup the specific resource instance TestRun")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should retry in a fresh pod and end Flaky when the retry passes", func() {
+			controllerReconciler := &TestRunReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			reconcileRun := func() {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: typeNamespacedName,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			finishPod := func(name string, phase corev1.PodPhase, message string) {
+				pod := &corev1.Pod{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, pod)).To(Succeed())
+				pod.Status.Phase = phase
+				pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+					Name:  runnerContainerName,
+					Image: pod.Spec.Containers[0].Image,
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: message}},
+				}}
+				Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+			}
+			reconcileRun()
+
+			By("failing the first attempt")
+			finishPod(resourceName+"-runner", corev1.PodFailed,
+				`{"summary":{"total":1,"passed":0,"failed":1,"assertions":1,"duration":"5ms"},`+
+					`"testCases":[{"name":"random","state":"Failed","assertions":1,"duration":"5ms","failure":{"message":"unlucky"}}]}`)
+			reconcileRun()
+
+			run := &appv1alpha1.TestRun{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, run)).To(Succeed())
+			Expect(run.Status.State).To(Equal("Pending"))
+			Expect(run.Status.Attempts).To(HaveLen(1))
+			Expect(run.Status.Attempts[0].FailedTestCases).To(Equal([]string{"random"}))
+			Expect(meta.FindStatusCondition(run.Status.Conditions, appv1alpha1.TestRunConditionSucceeded).Reason).To(Equal("Retrying"))
+
+			By("passing the retry")
+			reconcileRun()
+			Expect(k8sClient.Get(ctx, typeNamespacedName, run)).To(Succeed())
+			Expect(run.Status.RunnerPod).To(Equal(resourceName + "-runner-2"))
+			finishPod(resourceName+"-runner-2", corev1.PodSucceeded,
+				`{"summary":{"total":1,"passed":1,"failed":0,"assertions":1,"duration":"5ms"}}`)
+			reconcileRun()
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, run)).To(Succeed())
+			Expect(run.Status.State).To(Equal("Flaky"))
+			Expect(run.Status.Result).To(HavePrefix("passed on attempt 2 of 2"))
+			Expect(run.Status.Attempts).To(HaveLen(2))
+			Expect(run.Status.Attempts[1].State).To(Equal("Passed"))
+			Expect(meta.IsStatusConditionTrue(run.Status.Conditions, appv1alpha1.TestRunConditionSucceeded)).To(BeTrue())
+		})
+	})
+
+	Context("When the runner pod of a run with retries disappears", func() {
+		const resourceName = "test-run-missing-pod"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating a TestRun allowed one retry")
+			resource := &appv1alpha1.TestRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: appv1alpha1.TestRunSpec{
+					AppName: "test-resource",
+					Script:  "assert(true)",
+					Retries: 1,
+					Backoff: &appv1alpha1.RetryBackoff{Policy: appv1alpha1.BackoffFixed, Delay: "0s"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			By("Cleanup the specific resource instance TestRun")
+			resource := &appv1alpha1.TestRun{ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"}}
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should retry it in a fresh pod", func() {
+			controllerReconciler := &TestRunReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			reconcileRun := func() *appv1alpha1.TestRun {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				run := &appv1alpha1.TestRun{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, run)).To(Succeed())
+				return run
+			}
+			Expect(reconcileRun().Status.State).To(Equal("Running"))
+
+			By("deleting the runner pod")
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-runner", Namespace: "default"}}
+			Expect(k8sClient.Delete(ctx, pod, client.GracePeriodSeconds(0))).To(Succeed())
+
+			run := reconcileRun()
+			Expect(run.Status.State).To(Equal("Pending"))
+			Expect(run.Status.Attempts).To(HaveLen(1))
+			Expect(run.Status.Attempts[0].State).To(Equal("Error"))
+			Expect(run.Status.Attempts[0].Reason).To(Equal("RunnerPodMissing"))
+			Expect(run.Status.Attempts[0].RunnerPod).To(Equal(resourceName + "-runner"))
+
+			run = reconcileRun()
+			Expect(run.Status.State).To(Equal("Running"))
+			Expect(run.Status.RunnerPod).To(Equal(resourceName + "-runner-2"))
+		})
+	})
+
+	Context("When the environment of a run with retries fails", func() {
+		const resourceName = "test-run-env-failed"
+		const appName = "test-run-env-source"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating the App to clone and a TestRun allowed one retry")
+			app := &appv1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{Name: appName, Namespace: "default"},
+				Spec: appv1alpha1.AppSpec{
+					Services: []appv1alpha1.ServiceSpec{{Name: "api", Image: "nginx", Version: "1.14.2", Port: 80}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, app))).To(Succeed())
+			})
+
+			resource := &appv1alpha1.TestRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: appv1alpha1.TestRunSpec{
+					AppName:     appName,
+					Script:      "assert(true)",
+					Retries:     1,
+					Backoff:     &appv1alpha1.RetryBackoff{Policy: appv1alpha1.BackoffFixed, Delay: "0s"},
+					Environment: &appv1alpha1.EnvironmentSpec{Isolation: appv1alpha1.IsolationSuffix},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			By("Cleanup the specific resource instance TestRun")
+			resource := &appv1alpha1.TestRun{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			controllerReconciler := &TestRunReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should retry it on a new environment", func() {
+			controllerReconciler := &TestRunReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			reconcileRun := func() *appv1alpha1.TestRun {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				run := &appv1alpha1.TestRun{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, run)).To(Succeed())
+				return run
+			}
+			run := reconcileRun()
+			Expect(run.Status.State).To(Equal("Pending"))
+			Expect(run.Status.Environment).NotTo(BeNil())
+			first := types.NamespacedName{Name: run.Status.Environment.AppName, Namespace: run.Status.Environment.Namespace}
+
+			By("marking the clone unhealthy")
+			clone := &appv1alpha1.App{}
+			Expect(k8sClient.Get(ctx, first, clone)).To(Succeed())
+			clone.Status.Health = "Unhealthy"
+			Expect(k8sClient.Status().Update(ctx, clone)).To(Succeed())
+
+			run = reconcileRun()
+			Expect(run.Status.State).To(Equal("Pending"))
+			Expect(run.Status.Attempts).To(HaveLen(1))
+			Expect(run.Status.Attempts[0].Reason).To(Equal("EnvironmentFailed"))
+			Expect(run.Status.Environment).To(BeNil())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, first, &appv1alpha1.App{}))).To(BeTrue())
+
+			By("provisioning a clone of its own for the retry")
+			run = reconcileRun()
+			Expect(run.Status.State).To(Equal("Pending"))
+			Expect(run.Status.Environment).NotTo(BeNil())
+			Expect(run.Status.Environment.AppName).To(Equal(first.Name + "-2"))
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: first.Name + "-2", Namespace: "default"}, &appv1alpha1.App{})).To(Succeed())
+		})
+	})
+
+	Context("When the namespace limit on running TestRuns is reached", func() {
+		const namespace = "test-run-queue"
+
+		ctx := context.Background()
+
+		BeforeEach(func() {
+			By("creating a namespace that runs one TestRun at a time")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: namespace}, &corev1.Namespace{})
+			if err != nil && errors.IsNotFound(err) {
+				ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+					Name:        namespace,
+					Annotations: map[string]string{appv1alpha1.MaxConcurrentTestRunsAnnotation: "1"},
+				}}
+				Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+			}
+			for _, name := range []string{"first", "second"} {
+				run := &appv1alpha1.TestRun{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+					Spec:       appv1alpha1.TestRunSpec{AppName: "test-resource", Script: "assert(true)"},
+				}
+				Expect(k8sClient.Create(ctx, run)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup the TestRuns")
+			Expect(k8sClient.DeleteAllOf(ctx, &appv1alpha1.TestRun{}, client.InNamespace(namespace))).To(Succeed())
+		})
+
+		It("should queue the second run until the first one finishes", func() {
+			controllerReconciler := &TestRunReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			reconcileRun := func(name string) *appv1alpha1.TestRun {
+				key := types.NamespacedName{Name: name, Namespace: namespace}
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+				Expect(err).NotTo(HaveOccurred())
+				run := &appv1alpha1.TestRun{}
+				Expect(k8sClient.Get(ctx, key, run)).To(Succeed())
+				return run
+			}
+
+			first := reconcileRun("first")
+			Expect(first.Status.State).To(Equal("Running"))
+
+			second := reconcileRun("second")
+			Expect(second.Status.State).To(Equal("Pending"))
+			Expect(second.Status.QueuePosition).To(Equal(int32(1)))
+			Expect(second.Status.Result).To(ContainSubstring("1 of 1 TestRuns running in namespace " + namespace))
+
+			By("finishing the first run")
+			first.Status.State = "Passed"
+			Expect(k8sClient.Status().Update(ctx, first)).To(Succeed())
+			Expect(controllerReconciler.queuedRuns(ctx, first)).To(ContainElement(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "second", Namespace: namespace}}))
+
+			second = reconcileRun("second")
+			Expect(second.Status.State).To(Equal("Running"))
+			Expect(second.Status.QueuePosition).To(BeZero())
+		})
+	})
+
+	Context("When an Exclusive run holds its App", func() {
+		const appName = "test-locked-app"
+
+		ctx := context.Background()
+
+		BeforeEach(func() {
+			By("creating an Exclusive and a Shared run against the same App")
+			for _, access := range []string{appv1alpha1.AppAccessExclusive, appv1alpha1.AppAccessShared} {
+				run := &appv1alpha1.TestRun{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-run-" + strings.ToLower(access), Namespace: "default"},
+					Spec:       appv1alpha1.TestRunSpec{AppName: appName, Script: "assert(true)", AppAccess: access},
+				}
+				Expect(k8sClient.Create(ctx, run)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup the TestRuns")
+			for _, name := range []string{"test-run-exclusive", "test-run-shared"} {
+				run := &appv1alpha1.TestRun{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+				Expect(k8sClient.Delete(ctx, run)).To(Succeed())
+			}
+		})
+
+		It("should hold other runs until it releases the App lease", func() {
+			controllerReconciler := &TestRunReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			reconcileRun := func(name string) *appv1alpha1.TestRun {
+				key := types.NamespacedName{Name: name, Namespace: "default"}
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+				Expect(err).NotTo(HaveOccurred())
+				run := &appv1alpha1.TestRun{}
+				Expect(k8sClient.Get(ctx, key, run)).To(Succeed())
+				return run
+			}
+			leaseKey := types.NamespacedName{Name: appLeaseName(appName), Namespace: "default"}
+
+			exclusive := reconcileRun("test-run-exclusive")
+			Expect(exclusive.Status.State).To(Equal("Running"))
+			lease := &coordinationv1.Lease{}
+			Expect(k8sClient.Get(ctx, leaseKey, lease)).To(Succeed())
+			Expect(*lease.Spec.HolderIdentity).To(Equal("test-run-exclusive"))
+
+			pod := &corev1.Pod{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "test-run-exclusive-runner", Namespace: "default"}, pod)).To(Succeed())
+			Expect(pod.Spec.Containers[0].Args).To(ContainElements("--app-access", "Exclusive", "--app-lease", "default/"+leaseKey.Name))
+
+			shared := reconcileRun("test-run-shared")
+			Expect(shared.Status.State).To(Equal("Pending"))
+			Expect(shared.Status.Result).To(ContainSubstring("held by TestRun test-run-exclusive"))
+
+			By("finishing the Exclusive run")
+			pod.Status.Phase = corev1.PodSucceeded
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+			Expect(reconcileRun("test-run-exclusive").Status.State).To(Equal("Passed"))
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, leaseKey, &coordinationv1.Lease{}))).To(BeTrue())
+
+			Expect(reconcileRun("test-run-shared").Status.State).To(Equal("Running"))
+		})
+	})
+
+	Context("When a run has params and secrets", func() {
+		const resourceName = "test-run-params"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating a TestRun with params and a Secret reference")
+			resource := &appv1alpha1.TestRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: appv1alpha1.TestRunSpec{
+					AppName:    "test-resource",
+					Script:     "assert(params.base_url)",
+					Params:     map[string]string{"users": "10", "base_url": "http://orders:8080"},
+					SecretRefs: []appv1alpha1.SecretRef{{Name: "db-credentials", Keys: []string{"password"}}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			By("Cleanup the specific resource instance TestRun")
+			resource := &appv1alpha1.TestRun{ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"}}
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should fail the run when a referenced Secret is missing", func() {
+			controllerReconciler := &TestRunReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			run := &appv1alpha1.TestRun{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, run)).To(Succeed())
+			Expect(run.Status.State).To(Equal("Error"))
+			Expect(meta.FindStatusCondition(run.Status.Conditions, appv1alpha1.TestRunConditionSucceeded).Reason).To(Equal("SecretNotFound"))
+			Expect(run.Status.RunnerPod).To(BeEmpty())
+		})
+
+		It("should pass the params and mount the Secret in the runner pod", func() {
+			By("creating the referenced Secret")
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: "default"},
+				StringData: map[string]string{"password": "s3cr3t"},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+			DeferCleanup(func() { Expect(k8sClient.Delete(ctx, secret)).To(Succeed()) })
+
+			controllerReconciler := &TestRunReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			pod := &corev1.Pod{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-runner", Namespace: "default"}, pod)).To(Succeed())
+			runner := pod.Spec.Containers[0]
+			Expect(strings.Join(runner.Args, " ")).To(ContainSubstring(
+				"--param base_url=http://orders:8080 --param users=10 --secrets-dir /secrets"))
+			Expect(runner.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name: "secret-0", MountPath: "/secrets/db-credentials", ReadOnly: true,
+			}))
+			var volume *corev1.SecretVolumeSource
+			for _, v := range pod.Spec.Volumes {
+				if v.Name == "secret-0" {
+					volume = v.Secret
+				}
+			}
+			Expect(volume).NotTo(BeNil())
+			Expect(volume.SecretName).To(Equal("db-credentials"))
+			Expect(volume.Items).To(Equal([]corev1.KeyToPath{{Key: "password", Path: "password"}}))
+		})
+	})
+})
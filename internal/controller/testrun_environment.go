@@ -0,0 +1,289 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+const (
+	// environmentFinalizer removes a TestRun's App clone, kept or not, when the TestRun is deleted
+	environmentFinalizer = "topas.io/environment"
+
+	// testRunLabel marks the clone and everything copied for it with the owning TestRun
+	testRunLabel = "topas.io/testrun"
+	// cloneOfLabel names the App a clone was made from
+	cloneOfLabel = "topas.io/clone-of"
+)
+
+// environmentNames picks the clone's App name and namespace. The TestRun UID keeps them unique across
+// runs that reuse a name, and the attempt number across the clones of a run retried after its
+// environment failed, whose previous clone may still be terminating.
+func environmentNames(run *appv1alpha1.TestRun) (string, string) {
+	uid := string(run.UID)
+	if len(uid) > 8 {
+		uid = uid[:8]
+	}
+	suffix := ""
+	if attempt := len(run.Status.Attempts) + 1; attempt > 1 {
+		suffix = fmt.Sprintf("-%d", attempt)
+	}
+	if run.Spec.Environment.Isolation == appv1alpha1.IsolationSuffix {
+		return fmt.Sprintf("%s-%s%s", run.Spec.AppName, uid[:min(len(uid), 5)], suffix), run.Namespace
+	}
+	app := run.Spec.AppName
+	if len(app) > 40 {
+		app = app[:40]
+	}
+	return run.Spec.AppName, fmt.Sprintf("topas-%s-%s%s", app, uid, suffix)
+}
+
+// ensureEnvironment creates the TestRun's App clone and reports whether it is healthy. A non-empty
+// failure means the clone can never become ready and the run should stop.
+func (r *TestRunReconciler) ensureEnvironment(ctx context.Context, run *appv1alpha1.TestRun) (bool, string, error) {
+	log := logf.FromContext(ctx)
+
+	if run.Status.Environment == nil {
+		appName, namespace := environmentNames(run)
+		run.Status.Environment = &appv1alpha1.EnvironmentStatus{AppName: appName, Namespace: namespace}
+	}
+	env := run.Status.Environment
+
+	var source appv1alpha1.App
+	if err := r.Get(ctx, types.NamespacedName{Name: run.Spec.AppName, Namespace: run.Namespace}, &source); err != nil {
+		if errors.IsNotFound(err) {
+			return false, fmt.Sprintf("App %q not found", run.Spec.AppName), nil
+		}
+		return false, "", err
+	}
+
+	labels := map[string]string{testRunLabel: run.Name}
+	if env.Namespace != run.Namespace {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: env.Namespace, Labels: labels}}
+		if err := r.Create(ctx, ns); err != nil && !errors.IsAlreadyExists(err) {
+			return false, "", err
+		}
+		if err := r.copyAppReferences(ctx, &source, env.Namespace, labels); err != nil {
+			return false, "", err
+		}
+	}
+
+	var clone appv1alpha1.App
+	err := r.Get(ctx, types.NamespacedName{Name: env.AppName, Namespace: env.Namespace}, &clone)
+	if errors.IsNotFound(err) {
+		clone = appv1alpha1.App{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      env.AppName,
+				Namespace: env.Namespace,
+				Labels:    maps.Clone(source.Labels),
+			},
+			Spec: *source.Spec.DeepCopy(),
+		}
+		// The clone runs the source's current rendering; the template may not exist in its namespace
+		clone.Spec.Template = nil
+		if clone.Labels == nil {
+			clone.Labels = map[string]string{}
+		}
+		maps.Copy(clone.Labels, labels)
+		clone.Labels[cloneOfLabel] = source.Name
+		if env.Namespace == run.Namespace {
+			if err := ctrl.SetControllerReference(run, &clone, r.Scheme); err != nil {
+				return false, "", err
+			}
+		}
+		log.Info("Cloning App for TestRun", "app", source.Name, "clone", env.AppName, "namespace", env.Namespace)
+		if err := r.Create(ctx, &clone); err != nil {
+			if errors.IsInvalid(err) {
+				return false, fmt.Sprintf("cannot clone App %q: %v", source.Name, err), nil
+			}
+			return false, "", err
+		}
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+
+	switch clone.Status.Health {
+	case "Healthy":
+		return true, "", nil
+	case "Unhealthy":
+		msg := "environment " + env.Namespace + "/" + env.AppName + " is unhealthy"
+		if c := meta.FindStatusCondition(clone.Status.Conditions, appv1alpha1.AppConditionDegraded); c != nil {
+			msg += ": " + c.Message
+		}
+		return false, msg, nil
+	}
+	return false, "", nil
+}
+
+// copyAppReferences copies the Secrets and ConfigMaps an App refers to into namespace. Missing objects are
+// skipped; the clone then reports them the same way the original does.
+func (r *TestRunReconciler) copyAppReferences(ctx context.Context, app *appv1alpha1.App, namespace string, labels map[string]string) error {
+	secrets, configMaps := appReferences(app)
+
+	for _, name := range secrets {
+		var src corev1.Secret
+		if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: app.Namespace}, &src); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		dst := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+			Type:       src.Type,
+			Data:       src.Data,
+		}
+		if err := r.Create(ctx, dst); err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+
+	for _, name := range configMaps {
+		var src corev1.ConfigMap
+		if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: app.Namespace}, &src); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		dst := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+			Data:       src.Data,
+			BinaryData: src.BinaryData,
+		}
+		if err := r.Create(ctx, dst); err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// appReferences lists the user-provided Secrets and ConfigMaps named in an App spec. Secrets and
+// ConfigMaps the App controller manages itself are recreated by the clone and are not included.
+func appReferences(app *appv1alpha1.App) ([]string, []string) {
+	secrets := map[string]bool{}
+	configMaps := map[string]bool{}
+
+	workload := func(w appv1alpha1.WorkloadSpec) {
+		for _, vm := range w.VolumeMounts {
+			if vm.Secret != nil {
+				secrets[vm.Secret.SecretName] = true
+			}
+			if vm.ConfigMap != nil {
+				configMaps[vm.ConfigMap.Name] = true
+			}
+		}
+	}
+
+	for _, db := range app.Spec.Databases {
+		if db.CredentialsSecretRef != nil {
+			secrets[db.CredentialsSecretRef.Name] = true
+		}
+		for _, m := range db.Migrations {
+			if m.ConfigMapRef != nil {
+				configMaps[m.ConfigMapRef.Name] = true
+			}
+		}
+		workload(db.WorkloadSpec)
+	}
+
+	for _, svc := range app.Spec.Services {
+		for _, env := range svc.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+				secrets[ref.Name] = true
+			}
+			if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+				configMaps[ref.Name] = true
+			}
+		}
+		for _, src := range svc.EnvFrom {
+			if src.SecretRef != nil {
+				secrets[src.SecretRef.Name] = true
+			}
+			if src.ConfigMapRef != nil {
+				configMaps[src.ConfigMapRef.Name] = true
+			}
+		}
+		if svc.Mock != nil {
+			if ref := svc.Mock.StubsFrom; ref != nil {
+				configMaps[ref.Name] = true
+			}
+			if ref := svc.Mock.DescriptorsFrom; ref != nil {
+				configMaps[ref.Name] = true
+			}
+		}
+		workload(svc.WorkloadSpec)
+	}
+
+	delete(secrets, "")
+	delete(configMaps, "")
+	return slices.Sorted(maps.Keys(secrets)), slices.Sorted(maps.Keys(configMaps))
+}
+
+// finishEnvironment resets the faults the run injected, then tears down the clone of a finished run,
+// or keeps it when the run failed and spec.environment.keepOnFailure is set.
+func (r *TestRunReconciler) finishEnvironment(ctx context.Context, run *appv1alpha1.TestRun) error {
+	if err := r.resetFaults(ctx, run); err != nil {
+		return err
+	}
+	if run.Status.Environment == nil {
+		return nil
+	}
+	if run.Status.State != "Passed" && run.Status.State != "Flaky" && run.Spec.Environment != nil && run.Spec.Environment.KeepOnFailure {
+		logf.FromContext(ctx).Info("Keeping environment of failed TestRun", "app", run.Status.Environment.AppName, "namespace", run.Status.Environment.Namespace)
+		run.Status.Environment.Retained = true
+		return nil
+	}
+	return r.teardownEnvironment(ctx, run)
+}
+
+// teardownEnvironment deletes a TestRun's App clone, along with its namespace when it has one of its own.
+func (r *TestRunReconciler) teardownEnvironment(ctx context.Context, run *appv1alpha1.TestRun) error {
+	env := run.Status.Environment
+	if env == nil {
+		return nil
+	}
+	log := logf.FromContext(ctx)
+
+	if env.Namespace != run.Namespace {
+		log.Info("Deleting environment namespace", "namespace", env.Namespace)
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: env.Namespace}}
+		return client.IgnoreNotFound(r.Delete(ctx, ns))
+	}
+	log.Info("Deleting environment App", "app", env.AppName)
+	clone := &appv1alpha1.App{ObjectMeta: metav1.ObjectMeta{Name: env.AppName, Namespace: env.Namespace}}
+	return client.IgnoreNotFound(r.Delete(ctx, clone))
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// appLeaseGrace outlives the runner pod's deadline, so the lease of a run only lapses once its pod is gone.
+const appLeaseGrace = time.Minute
+
+// appLeaseRenewInterval is how often a Running run renews its lease; the pod deadline only counts from
+// the pod's start, so a pod slow to start would otherwise outlive the lease taken when it was created.
+const appLeaseRenewInterval = 30 * time.Second
+
+// appLeaseName names the Lease an Exclusive TestRun holds on an App.
+func appLeaseName(appName string) string {
+	return appName + "-testrun-lease"
+}
+
+// needsAppLease reports whether a run uses the App itself, shared with other runs, rather than a clone.
+func needsAppLease(run *appv1alpha1.TestRun) bool {
+	return run.Spec.Environment == nil
+}
+
+// acquireAppAccess checks that a run may use its App as its spec.appAccess declares, and takes the App's
+// lease for an Exclusive run. It returns why the run has to wait, or "" once it may start.
+//
+// A Shared run waits while another run holds the lease, and while an Exclusive run created before it
+// waits for the App, so a stream of Shared runs cannot starve it. An Exclusive run waits until no other
+// run uses the App.
+func (r *TestRunReconciler) acquireAppAccess(ctx context.Context, run *appv1alpha1.TestRun) (string, error) {
+	if !needsAppLease(run) {
+		return "", nil
+	}
+	key := types.NamespacedName{Name: appLeaseName(run.Spec.AppName), Namespace: run.Namespace}
+	var lease coordinationv1.Lease
+	if err := r.Get(ctx, key, &lease); client.IgnoreNotFound(err) != nil {
+		return "", err
+	}
+	holder, err := r.leaseHolder(ctx, &lease)
+	if err != nil {
+		return "", err
+	}
+	if holder != "" && holder != run.Name {
+		return fmt.Sprintf("waiting for exclusive access to App %s, held by TestRun %s", run.Spec.AppName, holder), nil
+	}
+
+	var runs appv1alpha1.TestRunList
+	if err := r.List(ctx, &runs, client.InNamespace(run.Namespace)); err != nil {
+		return "", err
+	}
+	for i := range runs.Items {
+		other := &runs.Items[i]
+		if other.Name == run.Name || other.Spec.AppName != run.Spec.AppName || !needsAppLease(other) {
+			continue
+		}
+		if run.Spec.AppAccess == appv1alpha1.AppAccessExclusive && holdsSlot(other) {
+			return fmt.Sprintf("waiting for exclusive access to App %s, used by TestRun %s", run.Spec.AppName, other.Name), nil
+		}
+		if run.Spec.AppAccess != appv1alpha1.AppAccessExclusive && other.Spec.AppAccess == appv1alpha1.AppAccessExclusive &&
+			queued(other) && other.CreationTimestamp.Before(&run.CreationTimestamp) {
+			return fmt.Sprintf("waiting for TestRun %s to get exclusive access to App %s", other.Name, run.Spec.AppName), nil
+		}
+	}
+	if run.Spec.AppAccess != appv1alpha1.AppAccessExclusive {
+		return "", nil
+	}
+
+	// Take or renew the lease for the attempt about to start
+	duration := int32((runTimeout(run) + appLeaseGrace).Seconds())
+	now := metav1.NewMicroTime(time.Now())
+	holderIdentity := run.Name
+	if lease.Name == "" {
+		lease = coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.Name,
+				Namespace: key.Namespace,
+				Labels:    map[string]string{"app": run.Spec.AppName, "runner-type": "topas"},
+			},
+		}
+		lease.Spec = coordinationv1.LeaseSpec{HolderIdentity: &holderIdentity, LeaseDurationSeconds: &duration, AcquireTime: &now, RenewTime: &now}
+		if err := ctrl.SetControllerReference(run, &lease, r.Scheme); err != nil {
+			return "", err
+		}
+		if err := r.Create(ctx, &lease); err != nil {
+			if errors.IsAlreadyExists(err) {
+				return fmt.Sprintf("waiting for exclusive access to App %s", run.Spec.AppName), nil
+			}
+			return "", err
+		}
+	} else {
+		if holder != run.Name {
+			lease.Spec.AcquireTime = &now
+			if lease.Spec.LeaseTransitions == nil {
+				lease.Spec.LeaseTransitions = new(int32)
+			}
+			*lease.Spec.LeaseTransitions++
+		}
+		lease.Spec.HolderIdentity = &holderIdentity
+		lease.Spec.LeaseDurationSeconds = &duration
+		lease.Spec.RenewTime = &now
+		lease.OwnerReferences = nil
+		if err := ctrl.SetControllerReference(run, &lease, r.Scheme); err != nil {
+			return "", err
+		}
+		// A conflict means another run took the lease first
+		if err := r.Update(ctx, &lease); err != nil {
+			if errors.IsConflict(err) {
+				return fmt.Sprintf("waiting for exclusive access to App %s", run.Spec.AppName), nil
+			}
+			return "", err
+		}
+	}
+	logf.FromContext(ctx).Info("Holding App lease", "app", run.Spec.AppName, "lease", lease.Name, "duration", duration)
+	return "", nil
+}
+
+// leaseHolder returns the TestRun holding an App lease, or "" when the lease is free: unheld, held by a
+// run that finished or is gone, or expired. An expired lease still counts as held while its run is
+// Running or waiting to retry, since that run may still be changing the App.
+func (r *TestRunReconciler) leaseHolder(ctx context.Context, lease *coordinationv1.Lease) (string, error) {
+	spec := lease.Spec
+	if spec.HolderIdentity == nil || *spec.HolderIdentity == "" || spec.RenewTime == nil || spec.LeaseDurationSeconds == nil {
+		return "", nil
+	}
+	expired := spec.RenewTime.Add(time.Duration(*spec.LeaseDurationSeconds) * time.Second).Before(time.Now())
+	var holder appv1alpha1.TestRun
+	if err := r.Get(ctx, types.NamespacedName{Name: *spec.HolderIdentity, Namespace: lease.Namespace}, &holder); err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	switch holder.Status.State {
+	case "Passed", "Flaky", "Failed", "Error":
+		return "", nil
+	case "Running":
+		return holder.Name, nil
+	}
+	if expired && len(holder.Status.Attempts) == 0 {
+		return "", nil
+	}
+	return holder.Name, nil
+}
+
+// renewAppLease extends the App lease of a Running Exclusive run, so it lasts as long as the run does.
+func (r *TestRunReconciler) renewAppLease(ctx context.Context, run *appv1alpha1.TestRun) error {
+	if run.Spec.AppAccess != appv1alpha1.AppAccessExclusive || !needsAppLease(run) {
+		return nil
+	}
+	var lease coordinationv1.Lease
+	if err := r.Get(ctx, types.NamespacedName{Name: appLeaseName(run.Spec.AppName), Namespace: run.Namespace}, &lease); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != run.Name {
+		return nil
+	}
+	if lease.Spec.RenewTime != nil && time.Since(lease.Spec.RenewTime.Time) < appLeaseRenewInterval {
+		return nil
+	}
+	now := metav1.NewMicroTime(time.Now())
+	duration := int32((runTimeout(run) + appLeaseGrace).Seconds())
+	lease.Spec.RenewTime = &now
+	lease.Spec.LeaseDurationSeconds = &duration
+	return r.Update(ctx, &lease)
+}
+
+// releaseAppLease frees the App lease of a finished run, when it holds it.
+func (r *TestRunReconciler) releaseAppLease(ctx context.Context, run *appv1alpha1.TestRun) error {
+	if run.Spec.AppAccess != appv1alpha1.AppAccessExclusive || !needsAppLease(run) {
+		return nil
+	}
+	var lease coordinationv1.Lease
+	if err := r.Get(ctx, types.NamespacedName{Name: appLeaseName(run.Spec.AppName), Namespace: run.Namespace}, &lease); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != run.Name {
+		return nil
+	}
+	logf.FromContext(ctx).Info("Releasing App lease", "app", run.Spec.AppName, "lease", lease.Name)
+	return client.IgnoreNotFound(r.Delete(ctx, &lease, client.Preconditions{UID: &lease.UID, ResourceVersion: &lease.ResourceVersion}))
+}
+
+// runTimeout is the deadline of one attempt of a run.
+func runTimeout(run *appv1alpha1.TestRun) time.Duration {
+	if d, err := time.ParseDuration(run.Spec.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return 60 * time.Second
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// holdsSlot reports whether a run counts against the concurrency limits: it runs, or it got through
+// the queue and is provisioning its environment or backing off before a retry.
+func holdsSlot(run *appv1alpha1.TestRun) bool {
+	switch run.Status.State {
+	case "Running":
+		return true
+	case "", "Pending":
+		return run.Status.Environment != nil || len(run.Status.Attempts) > 0
+	}
+	return false
+}
+
+// queued reports whether a run waits for a concurrency slot.
+func queued(run *appv1alpha1.TestRun) bool {
+	switch run.Status.State {
+	case "", "Pending":
+		return !holdsSlot(run) && run.DeletionTimestamp.IsZero()
+	}
+	return false
+}
+
+// runQueue counts the runs holding a slot, globally, per namespace and per App, and the limits on them.
+type runQueue struct {
+	reader client.Reader
+	global int
+
+	active          int
+	activeNamespace map[string]int
+	activeApp       map[types.NamespacedName]int
+
+	namespaceLimits map[string]int
+	appLimits       map[types.NamespacedName]int
+}
+
+// admit decides whether a queued run may start. It walks the queue of waiting runs, highest priority and
+// oldest first, letting through every run the limits leave room for; a run held by the limit of its App
+// or namespace does not hold back the runs behind it that other limits apply to. When the run has to
+// wait, admit returns its position in the queue and the limit holding it.
+func (r *TestRunReconciler) admit(ctx context.Context, run *appv1alpha1.TestRun) (int32, string, error) {
+	var runs appv1alpha1.TestRunList
+	if err := r.List(ctx, &runs); err != nil {
+		return 0, "", err
+	}
+	q := &runQueue{
+		reader:          r.Client,
+		global:          r.MaxConcurrentRuns,
+		activeNamespace: map[string]int{},
+		activeApp:       map[types.NamespacedName]int{},
+		namespaceLimits: map[string]int{},
+		appLimits:       map[types.NamespacedName]int{},
+	}
+	waiting := []*appv1alpha1.TestRun{run}
+	for i := range runs.Items {
+		other := &runs.Items[i]
+		switch {
+		case other.Namespace == run.Namespace && other.Name == run.Name:
+		case holdsSlot(other):
+			q.take(other)
+		case queued(other):
+			waiting = append(waiting, other)
+		}
+	}
+	sort.SliceStable(waiting, func(i, j int) bool {
+		a, b := waiting[i], waiting[j]
+		if a.Spec.Priority != b.Spec.Priority {
+			return a.Spec.Priority > b.Spec.Priority
+		}
+		if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			return a.CreationTimestamp.Before(&b.CreationTimestamp)
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	for position, next := range waiting {
+		reason, err := q.blocked(ctx, next)
+		if err != nil {
+			return 0, "", err
+		}
+		if next == run {
+			if reason == "" {
+				return 0, "", nil
+			}
+			return int32(position + 1), reason, nil
+		}
+		if reason == "" {
+			q.take(next)
+		}
+	}
+	return 0, "", nil
+}
+
+// take counts a run against the limits.
+func (q *runQueue) take(run *appv1alpha1.TestRun) {
+	q.active++
+	q.activeNamespace[run.Namespace]++
+	q.activeApp[types.NamespacedName{Namespace: run.Namespace, Name: run.Spec.AppName}]++
+}
+
+// blocked names the limit a run would exceed by starting, or returns "" when there is room for it.
+func (q *runQueue) blocked(ctx context.Context, run *appv1alpha1.TestRun) (string, error) {
+	if q.global > 0 && q.active >= q.global {
+		return fmt.Sprintf("%d of %d TestRuns running in the cluster", q.active, q.global), nil
+	}
+
+	limit, ok := q.namespaceLimits[run.Namespace]
+	if !ok {
+		var ns corev1.Namespace
+		if err := q.reader.Get(ctx, types.NamespacedName{Name: run.Namespace}, &ns); client.IgnoreNotFound(err) != nil {
+			return "", err
+		}
+		limit, _ = strconv.Atoi(ns.Annotations[appv1alpha1.MaxConcurrentTestRunsAnnotation])
+		q.namespaceLimits[run.Namespace] = limit
+	}
+	if active := q.activeNamespace[run.Namespace]; limit > 0 && active >= limit {
+		return fmt.Sprintf("%d of %d TestRuns running in namespace %s", active, limit, run.Namespace), nil
+	}
+
+	key := types.NamespacedName{Namespace: run.Namespace, Name: run.Spec.AppName}
+	limit, ok = q.appLimits[key]
+	if !ok {
+		var app appv1alpha1.App
+		err := q.reader.Get(ctx, key, &app)
+		switch {
+		case err == nil && app.Spec.MaxConcurrentTestRuns != nil:
+			limit = int(*app.Spec.MaxConcurrentTestRuns)
+		case err != nil && !errors.IsNotFound(err):
+			return "", err
+		}
+		q.appLimits[key] = limit
+	}
+	if active := q.activeApp[key]; limit > 0 && active >= limit {
+		return fmt.Sprintf("%d of %d TestRuns running against App %s", active, limit, run.Spec.AppName), nil
+	}
+	return "", nil
+}
+
+// queuedRuns maps a change to a run that is not queued, which may free a slot or move the queue, to the
+// runs waiting in the queue.
+func (r *TestRunReconciler) queuedRuns(ctx context.Context, obj client.Object) []reconcile.Request {
+	if run, ok := obj.(*appv1alpha1.TestRun); !ok || queued(run) {
+		return nil
+	}
+	var runs appv1alpha1.TestRunList
+	if err := r.List(ctx, &runs); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range runs.Items {
+		if queued(&runs.Items[i]) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&runs.Items[i])})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// runnerContainerName names the container executing the script in a runner pod
+const runnerContainerName = "runner"
+
+// finishRun records the outcome of a run: its state, result line, completion time and Succeeded condition.
+func finishRun(run *appv1alpha1.TestRun, state, reason, message string) {
+	run.Status.State = state
+	run.Status.Result = message
+	run.Status.QueuePosition = 0
+	now := metav1.Now()
+	run.Status.CompletionTime = &now
+
+	status := metav1.ConditionFalse
+	if state == "Passed" || state == "Flaky" {
+		status = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(&run.Status.Conditions, metav1.Condition{
+		Type:               appv1alpha1.TestRunConditionSucceeded,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: run.Generation,
+	})
+}
+
+// finishFromRunner finishes a run whose runner pod has exited, copying the results the runner wrote to its
+// termination message into the status.
+func finishFromRunner(run *appv1alpha1.TestRun, pod *corev1.Pod) {
+	var terminated *corev1.ContainerStateTerminated
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name == runnerContainerName {
+			terminated = cs.State.Terminated
+		}
+	}
+
+	// 1. Read the results, or keep what the runner printed last when it died before writing them
+	results, detail := parseResults(terminated)
+	run.Status.Results = results
+	reported := metav1.Condition{
+		Type:               appv1alpha1.TestRunConditionResultsReported,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Reported",
+		Message:            "results read from the runner's termination message",
+		ObservedGeneration: run.Generation,
+	}
+	switch {
+	case results == nil:
+		reported.Status = metav1.ConditionFalse
+		reported.Reason = "NoResults"
+		reported.Message = "the runner exited without reporting results"
+	case results.Truncated:
+		reported.Reason = "Truncated"
+		reported.Message = "passing test cases were left out to fit the termination message"
+	}
+	meta.SetStatusCondition(&run.Status.Conditions, reported)
+
+	// 2. Explain the outcome
+	switch {
+	case pod.Status.Phase == corev1.PodSucceeded && results != nil:
+		s := results.Summary
+		finishRun(run, "Passed", "TestsPassed", fmt.Sprintf("%d of %d test cases passed, %d assertions", s.Passed, s.Total, s.Assertions))
+	case pod.Status.Phase == corev1.PodSucceeded:
+		finishRun(run, "Passed", "TestsPassed", "Success")
+	case pod.Status.Reason == "DeadlineExceeded":
+		finishRun(run, "Failed", "Timeout", "timed out after "+run.Spec.Timeout)
+	case results != nil && results.Summary.Failed > 0:
+		message := fmt.Sprintf("%d of %d test cases failed", results.Summary.Failed, results.Summary.Total)
+		if first := firstFailure(results); first != nil {
+			message += "; " + first.Name + ": " + describeFailure(first.Failure)
+		}
+		finishRun(run, "Failed", "TestsFailed", message)
+	case results != nil && results.Error != nil:
+		finishRun(run, "Failed", "ScriptError", describeFailure(results.Error))
+	case detail != "":
+		finishRun(run, "Failed", "RunnerFailed", "Runner Pod Failed: "+detail)
+	default:
+		finishRun(run, "Failed", "RunnerFailed", "Runner Pod Failed")
+	}
+}
+
+// parseResults decodes the runner's termination message. When it is not a results document it returns
+// the message's last line, which holds the runner's final log output.
+func parseResults(terminated *corev1.ContainerStateTerminated) (*appv1alpha1.TestResults, string) {
+	if terminated == nil {
+		return nil, ""
+	}
+	msg := strings.TrimSpace(terminated.Message)
+	var results appv1alpha1.TestResults
+	if strings.HasPrefix(msg, "{") && json.Unmarshal([]byte(msg), &results) == nil {
+		return &results, ""
+	}
+	if i := strings.LastIndex(msg, "\n"); i >= 0 {
+		msg = msg[i+1:]
+	}
+	if msg == "" {
+		msg = fmt.Sprintf("exit code %d", terminated.ExitCode)
+	}
+	return nil, msg
+}
+
+// firstFailure returns the first failed test case, or nil when the failures were truncated away.
+func firstFailure(results *appv1alpha1.TestResults) *appv1alpha1.TestCaseResult {
+	for i, tc := range results.TestCases {
+		if tc.State == appv1alpha1.TestCaseFailed && tc.Failure != nil {
+			return &results.TestCases[i]
+		}
+	}
+	return nil
+}
+
+func describeFailure(f *appv1alpha1.TestFailure) string {
+	if f.Location == "" {
+		return f.Message
+	}
+	return f.Message + " (" + f.Location + ")"
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// Backoff applied when spec.backoff leaves it out
+const (
+	defaultRetryDelay    = 10 * time.Second
+	defaultRetryMaxDelay = 5 * time.Minute
+)
+
+// retriedErrorReasons are the reasons of Error runs that a fresh attempt may get past: a runner pod that
+// disappeared (evicted, or lost with its node) and an environment that did not come up, which the retry
+// provisions anew
+var retriedErrorReasons = map[string]bool{
+	"RunnerPodMissing":  true,
+	"EnvironmentFailed": true,
+}
+
+// runnerPodName names the runner pod of the next attempt of a run; retries get a pod of their own so the
+// logs of the failed attempts stay around.
+func runnerPodName(run *appv1alpha1.TestRun) string {
+	if attempt := len(run.Status.Attempts) + 1; attempt > 1 {
+		return fmt.Sprintf("%s-runner-%d", run.Name, attempt)
+	}
+	return run.Name + "-runner"
+}
+
+// recordAttempt appends the outcome the run was finished with to its attempts. pod is the attempt's runner
+// pod, as far as it is known: nil when the attempt failed before creating one.
+func recordAttempt(run *appv1alpha1.TestRun, pod *corev1.Pod) {
+	attempt := appv1alpha1.TestRunAttempt{
+		Attempt:        int32(len(run.Status.Attempts) + 1),
+		State:          run.Status.State,
+		Result:         run.Status.Result,
+		CompletionTime: run.Status.CompletionTime,
+	}
+	if pod != nil {
+		attempt.RunnerPod = pod.Name
+		attempt.StartTime = pod.Status.StartTime
+		if attempt.StartTime == nil && !pod.CreationTimestamp.IsZero() {
+			attempt.StartTime = &pod.CreationTimestamp
+		}
+	}
+	if c := meta.FindStatusCondition(run.Status.Conditions, appv1alpha1.TestRunConditionSucceeded); c != nil {
+		attempt.Reason = c.Reason
+	}
+	if results := run.Status.Results; results != nil {
+		for _, tc := range results.TestCases {
+			if tc.State == appv1alpha1.TestCaseFailed {
+				attempt.FailedTestCases = append(attempt.FailedTestCases, tc.Name)
+			}
+		}
+	}
+	run.Status.Attempts = append(run.Status.Attempts, attempt)
+}
+
+// retryRun puts a failed run with retries left back to Pending until the backoff delay has passed; runs
+// ending in Error are retried for the reasons in retriedErrorReasons only. It reports whether the run
+// will be retried.
+func retryRun(run *appv1alpha1.TestRun) (time.Duration, bool) {
+	failed := int32(len(run.Status.Attempts))
+	if failed > run.Spec.Retries {
+		return 0, false
+	}
+	switch run.Status.State {
+	case "Failed":
+	case "Error":
+		c := meta.FindStatusCondition(run.Status.Conditions, appv1alpha1.TestRunConditionSucceeded)
+		if c == nil || !retriedErrorReasons[c.Reason] {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	delay := retryDelay(run.Spec.Backoff, failed)
+	message := fmt.Sprintf("attempt %d of %d failed, retrying in %s: %s", failed, run.Spec.Retries+1, delay, run.Status.Result)
+
+	next := metav1.NewTime(time.Now().Add(delay))
+	run.Status.State = "Pending"
+	run.Status.Result = message
+	run.Status.CompletionTime = nil
+	run.Status.NextAttemptTime = &next
+	meta.SetStatusCondition(&run.Status.Conditions, metav1.Condition{
+		Type:               appv1alpha1.TestRunConditionSucceeded,
+		Status:             metav1.ConditionUnknown,
+		Reason:             "Retrying",
+		Message:            message,
+		ObservedGeneration: run.Generation,
+	})
+	return delay, true
+}
+
+// markFlaky turns a run that passed after failing attempts into a Flaky one.
+func markFlaky(run *appv1alpha1.TestRun) {
+	attempts := len(run.Status.Attempts)
+	if run.Status.State != "Passed" || attempts < 2 {
+		return
+	}
+	finishRun(run, "Flaky", "PassedOnRetry", fmt.Sprintf("passed on attempt %d of %d: %s", attempts, run.Spec.Retries+1, run.Status.Result))
+}
+
+// retryDelay returns the wait before the retry following the given number of failed attempts.
+func retryDelay(backoff *appv1alpha1.RetryBackoff, failed int32) time.Duration {
+	delay, maxDelay, policy := defaultRetryDelay, defaultRetryMaxDelay, appv1alpha1.BackoffExponential
+	if backoff != nil {
+		if d, err := time.ParseDuration(backoff.Delay); err == nil {
+			delay = d
+		}
+		if d, err := time.ParseDuration(backoff.MaxDelay); err == nil {
+			maxDelay = d
+		}
+		if backoff.Policy != "" {
+			policy = backoff.Policy
+		}
+	}
+	if policy == appv1alpha1.BackoffFixed {
+		return delay
+	}
+	for i := int32(1); i < failed && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
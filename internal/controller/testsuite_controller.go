@@ -0,0 +1,398 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// testSuiteLabel marks the TestRuns and discovery pods of a TestSuite
+const testSuiteLabel = "topas.io/testsuite"
+
+// TestSuiteReconciler reconciles a TestSuite object
+type TestSuiteReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=apps.example.com,resources=testsuites,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=apps.example.com,resources=testsuites/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps.example.com,resources=testsuites/finalizers,verbs=update
+// +kubebuilder:rbac:groups=apps.example.com,resources=testruns,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile expands the scripts of a TestSuite, starts a TestRun for each of them as the execution mode
+// allows, and aggregates their states into the suite status.
+func (r *TestSuiteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var suite appv1alpha1.TestSuite
+	if err := r.Get(ctx, req.NamespacedName, &suite); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	switch suite.Status.State {
+	case "Passed", "Failed", "Error":
+		return ctrl.Result{}, nil
+	}
+
+	// 1. Expand the scripts once, listing the files matching git globs
+	if suite.Status.Runs == nil {
+		runs, failure, err := r.resolveScripts(ctx, &suite)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if failure != "" {
+			suite.Status.State = "Error"
+			suite.Status.Message = failure
+			now := metav1.Now()
+			suite.Status.CompletionTime = &now
+			return ctrl.Result{}, r.Status().Update(ctx, &suite)
+		}
+		if runs == nil {
+			suite.Status.State = "Pending"
+			if err := r.Status().Update(ctx, &suite); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		}
+		log.Info("Resolved TestSuite scripts", "scripts", len(runs))
+		suite.Status.Runs = runs
+	}
+
+	// 2. Follow the TestRuns already started
+	for i := range suite.Status.Runs {
+		run := &suite.Status.Runs[i]
+		if run.TestRun == "" || finished(run.State) {
+			continue
+		}
+		var child appv1alpha1.TestRun
+		if err := r.Get(ctx, types.NamespacedName{Name: run.TestRun, Namespace: suite.Namespace}, &child); err != nil {
+			if !errors.IsNotFound(err) {
+				return ctrl.Result{}, err
+			}
+			run.State, run.Result = "Error", "TestRun was deleted"
+			continue
+		}
+		run.State, run.Result = child.Status.State, child.Status.Result
+		if run.State == "" {
+			run.State = "Pending"
+		}
+	}
+
+	// 3. Start the next TestRuns: one at a time in Sequential mode, up to maxParallel in Parallel mode.
+	// With failFast, nothing new starts once a script did not pass.
+	limit := len(suite.Status.Runs)
+	if suite.Spec.Mode == appv1alpha1.SuiteModeSequential {
+		limit = 1
+	} else if suite.Spec.MaxParallel != nil {
+		limit = int(*suite.Spec.MaxParallel)
+	}
+	active, stopping := 0, false
+	for _, run := range suite.Status.Runs {
+		if run.TestRun != "" && !finished(run.State) {
+			active++
+		}
+		if suite.Spec.FailFast && finished(run.State) && run.State != "Passed" && run.State != "Flaky" {
+			stopping = true
+		}
+	}
+	for i := range suite.Status.Runs {
+		run := &suite.Status.Runs[i]
+		if stopping || active >= limit {
+			break
+		}
+		if run.TestRun != "" || run.State != "" {
+			continue
+		}
+		child := r.defineTestRun(&suite, i)
+		if err := ctrl.SetControllerReference(&suite, child, r.Scheme); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.Create(ctx, child); errors.IsAlreadyExists(err) {
+			// A TestRun created by an earlier reconcile is picked up again, one of the same name made by
+			// anything else is not
+			collision, err := nameCollision(ctx, r.Client, child, &suite, testSuiteLabel)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if collision != "" {
+				suite.Status.State = "Error"
+				suite.Status.Message = collision
+				now := metav1.Now()
+				suite.Status.CompletionTime = &now
+				return ctrl.Result{}, r.Status().Update(ctx, &suite)
+			}
+		} else if err != nil {
+			if !errors.IsInvalid(err) {
+				return ctrl.Result{}, err
+			}
+			// The TestRun webhook rejected the script; it fails without running
+			run.State, run.Result = "Error", err.Error()
+			stopping = suite.Spec.FailFast
+			continue
+		}
+		log.Info("Starting TestRun for suite script", "testRun", child.Name, "script", run.Script)
+		run.TestRun, run.State = child.Name, "Pending"
+		active++
+		if suite.Status.StartTime == nil {
+			now := metav1.Now()
+			suite.Status.StartTime = &now
+		}
+	}
+
+	// 4. Skip what failFast kept from starting, and finish the suite once nothing runs
+	if stopping && active == 0 {
+		for i := range suite.Status.Runs {
+			if suite.Status.Runs[i].State == "" {
+				suite.Status.Runs[i].State = "Skipped"
+			}
+		}
+	}
+	suite.Status.Summary = summarizeSuite(suite.Status.Runs)
+	s := suite.Status.Summary
+	switch {
+	case s.Passed+s.Failed+s.Skipped < s.Total:
+		suite.Status.State = "Running"
+	case s.Passed == s.Total:
+		suite.Status.State = "Passed"
+	default:
+		suite.Status.State = "Failed"
+	}
+	if finished(suite.Status.State) {
+		now := metav1.Now()
+		suite.Status.CompletionTime = &now
+	}
+	return ctrl.Result{}, r.Status().Update(ctx, &suite)
+}
+
+// nameCollisionPrefix starts the message nameCollision returns
+const nameCollisionPrefix = "name collision: "
+
+// nameCollision fetches the existing TestRun named like child and returns why it cannot be adopted when
+// owner does not control it or it lacks owner's label, or an empty string when it is owner's own.
+func nameCollision(ctx context.Context, c client.Client, child *appv1alpha1.TestRun, owner client.Object, label string) (string, error) {
+	var existing appv1alpha1.TestRun
+	if err := c.Get(ctx, client.ObjectKeyFromObject(child), &existing); err != nil {
+		return "", err
+	}
+	if metav1.IsControlledBy(&existing, owner) && existing.Labels[label] == owner.GetName() {
+		return "", nil
+	}
+	return fmt.Sprintf("%sTestRun %s already exists and does not belong to %s", nameCollisionPrefix, child.Name, owner.GetName()), nil
+}
+
+// finished reports whether a TestRun or suite state is final.
+func finished(state string) bool {
+	switch state {
+	case "Passed", "Flaky", "Failed", "Error", "Skipped":
+		return true
+	}
+	return false
+}
+
+func summarizeSuite(runs []appv1alpha1.SuiteRunStatus) appv1alpha1.TestSuiteSummary {
+	s := appv1alpha1.TestSuiteSummary{Total: int32(len(runs))}
+	for _, run := range runs {
+		switch run.State {
+		case "Passed":
+			s.Passed++
+		case "Flaky":
+			s.Passed++
+			s.Flaky++
+		case "Failed", "Error":
+			s.Failed++
+		case "Skipped":
+			s.Skipped++
+		case "Pending", "Running":
+			s.Running++
+		}
+	}
+	return s
+}
+
+// defineTestRun creates the TestRun for the i-th script of the suite.
+func (r *TestSuiteReconciler) defineTestRun(suite *appv1alpha1.TestSuite, i int) *appv1alpha1.TestRun {
+	run := suite.Status.Runs[i]
+	src := suite.Spec.Scripts[run.Source]
+
+	spec := appv1alpha1.TestRunSpec{
+		AppName:     suite.Spec.AppName,
+		Script:      src.Script,
+		Timeout:     suite.Spec.Timeout,
+		Environment: suite.Spec.Environment.DeepCopy(),
+		Tags:        suite.Spec.Tags,
+		Retries:     suite.Spec.Retries,
+		Backoff:     suite.Spec.Backoff.DeepCopy(),
+		AppAccess:   suite.Spec.AppAccess,
+		Params:      suite.Spec.Params,
+		SecretRefs:  suite.Spec.SecretRefs,
+	}
+	if src.Git != nil {
+		spec.Git = &appv1alpha1.GitSource{URL: src.Git.URL, Path: run.Script, Revision: src.Git.Revision}
+	}
+	return &appv1alpha1.TestRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", suite.Name, i+1),
+			Namespace: suite.Namespace,
+			Labels:    map[string]string{testSuiteLabel: suite.Name},
+		},
+		Spec: spec,
+	}
+}
+
+// resolveScripts lists the scripts of the suite with git globs expanded. It returns nil runs while
+// the files matching a glob are still being listed, and a failure when they cannot be.
+func (r *TestSuiteReconciler) resolveScripts(ctx context.Context, suite *appv1alpha1.TestSuite) ([]appv1alpha1.SuiteRunStatus, string, error) {
+	runs := []appv1alpha1.SuiteRunStatus{}
+	pending := false
+	for i, src := range suite.Spec.Scripts {
+		switch {
+		case src.Git != nil && isGlob(src.Git.Path):
+			paths, failure, err := r.discoverScripts(ctx, suite, i)
+			if err != nil || failure != "" {
+				return nil, failure, err
+			}
+			if paths == nil {
+				pending = true
+				continue
+			}
+			for _, p := range paths {
+				runs = append(runs, appv1alpha1.SuiteRunStatus{Script: p, Source: int32(i)})
+			}
+		case src.Git != nil:
+			runs = append(runs, appv1alpha1.SuiteRunStatus{Script: src.Git.Path, Source: int32(i)})
+		default:
+			name := src.Name
+			if name == "" {
+				name = fmt.Sprintf("script-%d", i+1)
+			}
+			runs = append(runs, appv1alpha1.SuiteRunStatus{Script: name, Source: int32(i)})
+		}
+	}
+	if pending {
+		return nil, "", nil
+	}
+	return runs, "", nil
+}
+
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// discoverScripts lists the files of a git repository matching the glob path of the i-th script, using a
+// pod that clones the repository and writes the matches to its termination message. It returns nil
+// paths while the pod runs.
+func (r *TestSuiteReconciler) discoverScripts(ctx context.Context, suite *appv1alpha1.TestSuite, i int) ([]string, string, error) {
+	git := suite.Spec.Scripts[i].Git
+	name := fmt.Sprintf("%s-discover-%d", suite.Name, i+1)
+
+	var pod corev1.Pod
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: suite.Namespace}, &pod)
+	if errors.IsNotFound(err) {
+		pod = corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: suite.Namespace,
+				Labels:    map[string]string{testSuiteLabel: suite.Name},
+			},
+			Spec: corev1.PodSpec{
+				RestartPolicy: corev1.RestartPolicyNever,
+				Containers: []corev1.Container{{
+					Name:    "discover",
+					Image:   "alpine/git",
+					Command: []string{"sh", "-c", discoverCommand(git)},
+					// The matches are the termination message; a failed clone leaves its error there instead
+					TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+				}},
+			},
+		}
+		if err := ctrl.SetControllerReference(suite, &pod, r.Scheme); err != nil {
+			return nil, "", err
+		}
+		return nil, "", r.Create(ctx, &pod)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	var message string
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Terminated != nil {
+			message = strings.TrimSpace(cs.State.Terminated.Message)
+		}
+	}
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		if message == "" {
+			return nil, fmt.Sprintf("no file of %s matches %s", git.URL, git.Path), nil
+		}
+		return strings.Split(message, "\n"), "", nil
+	case corev1.PodFailed:
+		if i := strings.LastIndex(message, "\n"); i >= 0 {
+			message = message[i+1:]
+		}
+		return nil, fmt.Sprintf("cannot list scripts matching %s in %s: %s", git.Path, git.URL, message), nil
+	}
+	return nil, "", nil
+}
+
+// maxTerminationMessageBytes is the size beyond which Kubernetes cuts a container's termination message.
+const maxTerminationMessageBytes = 4096
+
+// discoverCommand clones a repository and writes the files matching its glob path, sorted, to the
+// termination message. Unlike a shell glob, * also matches across directories. A list too long for the
+// termination message would arrive cut, so the pod fails with "too many scripts" instead.
+func discoverCommand(git *appv1alpha1.GitSource) string {
+	cmd := "git clone -q " + shellQuote(git.URL) + " /repo && cd /repo"
+	if git.Revision != "" {
+		cmd += " && git checkout -q " + shellQuote(git.Revision)
+	}
+	pattern := "./" + strings.TrimPrefix(git.Path, "/")
+	cmd += " && find . -type f -path " + shellQuote(pattern) + " | sed 's|^\\./||' | sort > /tmp/scripts"
+	return cmd + fmt.Sprintf(" && if [ $(wc -c < /tmp/scripts) -gt %d ]; then"+
+		" echo \"too many scripts: $(wc -l < /tmp/scripts) files match, more than fit in the %d bytes of a termination message\" > %s; exit 1;"+
+		" fi && cp /tmp/scripts %s",
+		maxTerminationMessageBytes, maxTerminationMessageBytes, corev1.TerminationMessagePathDefault, corev1.TerminationMessagePathDefault)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TestSuiteReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&appv1alpha1.TestSuite{}).
+		Owns(&appv1alpha1.TestRun{}).
+		Owns(&corev1.Pod{}).
+		Named("testsuite").
+		Complete(r)
+}
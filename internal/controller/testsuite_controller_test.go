@@ -0,0 +1,180 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+var _ = Describe("TestSuite Controller", func() {
+	Context("When running scripts sequentially with failFast", func() {
+		const resourceName = "test-suite"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind TestSuite")
+			err := k8sClient.Get(ctx, typeNamespacedName, &appv1alpha1.TestSuite{})
+			if err != nil && errors.IsNotFound(err) {
+				resource := &appv1alpha1.TestSuite{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: "default",
+					},
+					Spec: appv1alpha1.TestSuiteSpec{
+						AppName:  "test-resource",
+						Mode:     appv1alpha1.SuiteModeSequential,
+						FailFast: true,
+						Tags:     []string{"smoke"},
+						Scripts: []appv1alpha1.SuiteScript{
+							{Name: "first", Script: "assert(false)"},
+							{Name: "second", Script: "assert(true)"},
+							{Script: "assert(true)"},
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &appv1alpha1.TestSuite{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+
+			By("Cleanup the specific resource instance TestSuite")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should skip the scripts left once one fails", func() {
+			controllerReconciler := &TestSuiteReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("starting only the first script")
+			suite := &appv1alpha1.TestSuite{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, suite)).To(Succeed())
+			Expect(suite.Status.State).To(Equal("Running"))
+			Expect(suite.Status.Runs).To(HaveLen(3))
+			Expect(suite.Status.Runs[0].TestRun).To(Equal(resourceName + "-1"))
+			Expect(suite.Status.Runs[1].TestRun).To(BeEmpty())
+			Expect(suite.Status.Runs[2].Script).To(Equal("script-3"))
+
+			first := &appv1alpha1.TestRun{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-1", Namespace: "default"}, first)).To(Succeed())
+			Expect(first.Spec.Script).To(Equal("assert(false)"))
+			Expect(first.Spec.Tags).To(Equal([]string{"smoke"}))
+			Expect(first.OwnerReferences).To(HaveLen(1))
+
+			By("failing the first TestRun")
+			first.Status.State = "Failed"
+			first.Status.Result = "1 of 1 test cases failed"
+			Expect(k8sClient.Status().Update(ctx, first)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, suite)).To(Succeed())
+			Expect(suite.Status.State).To(Equal("Failed"))
+			Expect(suite.Status.Runs[0].Result).To(Equal("1 of 1 test cases failed"))
+			Expect(suite.Status.Runs[1].State).To(Equal("Skipped"))
+			Expect(suite.Status.Runs[2].State).To(Equal("Skipped"))
+			Expect(suite.Status.Summary).To(Equal(appv1alpha1.TestSuiteSummary{Total: 3, Failed: 1, Skipped: 2}))
+			Expect(suite.Status.CompletionTime).NotTo(BeNil())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-2", Namespace: "default"}, &appv1alpha1.TestRun{})).NotTo(Succeed())
+		})
+	})
+
+	Context("When a TestRun of the suite's name belongs to something else", func() {
+		const resourceName = "test-suite-collision"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating a TestRun named like the suite's first one, and the suite")
+			other := &appv1alpha1.TestRun{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-1", Namespace: "default"},
+				Spec:       appv1alpha1.TestRunSpec{AppName: "test-resource", Script: "assert(true)"},
+			}
+			Expect(k8sClient.Create(ctx, other)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, other)).To(Succeed())
+			})
+
+			resource := &appv1alpha1.TestSuite{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: appv1alpha1.TestSuiteSpec{
+					AppName: "test-resource",
+					Scripts: []appv1alpha1.SuiteScript{{Script: "assert(true)"}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			By("Cleanup the specific resource instance TestSuite")
+			resource := &appv1alpha1.TestSuite{ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"}}
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should fail the suite rather than adopt the TestRun", func() {
+			controllerReconciler := &TestSuiteReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			suite := &appv1alpha1.TestSuite{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, suite)).To(Succeed())
+			Expect(suite.Status.State).To(Equal("Error"))
+			Expect(suite.Status.Message).To(HavePrefix("name collision: TestRun " + resourceName + "-1"))
+			Expect(suite.Status.Runs[0].TestRun).To(BeEmpty())
+
+			other := &appv1alpha1.TestRun{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-1", Namespace: "default"}, other)).To(Succeed())
+			Expect(other.OwnerReferences).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,79 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	appsv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// applyWorkload copies the probes, resources, scheduling and volume settings of a service or database
+// onto its pod spec. The first container is the component's own. Probe ports left at 0 default to
+// port, or grpcPort for gRPC probes when set.
+func applyWorkload(pod *corev1.PodSpec, w appsv1alpha1.WorkloadSpec, port int32, grpcPort *int32) {
+	container := &pod.Containers[0]
+	if w.ReadinessProbe != nil {
+		container.ReadinessProbe = withProbePort(w.ReadinessProbe, port, grpcPort)
+	}
+	if w.LivenessProbe != nil {
+		container.LivenessProbe = withProbePort(w.LivenessProbe, port, grpcPort)
+	}
+	container.Resources = w.Resources
+	pod.NodeSelector = w.NodeSelector
+	pod.Tolerations = w.Tolerations
+
+	for _, v := range w.VolumeMounts {
+		pod.Volumes = append(pod.Volumes, corev1.Volume{
+			Name: v.Name,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap:             v.ConfigMap,
+				Secret:                v.Secret,
+				PersistentVolumeClaim: v.PersistentVolumeClaim,
+				EmptyDir:              v.EmptyDir,
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      v.Name,
+			MountPath: v.MountPath,
+			SubPath:   v.SubPath,
+			ReadOnly:  v.ReadOnly,
+		})
+	}
+}
+
+// withProbePort returns a copy of probe with an unset port filled in.
+func withProbePort(probe *corev1.Probe, port int32, grpcPort *int32) *corev1.Probe {
+	p := probe.DeepCopy()
+	switch {
+	case p.HTTPGet != nil && isUnsetPort(p.HTTPGet.Port):
+		p.HTTPGet.Port = intstr.FromInt32(port)
+	case p.TCPSocket != nil && isUnsetPort(p.TCPSocket.Port):
+		p.TCPSocket.Port = intstr.FromInt32(port)
+	case p.GRPC != nil && p.GRPC.Port == 0:
+		p.GRPC.Port = port
+		if grpcPort != nil {
+			p.GRPC.Port = *grpcPort
+		}
+	}
+	return p
+}
+
+func isUnsetPort(port intstr.IntOrString) bool {
+	return port.Type == intstr.Int && port.IntVal == 0
+}
@@ -0,0 +1,546 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	appsv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// log is for logging in this package.
+var applog = logf.Log.WithName("app-resource")
+
+// SetupAppWebhookWithManager registers the webhook for App in the manager.
+func SetupAppWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &appsv1alpha1.App{}).
+		WithValidator(&AppCustomValidator{}).
+		WithDefaulter(&AppCustomDefaulter{}).
+		Complete()
+}
+
+// requiredDatabaseCredentials lists the credential keys each engine cannot start or connect without
+var requiredDatabaseCredentials = map[string][]string{
+	appsv1alpha1.DatabaseEnginePostgres: {"user", "dbname"},
+	appsv1alpha1.DatabaseEngineMySQL:    {"user", "dbname"},
+}
+
+// +kubebuilder:webhook:path=/mutate-apps-example-com-v1alpha1-app,mutating=true,failurePolicy=fail,sideEffects=None,groups=apps.example.com,resources=apps,verbs=create;update,versions=v1alpha1,name=mapp-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// AppCustomDefaulter sets default values on the App resource when it is created or updated.
+type AppCustomDefaulter struct{}
+
+// Default implements admission.Defaulter so a webhook will be registered for the type App.
+func (d *AppCustomDefaulter) Default(_ context.Context, app *appsv1alpha1.App) error {
+	applog.Info("Defaulting for App", "name", app.GetName())
+
+	for i := range app.Spec.Databases {
+		db := &app.Spec.Databases[i]
+		if db.Engine == "" {
+			db.Engine = appsv1alpha1.DatabaseEnginePostgres
+		}
+		if db.Port == 0 {
+			db.Port = appsv1alpha1.DefaultDatabasePort(db.Engine)
+		}
+	}
+
+	for i := range app.Spec.Services {
+		if app.Spec.Services[i].Replicas == nil {
+			replicas := int32(1)
+			app.Spec.Services[i].Replicas = &replicas
+		}
+	}
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-apps-example-com-v1alpha1-app,mutating=false,failurePolicy=fail,sideEffects=None,groups=apps.example.com,resources=apps,verbs=create;update,versions=v1alpha1,name=vapp-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// AppCustomValidator validates the App resource when it is created or updated.
+type AppCustomValidator struct{}
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the type App.
+func (v *AppCustomValidator) ValidateCreate(_ context.Context, app *appsv1alpha1.App) (admission.Warnings, error) {
+	applog.Info("Validation for App upon creation", "name", app.GetName())
+	return appWarnings(app), validateApp(app, nil)
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the type App.
+func (v *AppCustomValidator) ValidateUpdate(_ context.Context, old, app *appsv1alpha1.App) (admission.Warnings, error) {
+	applog.Info("Validation for App upon update", "name", app.GetName())
+	return appWarnings(app), validateApp(app, old)
+}
+
+// ValidateDelete implements admission.Validator so a webhook will be registered for the type App.
+func (v *AppCustomValidator) ValidateDelete(_ context.Context, _ *appsv1alpha1.App) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// appWarnings flags settings that are accepted but probably not what the author meant.
+func appWarnings(app *appsv1alpha1.App) admission.Warnings {
+	var warnings admission.Warnings
+	for i, svc := range app.Spec.Services {
+		if svc.IsMock() && svc.Image != "" {
+			warnings = append(warnings, fmt.Sprintf("spec.services[%d].image %q is ignored because mock services run the built-in stub server", i, svc.Image))
+			continue
+		}
+		if svc.Version != "" && svc.ImageRef() == svc.Image && svc.DesiredVersion() != svc.Version {
+			warnings = append(warnings, fmt.Sprintf("spec.services[%d].version %q is ignored because image %q already has a tag or digest", i, svc.Version, svc.Image))
+		}
+	}
+	return warnings
+}
+
+// validateApp returns an Invalid error listing every problem found in the App spec, or nil.
+// old is the App being replaced on update and nil on create.
+func validateApp(app, old *appsv1alpha1.App) error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if app.Spec.Template != nil && app.Spec.Template.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("template", "name"), "AppTemplate name is required"))
+	}
+
+	// Services and databases share the "<app>-<name>" namespace for Deployments and Services
+	seen := map[string]bool{}
+
+	for i, db := range app.Spec.Databases {
+		p := specPath.Child("databases").Index(i)
+		allErrs = append(allErrs, validateComponentName(app.Name, db.Name, p.Child("name"), seen)...)
+		if db.Image == "" {
+			allErrs = append(allErrs, field.Required(p.Child("image"), "database image is required"))
+		}
+		engine := db.Engine
+		if engine == "" {
+			engine = appsv1alpha1.DatabaseEnginePostgres
+		}
+		if appsv1alpha1.DefaultDatabasePort(engine) == 0 {
+			allErrs = append(allErrs, field.NotSupported(p.Child("engine"), engine, []string{
+				appsv1alpha1.DatabaseEnginePostgres, appsv1alpha1.DatabaseEngineMySQL,
+				appsv1alpha1.DatabaseEngineRedis, appsv1alpha1.DatabaseEngineMongoDB,
+			}))
+		}
+		if db.Port != 0 {
+			allErrs = append(allErrs, validatePort(db.Port, p.Child("port"))...)
+		}
+		allErrs = append(allErrs, validateMigrations(db, p)...)
+		allErrs = append(allErrs, validateWorkload(db.WorkloadSpec, p)...)
+		allErrs = append(allErrs, validateStorage(db, old, p)...)
+		switch {
+		case db.CredentialsSecretRef != nil && len(db.Credentials) > 0:
+			allErrs = append(allErrs, field.Forbidden(p.Child("credentialsSecretRef"), "credentials and credentialsSecretRef are mutually exclusive"))
+		case db.CredentialsSecretRef != nil:
+			if db.CredentialsSecretRef.Name == "" {
+				allErrs = append(allErrs, field.Required(p.Child("credentialsSecretRef", "name"), "Secret name is required"))
+			}
+		case len(db.Credentials) > 0:
+			// Empty credentials are generated by the controller; partial ones are a mistake
+			for _, key := range requiredDatabaseCredentials[engine] {
+				if db.Credentials[key] == "" {
+					allErrs = append(allErrs, field.Required(p.Child("credentials").Key(key), "database credentials for "+engine+" must include "+key))
+				}
+			}
+		}
+	}
+
+	for i, svc := range app.Spec.Services {
+		p := specPath.Child("services").Index(i)
+		allErrs = append(allErrs, validateComponentName(app.Name, svc.Name, p.Child("name"), seen)...)
+		if svc.Image == "" && !svc.IsMock() {
+			allErrs = append(allErrs, field.Required(p.Child("image"), "service image is required"))
+		}
+		allErrs = append(allErrs, validateMock(svc, p)...)
+		allErrs = append(allErrs, validateFaultInjection(svc, p)...)
+		allErrs = append(allErrs, validatePort(svc.Port, p.Child("port"))...)
+		if svc.GrpcPort != nil {
+			allErrs = append(allErrs, validatePort(*svc.GrpcPort, p.Child("grpcPort"))...)
+			if *svc.GrpcPort == svc.Port {
+				allErrs = append(allErrs, field.Invalid(p.Child("grpcPort"), *svc.GrpcPort, "must differ from port"))
+			}
+		}
+		if svc.Replicas != nil && *svc.Replicas < 0 {
+			allErrs = append(allErrs, field.Invalid(p.Child("replicas"), *svc.Replicas, "must be greater than or equal to 0"))
+		}
+		for name := range svc.EnvVars {
+			for _, msg := range validation.IsEnvVarName(name) {
+				allErrs = append(allErrs, field.Invalid(p.Child("envVars").Key(name), name, msg))
+			}
+		}
+		allErrs = append(allErrs, validateWorkload(svc.WorkloadSpec, p)...)
+		allErrs = append(allErrs, validateStrategy(app, svc, p)...)
+		for j, env := range svc.Env {
+			envPath := p.Child("env").Index(j)
+			for _, msg := range validation.IsEnvVarName(env.Name) {
+				allErrs = append(allErrs, field.Invalid(envPath.Child("name"), env.Name, msg))
+			}
+			if env.Value != "" && env.ValueFrom != nil {
+				allErrs = append(allErrs, field.Invalid(envPath.Child("valueFrom"), "", "may not be specified when value is not empty"))
+			}
+		}
+	}
+
+	allErrs = append(allErrs, validateDependencies(app, seen, specPath)...)
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(appsv1alpha1.GroupVersion.WithKind("App").GroupKind(), app.Name, allErrs)
+}
+
+// validateStorage checks a database's storage request and, on update, that its volume claim template is unchanged.
+func validateStorage(db appsv1alpha1.DatabaseSpec, old *appsv1alpha1.App, p *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if db.Storage != nil && db.Storage.Size.Sign() <= 0 {
+		errs = append(errs, field.Invalid(p.Child("storage", "size"), db.Storage.Size.String(), "must be greater than 0"))
+	}
+	if old == nil {
+		return errs
+	}
+
+	// StatefulSet volume claim templates are immutable, so storage can only be added or removed as a whole
+	for _, prev := range old.Spec.Databases {
+		if prev.Name != db.Name || prev.Storage == nil || db.Storage == nil {
+			continue
+		}
+		if prev.Storage.Size.Cmp(db.Storage.Size) != 0 {
+			errs = append(errs, field.Forbidden(p.Child("storage", "size"), "may not be changed once set"))
+		}
+		if storageClassOf(prev.Storage) != storageClassOf(db.Storage) {
+			errs = append(errs, field.Forbidden(p.Child("storage", "storageClassName"), "may not be changed once set"))
+		}
+	}
+	return errs
+}
+
+// storageClassOf returns the requested storage class, or "" for the cluster default.
+func storageClassOf(s *appsv1alpha1.DatabaseStorageSpec) string {
+	if s.StorageClassName == nil {
+		return ""
+	}
+	return *s.StorageClassName
+}
+
+// validateStrategy checks a service's rollout strategy and that the Deployment it runs a new version in
+// cannot collide with another component.
+func validateStrategy(app *appsv1alpha1.App, svc appsv1alpha1.ServiceSpec, p *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if svc.Strategy == nil {
+		return errs
+	}
+	strategy := svc.RolloutStrategyType()
+	switch strategy {
+	case appsv1alpha1.RolloutStrategyRollingUpdate:
+	case appsv1alpha1.RolloutStrategyCanary, appsv1alpha1.RolloutStrategyBlueGreen:
+		track := "canary"
+		if strategy == appsv1alpha1.RolloutStrategyBlueGreen {
+			track = "preview"
+		}
+		names := []string{}
+		for _, other := range app.Spec.Services {
+			names = append(names, other.Name)
+		}
+		for _, db := range app.Spec.Databases {
+			names = append(names, db.Name)
+		}
+		if slices.Contains(names, svc.Name+"-"+track) {
+			errs = append(errs, field.Invalid(p.Child("strategy", "type"), strategy,
+				"component "+svc.Name+"-"+track+" would collide with the "+track+" Deployment"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(p.Child("strategy", "type"), strategy, []string{
+			appsv1alpha1.RolloutStrategyRollingUpdate, appsv1alpha1.RolloutStrategyCanary, appsv1alpha1.RolloutStrategyBlueGreen,
+		}))
+	}
+	if w := svc.Strategy.CanaryWeight; w != nil {
+		if strategy != appsv1alpha1.RolloutStrategyCanary {
+			errs = append(errs, field.Forbidden(p.Child("strategy", "canaryWeight"), "only applies to the Canary strategy"))
+		} else if *w < 1 || *w > 99 {
+			errs = append(errs, field.Invalid(p.Child("strategy", "canaryWeight"), *w, "must be between 1 and 99"))
+		}
+	}
+	return errs
+}
+
+// validateMock checks a service's kind and, for mocks, that every stub can be served.
+func validateMock(svc appsv1alpha1.ServiceSpec, p *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	switch svc.Kind {
+	case "", appsv1alpha1.ServiceKindContainer:
+		if svc.Mock != nil {
+			errs = append(errs, field.Forbidden(p.Child("mock"), "only applies to services of kind Mock"))
+		}
+		return errs
+	case appsv1alpha1.ServiceKindMock:
+	default:
+		return append(errs, field.NotSupported(p.Child("kind"), svc.Kind, []string{
+			appsv1alpha1.ServiceKindContainer, appsv1alpha1.ServiceKindMock,
+		}))
+	}
+	if svc.Mock == nil {
+		return errs
+	}
+
+	for i, stub := range svc.Mock.Stubs {
+		sp := p.Child("mock", "stubs").Index(i)
+		if stub.Request.PathPattern != "" {
+			if _, err := regexp.Compile(stub.Request.PathPattern); err != nil {
+				errs = append(errs, field.Invalid(sp.Child("request", "pathPattern"), stub.Request.PathPattern, err.Error()))
+			}
+		}
+		if m := stub.Request.GRPCMethod; m != "" && !grpcMethodPattern.MatchString(m) {
+			errs = append(errs, field.Invalid(sp.Child("request", "grpcMethod"), m, "must be a full method name such as /pkg.Service/Method"))
+		}
+		if st := stub.Response.Status; st != 0 && (st < 100 || st > 599) {
+			errs = append(errs, field.Invalid(sp.Child("response", "status"), st, "must be between 100 and 599"))
+		}
+		if c := stub.Response.GRPCCode; c < 0 || c > 16 {
+			errs = append(errs, field.Invalid(sp.Child("response", "grpcCode"), c, "must be between 0 and 16"))
+		}
+	}
+	errs = append(errs, validateConfigMapKey(svc.Mock.StubsFrom, p.Child("mock", "stubsFrom"))...)
+	errs = append(errs, validateConfigMapKey(svc.Mock.DescriptorsFrom, p.Child("mock", "descriptorsFrom"))...)
+	return errs
+}
+
+// validateFaultInjection checks that a service with fault injection leaves the proxy's ports free and asks
+// for faults the proxy can inject.
+func validateFaultInjection(svc appsv1alpha1.ServiceSpec, p *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if svc.FaultInjection == nil {
+		return errs
+	}
+	reserved := []int32{appsv1alpha1.FaultProxyAdminPort, appsv1alpha1.FaultProxyHTTPPort, appsv1alpha1.FaultProxyGRPCPort}
+	if slices.Contains(reserved, svc.Port) {
+		errs = append(errs, field.Invalid(p.Child("port"), svc.Port, "is used by the fault-injection proxy"))
+	}
+	if svc.GrpcPort != nil && slices.Contains(reserved, *svc.GrpcPort) {
+		errs = append(errs, field.Invalid(p.Child("grpcPort"), *svc.GrpcPort, "is used by the fault-injection proxy"))
+	}
+	if f := svc.FaultInjection.Faults; f != nil {
+		errs = append(errs, validateFaults(*f, p.Child("faultInjection", "faults"))...)
+	}
+	return errs
+}
+
+// validateFaults checks the faults a fault-injection proxy starts with.
+func validateFaults(f appsv1alpha1.FaultSpec, p *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if f.Latency != nil && f.Latency.Duration < 0 {
+		errs = append(errs, field.Invalid(p.Child("latency"), f.Latency.Duration.String(), "must not be negative"))
+	}
+	if f.Jitter != nil && f.Jitter.Duration < 0 {
+		errs = append(errs, field.Invalid(p.Child("jitter"), f.Jitter.Duration.String(), "must not be negative"))
+	}
+	if f.ErrorPercent < 0 || f.ErrorPercent > 100 {
+		errs = append(errs, field.Invalid(p.Child("errorPercent"), f.ErrorPercent, "must be between 0 and 100"))
+	}
+	if f.ResetPercent < 0 || f.ResetPercent > 100 {
+		errs = append(errs, field.Invalid(p.Child("resetPercent"), f.ResetPercent, "must be between 0 and 100"))
+	}
+	if f.ErrorStatus != 0 && (f.ErrorStatus < 100 || f.ErrorStatus > 599) {
+		errs = append(errs, field.Invalid(p.Child("errorStatus"), f.ErrorStatus, "must be between 100 and 599"))
+	}
+	if f.Bandwidth != nil && f.Bandwidth.Sign() <= 0 {
+		errs = append(errs, field.Invalid(p.Child("bandwidth"), f.Bandwidth.String(), "must be greater than 0"))
+	}
+	return errs
+}
+
+// validateConfigMapKey checks that an optional ConfigMap key reference names both the ConfigMap and the key.
+func validateConfigMapKey(ref *corev1.ConfigMapKeySelector, p *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if ref == nil {
+		return errs
+	}
+	if ref.Name == "" {
+		errs = append(errs, field.Required(p.Child("name"), "ConfigMap name is required"))
+	}
+	if ref.Key == "" {
+		errs = append(errs, field.Required(p.Child("key"), "ConfigMap key is required"))
+	}
+	return errs
+}
+
+// grpcMethodPattern matches a full gRPC method name such as /payments.v1.Payments/Charge.
+var grpcMethodPattern = regexp.MustCompile(`^/[A-Za-z_][\w.]*/[A-Za-z_]\w*$`)
+
+// validateComponentName checks that a service or database name is unique and yields a valid resource name.
+func validateComponentName(appName, name string, p *field.Path, seen map[string]bool) field.ErrorList {
+	var errs field.ErrorList
+	if name == "" {
+		return append(errs, field.Required(p, "name is required"))
+	}
+	if seen[name] {
+		errs = append(errs, field.Duplicate(p, name))
+	}
+	seen[name] = true
+	for _, msg := range validation.IsDNS1035Label(appName + "-" + name) {
+		errs = append(errs, field.Invalid(p, name, "generated resource name "+appName+"-"+name+" is invalid: "+msg))
+	}
+	return errs
+}
+
+// validateDependencies checks that dependsOn names known components and that services don't wait on each other in a cycle.
+func validateDependencies(app *appsv1alpha1.App, components map[string]bool, specPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	graph := map[string][]string{}
+	for i, svc := range app.Spec.Services {
+		for j, dep := range svc.DependsOn {
+			p := specPath.Child("services").Index(i).Child("dependsOn").Index(j)
+			switch {
+			case dep == svc.Name:
+				errs = append(errs, field.Invalid(p, dep, "a service cannot depend on itself"))
+			case !components[dep]:
+				errs = append(errs, field.NotFound(p, dep))
+			default:
+				graph[svc.Name] = append(graph[svc.Name], dep)
+			}
+		}
+	}
+
+	// Depth-first search; a service reached again while still on the stack closes a cycle
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+	var visit func(name string, path []string) []string
+	visit = func(name string, path []string) []string {
+		switch state[name] {
+		case visiting:
+			return append(path[slices.Index(path, name):], name)
+		case done:
+			return nil
+		}
+		state[name] = visiting
+		for _, dep := range graph[name] {
+			if cycle := visit(dep, append(path, name)); cycle != nil {
+				return cycle
+			}
+		}
+		state[name] = done
+		return nil
+	}
+	for _, svc := range app.Spec.Services {
+		if cycle := visit(svc.Name, nil); cycle != nil {
+			i := slices.IndexFunc(app.Spec.Services, func(s appsv1alpha1.ServiceSpec) bool { return s.Name == cycle[0] })
+			errs = append(errs, field.Invalid(specPath.Child("services").Index(i).Child("dependsOn"), app.Spec.Services[i].DependsOn,
+				"dependency cycle: "+strings.Join(cycle, " -> ")))
+			break
+		}
+	}
+	return errs
+}
+
+// validateMigrations checks that migration versions are unique and each has exactly one script source.
+func validateMigrations(db appsv1alpha1.DatabaseSpec, p *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	// InitSQL is applied under this version
+	seen := map[string]bool{"initSQL": db.InitSQL != ""}
+	for i, m := range db.Migrations {
+		mp := p.Child("migrations").Index(i)
+		switch {
+		case m.Version == "":
+			errs = append(errs, field.Required(mp.Child("version"), "migration version is required"))
+		case seen[m.Version]:
+			errs = append(errs, field.Duplicate(mp.Child("version"), m.Version))
+		}
+		seen[m.Version] = true
+
+		switch {
+		case m.SQL != "" && m.ConfigMapRef != nil:
+			errs = append(errs, field.Forbidden(mp.Child("configMapRef"), "sql and configMapRef are mutually exclusive"))
+		case m.SQL == "" && m.ConfigMapRef == nil:
+			errs = append(errs, field.Required(mp, "one of sql or configMapRef must be set"))
+		case m.ConfigMapRef != nil:
+			if m.ConfigMapRef.Name == "" {
+				errs = append(errs, field.Required(mp.Child("configMapRef", "name"), "ConfigMap name is required"))
+			}
+			if m.ConfigMapRef.Key == "" {
+				errs = append(errs, field.Required(mp.Child("configMapRef", "key"), "ConfigMap key is required"))
+			}
+		}
+	}
+	return errs
+}
+
+// validateWorkload checks probe handlers and extra volume mounts.
+func validateWorkload(w appsv1alpha1.WorkloadSpec, p *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for _, probe := range []struct {
+		name  string
+		probe *corev1.Probe
+	}{{"readinessProbe", w.ReadinessProbe}, {"livenessProbe", w.LivenessProbe}} {
+		if probe.probe == nil {
+			continue
+		}
+		handlers := 0
+		for _, set := range []bool{probe.probe.HTTPGet != nil, probe.probe.TCPSocket != nil, probe.probe.GRPC != nil, probe.probe.Exec != nil} {
+			if set {
+				handlers++
+			}
+		}
+		if handlers != 1 {
+			errs = append(errs, field.Invalid(p.Child(probe.name), field.OmitValueType{}, "must set exactly one of httpGet, tcpSocket, grpc or exec"))
+		}
+	}
+
+	names := map[string]bool{}
+	for i, v := range w.VolumeMounts {
+		vp := p.Child("volumeMounts").Index(i)
+		for _, msg := range validation.IsDNS1123Label(v.Name) {
+			errs = append(errs, field.Invalid(vp.Child("name"), v.Name, msg))
+		}
+		if names[v.Name] {
+			errs = append(errs, field.Duplicate(vp.Child("name"), v.Name))
+		}
+		names[v.Name] = true
+		if v.MountPath == "" {
+			errs = append(errs, field.Required(vp.Child("mountPath"), "mount path is required"))
+		}
+		sources := 0
+		for _, set := range []bool{v.ConfigMap != nil, v.Secret != nil, v.PersistentVolumeClaim != nil, v.EmptyDir != nil} {
+			if set {
+				sources++
+			}
+		}
+		if sources != 1 {
+			errs = append(errs, field.Invalid(vp, v.Name, "must set exactly one of configMap, secret, persistentVolumeClaim or emptyDir"))
+		}
+	}
+	return errs
+}
+
+// validatePort checks that a port is within the TCP range.
+func validatePort(port int32, p *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for _, msg := range validation.IsValidPortNum(int(port)) {
+		errs = append(errs, field.Invalid(p, port, msg))
+	}
+	return errs
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+var _ = Describe("App Webhook", func() {
+	var (
+		obj       *appsv1alpha1.App
+		validator AppCustomValidator
+		defaulter AppCustomDefaulter
+	)
+
+	BeforeEach(func() {
+		grpcPort := int32(50051)
+		obj = &appsv1alpha1.App{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "default"},
+			Spec: appsv1alpha1.AppSpec{
+				Services: []appsv1alpha1.ServiceSpec{{
+					Name:     "api",
+					Image:    "shop/api:v1",
+					Version:  "v1",
+					Port:     8080,
+					GrpcPort: &grpcPort,
+				}},
+				Databases: []appsv1alpha1.DatabaseSpec{{
+					Name:        "db",
+					Image:       "postgres:16-alpine",
+					Port:        5432,
+					Credentials: map[string]string{"user": "shop", "password": "shop", "dbname": "shop"},
+				}},
+			},
+		}
+		validator = AppCustomValidator{}
+		defaulter = AppCustomDefaulter{}
+	})
+
+	Context("When creating App under Defaulting Webhook", func() {
+		It("Should default replicas to 1", func() {
+			By("calling the Default method to apply defaults")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Services[0].Replicas).NotTo(BeNil())
+			Expect(*obj.Spec.Services[0].Replicas).To(Equal(int32(1)))
+		})
+	})
+
+	Context("When creating App with databases under Defaulting Webhook", func() {
+		It("Should default the engine to postgres and the port per engine", func() {
+			obj.Spec.Databases[0].Port = 0
+			obj.Spec.Databases = append(obj.Spec.Databases, appsv1alpha1.DatabaseSpec{
+				Name: "cache", Engine: appsv1alpha1.DatabaseEngineRedis, Image: "redis:7-alpine",
+			})
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Databases[0].Engine).To(Equal(appsv1alpha1.DatabaseEnginePostgres))
+			Expect(obj.Spec.Databases[0].Port).To(Equal(int32(5432)))
+			Expect(obj.Spec.Databases[1].Port).To(Equal(int32(6379)))
+		})
+	})
+
+	Context("When creating or updating App under Validating Webhook", func() {
+		It("Should admit a valid App", func() {
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should warn when version is ignored because the image is tagged", func() {
+			obj.Spec.Services[0].Version = "v2"
+			warnings, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ContainElement(ContainSubstring("spec.services[0].version")))
+		})
+
+		It("Should deny duplicate component names", func() {
+			obj.Spec.Services = append(obj.Spec.Services, appsv1alpha1.ServiceSpec{
+				Name: "db", Image: "shop/worker:v1", Version: "v1", Port: 9090,
+			})
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.services[1].name: Duplicate value")))
+		})
+
+		It("Should deny a port that collides with grpcPort", func() {
+			port := int32(8080)
+			obj.Spec.Services[0].GrpcPort = &port
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.services[0].grpcPort")))
+		})
+
+		It("Should deny databases without user or dbname credentials", func() {
+			obj.Spec.Databases[0].Credentials = map[string]string{"password": "shop"}
+			_, err := validator.ValidateUpdate(ctx, obj, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.databases[0].credentials[user]")))
+			Expect(err).To(MatchError(ContainSubstring("spec.databases[0].credentials[dbname]")))
+		})
+
+		It("Should admit a database without credentials so the controller can generate them", func() {
+			obj.Spec.Databases[0].Credentials = nil
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny inline credentials combined with a Secret reference", func() {
+			obj.Spec.Databases[0].CredentialsSecretRef = &corev1.LocalObjectReference{Name: "shop-db"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.databases[0].credentialsSecretRef")))
+		})
+
+		It("Should deny service env entries with both value and valueFrom", func() {
+			obj.Spec.Services[0].Env = []corev1.EnvVar{{
+				Name:  "API_TOKEN",
+				Value: "plain",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "shop-api"}, Key: "token",
+				}},
+			}}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.services[0].env[0].valueFrom")))
+		})
+
+		It("Should deny duplicate migration versions and migrations without a script", func() {
+			obj.Spec.Databases[0].Migrations = []appsv1alpha1.MigrationSpec{
+				{Version: "001", SQL: "CREATE TABLE items (id serial PRIMARY KEY);"},
+				{Version: "001", SQL: "ALTER TABLE items ADD name text;"},
+				{Version: "002"},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.databases[0].migrations[1].version: Duplicate value")))
+			Expect(err).To(MatchError(ContainSubstring("spec.databases[0].migrations[2]: Required value")))
+		})
+
+		It("Should deny dependencies on unknown components", func() {
+			obj.Spec.Services[0].DependsOn = []string{"db", "queue"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.services[0].dependsOn[1]: Not found")))
+		})
+
+		It("Should deny dependency cycles between services", func() {
+			obj.Spec.Services[0].DependsOn = []string{"worker"}
+			obj.Spec.Services = append(obj.Spec.Services, appsv1alpha1.ServiceSpec{
+				Name: "worker", Image: "shop/worker:v1", Version: "v1", Port: 9090, DependsOn: []string{"db", "api"},
+			})
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("dependency cycle: api -> worker -> api")))
+		})
+
+		It("Should deny volume mounts without exactly one source", func() {
+			obj.Spec.Services[0].VolumeMounts = []appsv1alpha1.VolumeMountSpec{{
+				Name:      "config",
+				MountPath: "/etc/api",
+			}}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.services[0].volumeMounts[0]")))
+		})
+
+		It("Should deny changing the storage size of a database", func() {
+			obj.Spec.Databases[0].Storage = &appsv1alpha1.DatabaseStorageSpec{Size: resource.MustParse("1Gi")}
+			updated := obj.DeepCopy()
+			updated.Spec.Databases[0].Storage.Size = resource.MustParse("2Gi")
+			_, err := validator.ValidateUpdate(ctx, obj, updated)
+			Expect(err).To(MatchError(ContainSubstring("spec.databases[0].storage.size: Forbidden")))
+		})
+
+		It("Should deny a template reference without a name", func() {
+			obj.Spec.Template = &appsv1alpha1.AppTemplateRef{Parameters: map[string]string{"version": "v2"}}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.template.name: Required value")))
+		})
+
+		It("Should deny a canary weight on a blue/green service", func() {
+			weight := int32(30)
+			obj.Spec.Services[0].Strategy = &appsv1alpha1.RolloutStrategy{
+				Type:         appsv1alpha1.RolloutStrategyBlueGreen,
+				CanaryWeight: &weight,
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.services[0].strategy.canaryWeight: Forbidden")))
+		})
+
+		It("Should admit a redis database without credentials", func() {
+			obj.Spec.Databases[0].Engine = appsv1alpha1.DatabaseEngineRedis
+			obj.Spec.Databases[0].Credentials = nil
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny a container service without an image", func() {
+			obj.Spec.Services[0].Image = ""
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.services[0].image: Required value")))
+		})
+
+		It("Should admit a mock service without an image", func() {
+			obj.Spec.Services[0].Image = ""
+			obj.Spec.Services[0].Kind = appsv1alpha1.ServiceKindMock
+			obj.Spec.Services[0].Mock = &appsv1alpha1.MockSpec{Stubs: []appsv1alpha1.StubSpec{{
+				Request:  appsv1alpha1.StubRequest{PathPattern: "^/users/[0-9]+$"},
+				Response: appsv1alpha1.StubResponse{Status: 200},
+			}}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny a mock stub with an invalid path pattern", func() {
+			obj.Spec.Services[0].Kind = appsv1alpha1.ServiceKindMock
+			obj.Spec.Services[0].Mock = &appsv1alpha1.MockSpec{Stubs: []appsv1alpha1.StubSpec{{
+				Request: appsv1alpha1.StubRequest{PathPattern: "/users/("},
+			}}}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.services[0].mock.stubs[0].request.pathPattern")))
+		})
+
+		It("Should deny fault injection on a service listening on a proxy port", func() {
+			obj.Spec.Services[0].Port = appsv1alpha1.FaultProxyHTTPPort
+			obj.Spec.Services[0].FaultInjection = &appsv1alpha1.FaultInjectionSpec{}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.services[0].port")))
+		})
+	})
+})
@@ -0,0 +1,85 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	appsv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// log is for logging in this package.
+var crontestrunlog = logf.Log.WithName("crontestrun-resource")
+
+// SetupCronTestRunWebhookWithManager registers the webhook for CronTestRun in the manager.
+func SetupCronTestRunWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &appsv1alpha1.CronTestRun{}).
+		WithValidator(&CronTestRunCustomValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-apps-example-com-v1alpha1-crontestrun,mutating=false,failurePolicy=fail,sideEffects=None,groups=apps.example.com,resources=crontestruns,verbs=create;update,versions=v1alpha1,name=vcrontestrun-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// CronTestRunCustomValidator validates the CronTestRun resource when it is created or updated, so that
+// a template the TestRun webhook would reject is refused up front rather than on every schedule.
+type CronTestRunCustomValidator struct{}
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the type CronTestRun.
+func (v *CronTestRunCustomValidator) ValidateCreate(_ context.Context, cronRun *appsv1alpha1.CronTestRun) (admission.Warnings, error) {
+	crontestrunlog.Info("Validation for CronTestRun upon creation", "name", cronRun.GetName())
+	return nil, validateCronTestRun(cronRun)
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the type CronTestRun.
+func (v *CronTestRunCustomValidator) ValidateUpdate(_ context.Context, _, cronRun *appsv1alpha1.CronTestRun) (admission.Warnings, error) {
+	crontestrunlog.Info("Validation for CronTestRun upon update", "name", cronRun.GetName())
+	return nil, validateCronTestRun(cronRun)
+}
+
+// ValidateDelete implements admission.Validator so a webhook will be registered for the type CronTestRun.
+func (v *CronTestRunCustomValidator) ValidateDelete(_ context.Context, _ *appsv1alpha1.CronTestRun) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// maxCronTestRunNameLength leaves room for the "-<minutes since the epoch>" the controller appends to name
+// a TestRun, whose name must fit the 63 characters of the label value it is given on the runner pod.
+const maxCronTestRunNameLength = 52
+
+// validateCronTestRun returns an Invalid error listing the problems of the name, the schedule and the
+// TestRun template, or nil.
+func validateCronTestRun(cronRun *appsv1alpha1.CronTestRun) error {
+	specPath := field.NewPath("spec")
+	var allErrs field.ErrorList
+	if len(cronRun.Name) > maxCronTestRunNameLength {
+		allErrs = append(allErrs, field.TooLong(field.NewPath("metadata", "name"), cronRun.Name, maxCronTestRunNameLength))
+	}
+	if _, err := cron.ParseStandard(cronRun.Spec.Schedule); err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("schedule"), cronRun.Spec.Schedule, err.Error()))
+	}
+	allErrs = append(allErrs, validateTestRunSpec(&cronRun.Spec.Template, specPath.Child("template"))...)
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(appsv1alpha1.GroupVersion.WithKind("CronTestRun").GroupKind(), cronRun.Name, allErrs)
+}
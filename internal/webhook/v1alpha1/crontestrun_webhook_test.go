@@ -0,0 +1,72 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+var _ = Describe("CronTestRun Webhook", func() {
+	var (
+		obj       *appsv1alpha1.CronTestRun
+		validator CronTestRunCustomValidator
+	)
+
+	BeforeEach(func() {
+		obj = &appsv1alpha1.CronTestRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"},
+			Spec: appsv1alpha1.CronTestRunSpec{
+				Schedule: "0 2 * * *",
+				Template: appsv1alpha1.TestRunSpec{
+					AppName: "shop",
+					Script:  "print(\"ok\")\n",
+				},
+			},
+		}
+		validator = CronTestRunCustomValidator{}
+	})
+
+	Context("When creating or updating CronTestRun under Validating Webhook", func() {
+		It("Should admit a valid CronTestRun", func() {
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny a name too long for the names of its TestRuns", func() {
+			obj.Name = strings.Repeat("n", 53)
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("metadata.name")))
+		})
+
+		It("Should deny an unparseable schedule", func() {
+			obj.Spec.Schedule = "every night"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.schedule")))
+		})
+
+		It("Should deny a template the TestRun webhook would reject", func() {
+			obj.Spec.Template.Script = "if true then print('unterminated')"
+			_, err := validator.ValidateUpdate(ctx, obj, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.template.script")))
+		})
+	})
+})
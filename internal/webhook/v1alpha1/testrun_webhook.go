@@ -0,0 +1,209 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/yuin/gopher-lua/parse"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	appsv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// log is for logging in this package.
+var testrunlog = logf.Log.WithName("testrun-resource")
+
+// SetupTestRunWebhookWithManager registers the webhook for TestRun in the manager.
+func SetupTestRunWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &appsv1alpha1.TestRun{}).
+		WithValidator(&TestRunCustomValidator{}).
+		WithDefaulter(&TestRunCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-apps-example-com-v1alpha1-testrun,mutating=true,failurePolicy=fail,sideEffects=None,groups=apps.example.com,resources=testruns,verbs=create;update,versions=v1alpha1,name=mtestrun-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// TestRunCustomDefaulter sets default values on the TestRun resource when it is created or updated.
+type TestRunCustomDefaulter struct{}
+
+// Default implements admission.Defaulter so a webhook will be registered for the type TestRun.
+func (d *TestRunCustomDefaulter) Default(_ context.Context, run *appsv1alpha1.TestRun) error {
+	testrunlog.Info("Defaulting for TestRun", "name", run.GetName())
+
+	if run.Spec.Timeout == "" {
+		run.Spec.Timeout = "60s"
+	}
+	if b := run.Spec.Backoff; b != nil {
+		if b.Policy == "" {
+			b.Policy = appsv1alpha1.BackoffExponential
+		}
+		if b.Delay == "" {
+			b.Delay = "10s"
+		}
+		if b.MaxDelay == "" {
+			b.MaxDelay = "5m"
+		}
+	}
+	if run.Spec.AppAccess == "" {
+		run.Spec.AppAccess = appsv1alpha1.AppAccessShared
+	}
+	if run.Spec.Environment != nil && run.Spec.Environment.Isolation == "" {
+		run.Spec.Environment.Isolation = appsv1alpha1.IsolationNamespace
+	}
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-apps-example-com-v1alpha1-testrun,mutating=false,failurePolicy=fail,sideEffects=None,groups=apps.example.com,resources=testruns,verbs=create;update,versions=v1alpha1,name=vtestrun-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// TestRunCustomValidator validates the TestRun resource when it is created or updated.
+type TestRunCustomValidator struct{}
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the type TestRun.
+func (v *TestRunCustomValidator) ValidateCreate(_ context.Context, run *appsv1alpha1.TestRun) (admission.Warnings, error) {
+	testrunlog.Info("Validation for TestRun upon creation", "name", run.GetName())
+	return nil, validateTestRun(run)
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the type TestRun.
+func (v *TestRunCustomValidator) ValidateUpdate(_ context.Context, _, run *appsv1alpha1.TestRun) (admission.Warnings, error) {
+	testrunlog.Info("Validation for TestRun upon update", "name", run.GetName())
+	return nil, validateTestRun(run)
+}
+
+// ValidateDelete implements admission.Validator so a webhook will be registered for the type TestRun.
+func (v *TestRunCustomValidator) ValidateDelete(_ context.Context, _ *appsv1alpha1.TestRun) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateTestRun returns an Invalid error listing every problem found in the TestRun spec, or nil.
+func validateTestRun(run *appsv1alpha1.TestRun) error {
+	allErrs := validateTestRunSpec(&run.Spec, field.NewPath("spec"))
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(appsv1alpha1.GroupVersion.WithKind("TestRun").GroupKind(), run.Name, allErrs)
+}
+
+// validateTestRunSpec lists the problems found in a TestRun spec found at specPath.
+func validateTestRunSpec(spec *appsv1alpha1.TestRunSpec, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.AppName == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("appName"), "target App is required"))
+	}
+
+	// Exactly one script source
+	switch {
+	case spec.Script != "" && spec.Git != nil:
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("git"), "script and git are mutually exclusive"))
+	case spec.Script == "" && spec.Git == nil:
+		allErrs = append(allErrs, field.Required(specPath, "one of script or git must be set"))
+	}
+
+	if spec.Script != "" {
+		if _, err := parse.Parse(strings.NewReader(spec.Script), "<script>"); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("script"), field.OmitValueType{}, "lua syntax error: "+strings.TrimSpace(err.Error())))
+		}
+	}
+
+	if spec.Git != nil {
+		gitPath := specPath.Child("git")
+		if spec.Git.URL == "" {
+			allErrs = append(allErrs, field.Required(gitPath.Child("url"), "git repository URL is required"))
+		}
+		if spec.Git.Path == "" {
+			allErrs = append(allErrs, field.Required(gitPath.Child("path"), "script path within the repository is required"))
+		}
+	}
+
+	if spec.Timeout != "" {
+		d, err := time.ParseDuration(spec.Timeout)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("timeout"), spec.Timeout, "must be a duration such as 90s or 5m"))
+		} else if d <= 0 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("timeout"), spec.Timeout, "must be greater than zero"))
+		}
+	}
+
+	switch spec.AppAccess {
+	case "", appsv1alpha1.AppAccessShared, appsv1alpha1.AppAccessExclusive:
+	default:
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("appAccess"), spec.AppAccess, []string{
+			appsv1alpha1.AppAccessShared, appsv1alpha1.AppAccessExclusive,
+		}))
+	}
+
+	if spec.Retries < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("retries"), spec.Retries, "must not be negative"))
+	}
+	if b := spec.Backoff; b != nil {
+		backoffPath := specPath.Child("backoff")
+		switch b.Policy {
+		case "", appsv1alpha1.BackoffFixed, appsv1alpha1.BackoffExponential:
+		default:
+			allErrs = append(allErrs, field.NotSupported(backoffPath.Child("policy"), b.Policy, []string{
+				appsv1alpha1.BackoffFixed, appsv1alpha1.BackoffExponential,
+			}))
+		}
+		for _, f := range []struct{ name, value string }{{"delay", b.Delay}, {"maxDelay", b.MaxDelay}} {
+			if f.value == "" {
+				continue
+			}
+			if d, err := time.ParseDuration(f.value); err != nil || d < 0 {
+				allErrs = append(allErrs, field.Invalid(backoffPath.Child(f.name), f.value, "must be a duration such as 10s or 5m"))
+			}
+		}
+	}
+
+	// The runner takes each param as key=value
+	for key := range spec.Params {
+		if key == "" || strings.Contains(key, "=") {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("params"), key, "keys must be non-empty and may not contain ="))
+		}
+	}
+	// Each Secret is mounted in a directory of its own name
+	secretNames := map[string]bool{}
+	for i, ref := range spec.SecretRefs {
+		refPath := specPath.Child("secretRefs").Index(i)
+		switch {
+		case ref.Name == "":
+			allErrs = append(allErrs, field.Required(refPath.Child("name"), "Secret name is required"))
+		case secretNames[ref.Name]:
+			allErrs = append(allErrs, field.Duplicate(refPath.Child("name"), ref.Name))
+		}
+		secretNames[ref.Name] = true
+	}
+
+	if env := spec.Environment; env != nil {
+		switch env.Isolation {
+		case "", appsv1alpha1.IsolationNamespace, appsv1alpha1.IsolationSuffix:
+		default:
+			allErrs = append(allErrs, field.NotSupported(specPath.Child("environment", "isolation"), env.Isolation, []string{
+				appsv1alpha1.IsolationNamespace, appsv1alpha1.IsolationSuffix,
+			}))
+		}
+	}
+
+	return allErrs
+}
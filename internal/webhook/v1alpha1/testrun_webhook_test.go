@@ -0,0 +1,125 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+var _ = Describe("TestRun Webhook", func() {
+	var (
+		obj       *appsv1alpha1.TestRun
+		validator TestRunCustomValidator
+		defaulter TestRunCustomDefaulter
+	)
+
+	BeforeEach(func() {
+		obj = &appsv1alpha1.TestRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "smoke", Namespace: "default"},
+			Spec: appsv1alpha1.TestRunSpec{
+				AppName: "shop",
+				Script:  "local http = require(\"http\")\nprint(\"ok\")\n",
+				Timeout: "60s",
+			},
+		}
+		validator = TestRunCustomValidator{}
+		defaulter = TestRunCustomDefaulter{}
+	})
+
+	Context("When creating TestRun under Defaulting Webhook", func() {
+		It("Should default the timeout and leave the git revision to the repository's default branch", func() {
+			obj.Spec.Timeout = ""
+			obj.Spec.Script = ""
+			obj.Spec.Git = &appsv1alpha1.GitSource{URL: "https://example.com/tests.git", Path: "smoke.lua"}
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Timeout).To(Equal("60s"))
+			Expect(obj.Spec.Git.Revision).To(BeEmpty())
+		})
+
+		It("Should default the retry backoff to an exponential one", func() {
+			obj.Spec.Backoff = &appsv1alpha1.RetryBackoff{Delay: "1s"}
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Backoff.Policy).To(Equal(appsv1alpha1.BackoffExponential))
+			Expect(obj.Spec.Backoff.Delay).To(Equal("1s"))
+			Expect(obj.Spec.Backoff.MaxDelay).To(Equal("5m"))
+		})
+
+		It("Should default App access to Shared", func() {
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.AppAccess).To(Equal(appsv1alpha1.AppAccessShared))
+		})
+
+		It("Should default environment isolation to a fresh namespace", func() {
+			obj.Spec.Environment = &appsv1alpha1.EnvironmentSpec{}
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Environment.Isolation).To(Equal(appsv1alpha1.IsolationNamespace))
+		})
+	})
+
+	Context("When creating or updating TestRun under Validating Webhook", func() {
+		It("Should admit a valid TestRun", func() {
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny setting both script and git", func() {
+			obj.Spec.Git = &appsv1alpha1.GitSource{URL: "https://example.com/tests.git", Path: "smoke.lua"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("script and git are mutually exclusive")))
+		})
+
+		It("Should deny setting neither script nor git", func() {
+			obj.Spec.Script = ""
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("one of script or git must be set")))
+		})
+
+		It("Should deny an unparseable timeout", func() {
+			obj.Spec.Timeout = "soon"
+			_, err := validator.ValidateUpdate(ctx, obj, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.timeout")))
+		})
+
+		It("Should deny an unknown environment isolation mode", func() {
+			obj.Spec.Environment = &appsv1alpha1.EnvironmentSpec{Isolation: "Cluster"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.environment.isolation")))
+		})
+
+		It("Should deny a retry backoff with an unparseable delay", func() {
+			obj.Spec.Retries = 2
+			obj.Spec.Backoff = &appsv1alpha1.RetryBackoff{Policy: appsv1alpha1.BackoffFixed, Delay: "a while"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.backoff.delay")))
+		})
+
+		It("Should deny referencing the same Secret twice", func() {
+			obj.Spec.SecretRefs = []appsv1alpha1.SecretRef{{Name: "db-credentials"}, {Name: "db-credentials", Keys: []string{"password"}}}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.secretRefs[1].name")))
+		})
+
+		It("Should deny an inline script with a Lua syntax error", func() {
+			obj.Spec.Script = "if true then print('unterminated')"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("lua syntax error")))
+		})
+	})
+})
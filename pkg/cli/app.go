@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+	"github.com/chakradharkondapalli/topas/pkg/k8s"
+)
+
+// appCmd represents the app command
+var appCmd = &cobra.Command{
+	Use:   "app",
+	Short: "Inspect Apps under test",
+}
+
+var appStatusCmd = &cobra.Command{
+	Use:   "status <app-name>",
+	Short: "Show the health and running versions of an App",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := k8s.NewClient()
+		if err != nil {
+			fmt.Printf("Error creating client: %v\n", err)
+			os.Exit(1)
+		}
+
+		app := &appv1alpha1.App{}
+		if err := client.Get(context.Background(), types.NamespacedName{Name: args[0], Namespace: namespace}, app); err != nil {
+			fmt.Printf("Error getting App: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Name:       %s\n", app.Name)
+		fmt.Printf("Namespace:  %s\n", app.Namespace)
+		fmt.Printf("Health:     %s\n", app.Status.Health)
+		if app.Status.LastChecked != nil {
+			fmt.Printf("Checked:    %s\n", app.Status.LastChecked.Format("2006-01-02 15:04:05"))
+		}
+		fmt.Println()
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "COMPONENT\tKIND\tPHASE\tREADY\tVERSION\tRUNNING\tMESSAGE")
+		for _, c := range app.Status.Components {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d/%d\t%s\t%s\t%s\n",
+				c.Name, c.Kind, c.Phase, c.ReadyReplicas, c.DesiredReplicas,
+				orDash(c.Version), orDash(c.ObservedVersion), c.Message)
+		}
+		w.Flush()
+	},
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	rootCmd.AddCommand(appCmd)
+	appCmd.AddCommand(appStatusCmd)
+
+	appStatusCmd.Flags().StringVar(&namespace, "namespace", "default", "Namespace of the App")
+}
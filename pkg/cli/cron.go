@@ -0,0 +1,225 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+	"github.com/chakradharkondapalli/topas/pkg/k8s"
+)
+
+var (
+	cronSchedule      string
+	cronConcurrency   string
+	cronDeadline      int64
+	cronSuccessLimit  int32
+	cronFailedLimit   int32
+	cronTags          []string
+	cronTimeout       string
+	cronSuspendCreate bool
+)
+
+// cronCmd groups the commands managing CronTestRuns
+var cronCmd = &cobra.Command{
+	Use:   "cron",
+	Short: "Manage test runs scheduled on a cron schedule",
+	Long:  `Create, list, suspend, resume and delete CronTestRuns, which start a test run on a cron schedule.`,
+}
+
+var cronCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a CronTestRun",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if scriptPath == "" && gitURL == "" {
+			fmt.Println("Error: --script or --git is required")
+			os.Exit(1)
+		}
+		if appName == "" {
+			fmt.Println("Error: --app is required")
+			os.Exit(1)
+		}
+		if cronSchedule == "" {
+			fmt.Println("Error: --schedule is required")
+			os.Exit(1)
+		}
+		if _, err := cron.ParseStandard(cronSchedule); err != nil {
+			fmt.Printf("Error: invalid --schedule %q: %v\n", cronSchedule, err)
+			os.Exit(1)
+		}
+		var policy string
+		switch strings.ToLower(cronConcurrency) {
+		case "allow":
+			policy = appv1alpha1.ConcurrencyAllow
+		case "forbid":
+			policy = appv1alpha1.ConcurrencyForbid
+		case "replace":
+			policy = appv1alpha1.ConcurrencyReplace
+		default:
+			fmt.Printf("Error: --concurrency must be allow, forbid or replace, got %q\n", cronConcurrency)
+			os.Exit(1)
+		}
+
+		cronRun := &appv1alpha1.CronTestRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      args[0],
+				Namespace: namespace,
+			},
+			Spec: appv1alpha1.CronTestRunSpec{
+				Schedule:                   cronSchedule,
+				ConcurrencyPolicy:          policy,
+				Suspend:                    cronSuspendCreate,
+				SuccessfulRunsHistoryLimit: &cronSuccessLimit,
+				FailedRunsHistoryLimit:     &cronFailedLimit,
+				Template: appv1alpha1.TestRunSpec{
+					AppName:     appName,
+					Timeout:     cronTimeout,
+					Environment: environmentSpec(),
+					Tags:        cronTags,
+					Retries:     retries,
+					AppAccess:   appAccess(),
+				},
+			},
+		}
+		if cmd.Flags().Changed("starting-deadline") {
+			cronRun.Spec.StartingDeadlineSeconds = &cronDeadline
+		}
+		setScriptSource(&cronRun.Spec.Template)
+
+		k8sClient, err := k8s.NewClient()
+		if err != nil {
+			fmt.Printf("Failed to create client: %v\n", err)
+			os.Exit(1)
+		}
+		if err := k8sClient.Create(context.Background(), cronRun); err != nil {
+			fmt.Printf("Failed to create CronTestRun: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("CronTestRun created: %s/%s (%s)\n", namespace, cronRun.Name, cronSchedule)
+	},
+}
+
+var cronListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the CronTestRuns of a namespace",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		k8sClient, err := k8s.NewClient()
+		if err != nil {
+			fmt.Printf("Error creating client: %v\n", err)
+			os.Exit(1)
+		}
+		var list appv1alpha1.CronTestRunList
+		if err := k8sClient.List(context.Background(), &list, client.InNamespace(namespace)); err != nil {
+			fmt.Printf("Error listing CronTestRuns: %v\n", err)
+			os.Exit(1)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSCHEDULE\tSUSPEND\tACTIVE\tLAST SCHEDULE\tLAST RUN\tLAST STATE")
+		for _, c := range list.Items {
+			lastSchedule := "-"
+			if c.Status.LastScheduleTime != nil {
+				lastSchedule = time.Since(c.Status.LastScheduleTime.Time).Round(time.Second).String() + " ago"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%t\t%d\t%s\t%s\t%s\n", c.Name, c.Spec.Schedule, c.Spec.Suspend, len(c.Status.Active),
+				lastSchedule, orDash(c.Status.LastRun), orDash(c.Status.LastState))
+		}
+		w.Flush()
+	},
+}
+
+var cronSuspendCmd = &cobra.Command{
+	Use:   "suspend <name>",
+	Short: "Stop a CronTestRun from starting test runs",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setCronSuspend(args[0], true)
+		fmt.Printf("CronTestRun %s suspended\n", args[0])
+	},
+}
+
+var cronResumeCmd = &cobra.Command{
+	Use:   "resume <name>",
+	Short: "Let a suspended CronTestRun start test runs again",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setCronSuspend(args[0], false)
+		fmt.Printf("CronTestRun %s resumed\n", args[0])
+	},
+}
+
+var cronDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a CronTestRun and the test runs it started",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		k8sClient, err := k8s.NewClient()
+		if err != nil {
+			fmt.Printf("Error creating client: %v\n", err)
+			os.Exit(1)
+		}
+		cronRun := &appv1alpha1.CronTestRun{ObjectMeta: metav1.ObjectMeta{Name: args[0], Namespace: namespace}}
+		if err := k8sClient.Delete(context.Background(), cronRun, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+			fmt.Printf("Error deleting CronTestRun: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("CronTestRun %s deleted\n", args[0])
+	},
+}
+
+// setCronSuspend patches spec.suspend of a CronTestRun.
+func setCronSuspend(name string, suspend bool) {
+	k8sClient, err := k8s.NewClient()
+	if err != nil {
+		fmt.Printf("Error creating client: %v\n", err)
+		os.Exit(1)
+	}
+	ctx := context.Background()
+	cronRun := &appv1alpha1.CronTestRun{}
+	if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, cronRun); err != nil {
+		fmt.Printf("Error getting CronTestRun: %v\n", err)
+		os.Exit(1)
+	}
+	patch := client.MergeFrom(cronRun.DeepCopy())
+	cronRun.Spec.Suspend = suspend
+	if err := k8sClient.Patch(ctx, cronRun, patch); err != nil {
+		fmt.Printf("Error updating CronTestRun: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	testCmd.AddCommand(cronCmd)
+	cronCmd.AddCommand(cronCreateCmd, cronListCmd, cronSuspendCmd, cronResumeCmd, cronDeleteCmd)
+
+	cronCmd.PersistentFlags().StringVar(&namespace, "namespace", "default", "Namespace of the CronTestRuns")
+
+	f := cronCreateCmd.Flags()
+	f.StringVar(&cronSchedule, "schedule", "", `Cron schedule, such as "0 * * * *" or "@hourly"`)
+	f.StringVar(&cronConcurrency, "concurrency", "allow", "When a run is due while one still runs: allow, forbid or replace")
+	f.Int64Var(&cronDeadline, "starting-deadline", 0, "Seconds a missed run may still start late")
+	f.Int32Var(&cronSuccessLimit, "successful-history", 3, "Number of passed test runs to keep")
+	f.Int32Var(&cronFailedLimit, "failed-history", 1, "Number of failed test runs to keep")
+	f.BoolVar(&cronSuspendCreate, "suspend", false, "Create the CronTestRun suspended")
+	f.StringVar(&scriptPath, "script", "", "Path to local Lua script")
+	f.StringVar(&gitURL, "git", "", "Git repository URL")
+	f.StringVar(&gitPath, "git-path", "", "Path within git repo")
+	f.StringVar(&appName, "app", "", "Target App name")
+	f.StringVar(&cronTimeout, "timeout", "60s", "Timeout of each test run")
+	f.StringSliceVar(&cronTags, "tags", nil, "Run only the test cases tagged with one of these #tags")
+	f.StringVar(&isolation, "isolate", "", "Run against a clone of the App: namespace or suffix")
+	f.BoolVar(&keepOnFailure, "keep-on-failure", false, "Keep the App clone when the run does not pass")
+	f.Int32Var(&retries, "retries", 0, "Run a failed test again up to this many times")
+	f.BoolVar(&exclusive, "exclusive", false, "Hold the App alone so the script may change it with sut.apply")
+}
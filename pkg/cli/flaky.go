@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+	"github.com/chakradharkondapalli/topas/pkg/k8s"
+)
+
+var (
+	flakyApp   string
+	flakyCases bool
+)
+
+// scriptHistory tallies the finished runs of one script
+type scriptHistory struct {
+	script              string
+	runs, passed, flaky int
+	failed              int
+	lastFlaky           time.Time
+	cases               map[string]int
+	casesLast           map[string]time.Time
+}
+
+var flakyCmd = &cobra.Command{
+	Use:   "flaky",
+	Short: "Show how often the scripts of finished test runs needed a retry to pass",
+	Long: `Groups the finished test runs of a namespace by script and counts those that passed, passed only
+on a retry (Flaky) and failed. With --cases, lists the test cases that failed in an attempt of a
+run that passed later, the candidates for quarantine.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		k8sClient, err := k8s.NewClient()
+		if err != nil {
+			fmt.Printf("Error creating client: %v\n", err)
+			os.Exit(1)
+		}
+		var runs appv1alpha1.TestRunList
+		if err := k8sClient.List(context.Background(), &runs, client.InNamespace(namespace)); err != nil {
+			fmt.Printf("Error listing TestRuns: %v\n", err)
+			os.Exit(1)
+		}
+
+		histories := map[string]*scriptHistory{}
+		for _, run := range runs.Items {
+			if flakyApp != "" && run.Spec.AppName != flakyApp {
+				continue
+			}
+			switch run.Status.State {
+			case "Passed", "Flaky", "Failed":
+			default:
+				continue
+			}
+			key := scriptKey(&run)
+			h := histories[key]
+			if h == nil {
+				h = &scriptHistory{script: key, cases: map[string]int{}, casesLast: map[string]time.Time{}}
+				histories[key] = h
+			}
+			h.runs++
+			switch run.Status.State {
+			case "Passed":
+				h.passed++
+				continue
+			case "Failed":
+				h.failed++
+				continue
+			}
+			h.flaky++
+			finished := run.CreationTimestamp.Time
+			if run.Status.CompletionTime != nil {
+				finished = run.Status.CompletionTime.Time
+			}
+			if finished.After(h.lastFlaky) {
+				h.lastFlaky = finished
+			}
+			for _, name := range flakyTestCases(run.Status.Attempts) {
+				h.cases[name]++
+				if finished.After(h.casesLast[name]) {
+					h.casesLast[name] = finished
+				}
+			}
+		}
+		if len(histories) == 0 {
+			fmt.Printf("No finished TestRuns in namespace %s\n", namespace)
+			return
+		}
+
+		// Flakiest scripts first
+		list := make([]*scriptHistory, 0, len(histories))
+		for _, h := range histories {
+			list = append(list, h)
+		}
+		sort.Slice(list, func(i, j int) bool {
+			ri, rj := float64(list[i].flaky)/float64(list[i].runs), float64(list[j].flaky)/float64(list[j].runs)
+			if ri != rj {
+				return ri > rj
+			}
+			return list[i].script < list[j].script
+		})
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SCRIPT\tRUNS\tPASSED\tFLAKY\tFAILED\tFLAKY RATE\tLAST FLAKY")
+		for _, h := range list {
+			last := "-"
+			if !h.lastFlaky.IsZero() {
+				last = h.lastFlaky.Format("2006-01-02 15:04")
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%.0f%%\t%s\n", h.script, h.runs, h.passed, h.flaky, h.failed,
+				100*float64(h.flaky)/float64(h.runs), last)
+		}
+		w.Flush()
+
+		if !flakyCases {
+			return
+		}
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TEST CASE\tSCRIPT\tFLAKY RUNS\tLAST FLAKY")
+		for _, h := range list {
+			names := make([]string, 0, len(h.cases))
+			for name := range h.cases {
+				names = append(names, name)
+			}
+			sort.Slice(names, func(i, j int) bool {
+				if h.cases[names[i]] != h.cases[names[j]] {
+					return h.cases[names[i]] > h.cases[names[j]]
+				}
+				return names[i] < names[j]
+			})
+			for _, name := range names {
+				fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", name, h.script, h.cases[name], h.casesLast[name].Format("2006-01-02 15:04"))
+			}
+		}
+		w.Flush()
+	},
+}
+
+// scriptKey identifies the script of a run: the git repository and path it comes from, or a hash of
+// the inline script.
+func scriptKey(run *appv1alpha1.TestRun) string {
+	if run.Spec.Script == "" && run.Spec.Git != nil {
+		return run.Spec.Git.URL + "//" + run.Spec.Git.Path
+	}
+	sum := sha256.Sum256([]byte(run.Spec.Script))
+	return "inline:" + hex.EncodeToString(sum[:])[:12]
+}
+
+// flakyTestCases returns the test cases that failed in an attempt of a run but not in its last one.
+func flakyTestCases(attempts []appv1alpha1.TestRunAttempt) []string {
+	if len(attempts) < 2 {
+		return nil
+	}
+	last := attempts[len(attempts)-1].FailedTestCases
+	var names []string
+	for _, attempt := range attempts[:len(attempts)-1] {
+		for _, name := range attempt.FailedTestCases {
+			if !slices.Contains(last, name) && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+func init() {
+	testCmd.AddCommand(flakyCmd)
+
+	flakyCmd.Flags().StringVar(&namespace, "namespace", "default", "Namespace of the TestRuns")
+	flakyCmd.Flags().StringVar(&flakyApp, "app", "", "Only count the TestRuns of this App")
+	flakyCmd.Flags().BoolVar(&flakyCases, "cases", false, "List the test cases that failed before passing on a retry")
+}
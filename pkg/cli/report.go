@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+	"github.com/chakradharkondapalli/topas/pkg/k8s"
+	"github.com/chakradharkondapalli/topas/pkg/report"
+)
+
+var (
+	reportFormat string
+	reportOutput string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report <test-run-name>",
+	Short: "Print the report of a finished test run as JUnit XML, JSON or HTML",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runName := args[0]
+		if reportFormat != "junit" && reportFormat != "json" && reportFormat != "html" {
+			fmt.Printf("Error: unknown format %q, expected junit, json or html\n", reportFormat)
+			os.Exit(1)
+		}
+
+		client, err := k8s.NewClient()
+		if err != nil {
+			fmt.Printf("Error creating client: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 1. Find the report ConfigMap of the run
+		ctx := context.Background()
+		testRun := &appv1alpha1.TestRun{}
+		if err := client.Get(ctx, types.NamespacedName{Name: runName, Namespace: namespace}, testRun); err != nil {
+			fmt.Printf("Error getting TestRun: %v\n", err)
+			os.Exit(1)
+		}
+		if testRun.Status.ReportConfigMap == "" {
+			fmt.Printf("TestRun %s has no report yet (state %s)\n", runName, testRun.Status.State)
+			os.Exit(1)
+		}
+		cm := &corev1.ConfigMap{}
+		if err := client.Get(ctx, types.NamespacedName{Name: testRun.Status.ReportConfigMap, Namespace: namespace}, cm); err != nil {
+			fmt.Printf("Error getting report ConfigMap: %v\n", err)
+			os.Exit(1)
+		}
+		raw, ok := cm.Data[appv1alpha1.ReportJSONKey]
+		if !ok {
+			fmt.Printf("TestRun %s has no report yet (state %s)\n", runName, testRun.Status.State)
+			os.Exit(1)
+		}
+
+		// 2. Render it in the requested format
+		var out []byte
+		switch reportFormat {
+		case "junit":
+			out = []byte(cm.Data[appv1alpha1.ReportJUnitKey])
+		case "json":
+			out = []byte(raw)
+		case "html":
+			var rep report.Report
+			if err := json.Unmarshal([]byte(raw), &rep); err != nil {
+				fmt.Printf("Error parsing report: %v\n", err)
+				os.Exit(1)
+			}
+			if out, err = report.HTML(rep); err != nil {
+				fmt.Printf("Error rendering report: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		if reportOutput == "" {
+			os.Stdout.Write(out)
+			return
+		}
+		if err := os.WriteFile(reportOutput, out, 0o644); err != nil {
+			fmt.Printf("Error writing report: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Report written to %s\n", reportOutput)
+	},
+}
+
+func init() {
+	testCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().StringVar(&reportFormat, "format", "junit", "Report format: junit, json or html")
+	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "", "Write the report to this file instead of stdout")
+	reportCmd.Flags().StringVar(&namespace, "namespace", "default", "Namespace of the TestRun")
+}
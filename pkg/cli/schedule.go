@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,6 +19,12 @@ var (
 	gitPath    string
 	appName    string
 	namespace  string
+
+	isolation     string
+	keepOnFailure bool
+	retries       int32
+	exclusive     bool
+	params        []string
 )
 
 var scheduleCmd = &cobra.Command{
@@ -33,6 +40,9 @@ var scheduleCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		environment := environmentSpec()
+		runParams := paramsSpec()
+
 		// 1. Initialize Client
 		k8sClient, err := k8s.NewClient()
 		if err != nil {
@@ -48,23 +58,14 @@ var scheduleCmd = &cobra.Command{
 				Namespace: namespace,
 			},
 			Spec: appv1alpha1.TestRunSpec{
-				AppName: appName,
+				AppName:     appName,
+				Environment: environment,
+				Retries:     retries,
+				AppAccess:   appAccess(),
+				Params:      runParams,
 			},
 		}
-
-		if scriptPath != "" {
-			content, err := os.ReadFile(scriptPath)
-			if err != nil {
-				fmt.Printf("Failed to read script: %v\n", err)
-				os.Exit(1)
-			}
-			testRun.Spec.Script = string(content)
-		} else {
-			testRun.Spec.Git = &appv1alpha1.GitSource{
-				URL:  gitURL,
-				Path: gitPath,
-			}
-		}
+		setScriptSource(&testRun.Spec)
 
 		// 3. Create Resource
 		if err := k8sClient.Create(context.Background(), testRun); err != nil {
@@ -76,6 +77,67 @@ var scheduleCmd = &cobra.Command{
 	},
 }
 
+// environmentSpec builds the App clone asked for by --isolate and --keep-on-failure.
+func environmentSpec() *appv1alpha1.EnvironmentSpec {
+	switch strings.ToLower(isolation) {
+	case "":
+		if keepOnFailure {
+			fmt.Println("Error: --keep-on-failure requires --isolate")
+			os.Exit(1)
+		}
+		return nil
+	case "namespace":
+		return &appv1alpha1.EnvironmentSpec{Isolation: appv1alpha1.IsolationNamespace, KeepOnFailure: keepOnFailure}
+	case "suffix":
+		return &appv1alpha1.EnvironmentSpec{Isolation: appv1alpha1.IsolationSuffix, KeepOnFailure: keepOnFailure}
+	}
+	fmt.Printf("Error: --isolate must be namespace or suffix, got %q\n", isolation)
+	os.Exit(1)
+	return nil
+}
+
+// appAccess is the App access asked for by --exclusive.
+func appAccess() string {
+	if exclusive {
+		return appv1alpha1.AppAccessExclusive
+	}
+	return appv1alpha1.AppAccessShared
+}
+
+// paramsSpec parses the key=value pairs given with --param.
+func paramsSpec() map[string]string {
+	if len(params) == 0 {
+		return nil
+	}
+	spec := make(map[string]string, len(params))
+	for _, p := range params {
+		key, value, ok := strings.Cut(p, "=")
+		if !ok || key == "" {
+			fmt.Printf("Error: --param must be key=value, got %q\n", p)
+			os.Exit(1)
+		}
+		spec[key] = value
+	}
+	return spec
+}
+
+// setScriptSource sets the script of a TestRun spec from --script, or from --git and --git-path.
+func setScriptSource(spec *appv1alpha1.TestRunSpec) {
+	if scriptPath != "" {
+		content, err := os.ReadFile(scriptPath)
+		if err != nil {
+			fmt.Printf("Failed to read script: %v\n", err)
+			os.Exit(1)
+		}
+		spec.Script = string(content)
+		return
+	}
+	spec.Git = &appv1alpha1.GitSource{
+		URL:  gitURL,
+		Path: gitPath,
+	}
+}
+
 func init() {
 	testCmd.AddCommand(scheduleCmd)
 
@@ -84,4 +146,9 @@ func init() {
 	scheduleCmd.Flags().StringVar(&gitPath, "git-path", "", "Path within git repo")
 	scheduleCmd.Flags().StringVar(&appName, "app", "", "Target App name")
 	scheduleCmd.Flags().StringVar(&namespace, "namespace", "default", "Target Namespace")
+	scheduleCmd.Flags().StringVar(&isolation, "isolate", "", "Run against a clone of the App: namespace or suffix")
+	scheduleCmd.Flags().BoolVar(&keepOnFailure, "keep-on-failure", false, "Keep the App clone when the run does not pass")
+	scheduleCmd.Flags().Int32Var(&retries, "retries", 0, "Run a failed test again up to this many times")
+	scheduleCmd.Flags().BoolVar(&exclusive, "exclusive", false, "Hold the App alone so the script may change it with sut.apply")
+	scheduleCmd.Flags().StringArrayVar(&params, "param", nil, "Param handed to the script as key=value; may be repeated")
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -39,6 +41,13 @@ var statusCmd = &cobra.Command{
 		fmt.Printf("State:      %s\n", testRun.Status.State)
 		fmt.Printf("Result:     %s\n", testRun.Status.Result)
 		fmt.Printf("RunnerPod:  %s\n", testRun.Status.RunnerPod)
+		if env := testRun.Status.Environment; env != nil {
+			retained := ""
+			if env.Retained {
+				retained = " (kept)"
+			}
+			fmt.Printf("Clone:      %s/%s%s\n", env.Namespace, env.AppName, retained)
+		}
 		if testRun.Status.StartTime != nil {
 			fmt.Printf("Started:    %s\n", testRun.Status.StartTime.Format("2006-01-02 15:04:05"))
 		}
@@ -49,9 +58,54 @@ var statusCmd = &cobra.Command{
 				fmt.Printf("Duration:   %s\n", duration.Round(time.Millisecond))
 			}
 		}
+		for _, c := range testRun.Status.Conditions {
+			fmt.Printf("Condition:  %s=%s (%s)\n", c.Type, c.Status, c.Reason)
+		}
+		if len(testRun.Status.Attempts) > 1 || testRun.Spec.Retries > 0 {
+			for _, a := range testRun.Status.Attempts {
+				fmt.Printf("Attempt %d:  %s, %s (%s)\n", a.Attempt, a.State, a.Result, a.RunnerPod)
+			}
+			if next := testRun.Status.NextAttemptTime; next != nil {
+				fmt.Printf("Next try:   %s\n", next.Format("2006-01-02 15:04:05"))
+			}
+		}
+
+		results := testRun.Status.Results
+		if results == nil {
+			return
+		}
+		s := results.Summary
+		fmt.Printf("Tests:      %d passed, %d failed, %d skipped of %d, %d assertions\n", s.Passed, s.Failed, s.Skipped, s.Total, s.Assertions)
+		if results.Error != nil {
+			fmt.Printf("Error:      %s\n", failureText(results.Error))
+		}
+		fmt.Println()
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TEST CASE\tSTATE\tASSERTIONS\tDURATION\tFAILURE")
+		for _, tc := range results.TestCases {
+			failure := "-"
+			if tc.Failure != nil {
+				failure = failureText(tc.Failure)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", tc.Name, tc.State, tc.Assertions, tc.Duration.Round(time.Millisecond), failure)
+		}
+		w.Flush()
+		if results.Truncated {
+			fmt.Println("(passing test cases omitted: the results did not fit the runner's termination message)")
+		}
 	},
 }
 
+// failureText puts a failure on one line, prefixed with where it was raised.
+func failureText(f *appv1alpha1.TestFailure) string {
+	msg := strings.ReplaceAll(f.Message, "\n", " ")
+	if f.Location == "" {
+		return msg
+	}
+	return f.Location + ": " + msg
+}
+
 func init() {
 	testCmd.AddCommand(statusCmd)
 }
@@ -0,0 +1,72 @@
+package faultproxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// Client drives the admin API of fault-injection proxies, each addressed as host:port.
+type Client struct {
+	HTTP *http.Client
+}
+
+// Set replaces the faults of the proxy at addr.
+func (c Client) Set(ctx context.Context, addr string, faults appv1alpha1.FaultSpec) error {
+	raw, err := json.Marshal(faults)
+	if err != nil {
+		return err
+	}
+	_, err = c.call(ctx, http.MethodPut, addr, raw)
+	return err
+}
+
+// Reset restores the faults the proxy at addr was started with.
+func (c Client) Reset(ctx context.Context, addr string) error {
+	_, err := c.call(ctx, http.MethodDelete, addr, nil)
+	return err
+}
+
+// Get returns the faults in effect at the proxy at addr.
+func (c Client) Get(ctx context.Context, addr string) (appv1alpha1.FaultSpec, error) {
+	var faults appv1alpha1.FaultSpec
+	raw, err := c.call(ctx, http.MethodGet, addr, nil)
+	if err != nil {
+		return faults, err
+	}
+	return faults, json.Unmarshal(raw, &faults)
+}
+
+func (c Client) call(ctx context.Context, method, addr string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, "http://"+addr+"/faults", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s: %s: %s", method, req.URL, resp.Status, strings.TrimSpace(string(raw)))
+	}
+	return raw, nil
+}
@@ -0,0 +1,215 @@
+// Package faultproxy is the fault-injection proxy run in the pods of services with fault injection. It
+// forwards HTTP/1 and h2c (gRPC) traffic to the service on localhost, adding the latency, errors,
+// connection resets and bandwidth limits set through its admin API:
+//
+//	GET    /faults  the faults in effect
+//	PUT    /faults  replace them (a FaultSpec as JSON)
+//	DELETE /faults  restore the faults the proxy was started with
+package faultproxy
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// Proxy holds the faults shared by every route of a pod.
+type Proxy struct {
+	baseline appv1alpha1.FaultSpec
+
+	mu     sync.RWMutex
+	faults appv1alpha1.FaultSpec
+}
+
+// New creates a proxy injecting baseline until told otherwise.
+func New(baseline appv1alpha1.FaultSpec) *Proxy {
+	return &Proxy{baseline: baseline, faults: baseline}
+}
+
+// Faults returns the faults in effect.
+func (p *Proxy) Faults() appv1alpha1.FaultSpec {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.faults
+}
+
+func (p *Proxy) setFaults(f appv1alpha1.FaultSpec) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.faults = f
+}
+
+// Protocols are those a route serves: HTTP/1 and HTTP/2 without TLS, which gRPC clients use in-cluster.
+func Protocols() *http.Protocols {
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	return &protocols
+}
+
+// Route returns a handler forwarding to target (host:port) with the proxy's faults applied.
+func (p *Proxy) Route(target string) http.Handler {
+	var h2c http.Protocols
+	h2c.SetUnencryptedHTTP2(true)
+	dial := (&net.Dialer{Timeout: 5 * time.Second}).DialContext
+	rp := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(&url.URL{Scheme: "http", Host: target})
+			r.Out.Host = r.In.Host
+		},
+		Transport: protocolTransport{
+			h1: &http.Transport{DialContext: dial},
+			h2: &http.Transport{DialContext: dial, Protocols: &h2c},
+		},
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.serve(w, r, rp)
+	})
+}
+
+func (p *Proxy) serve(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	f := p.Faults()
+
+	// 1. Hold the request back
+	delay := time.Duration(0)
+	if f.Latency != nil {
+		delay = f.Latency.Duration
+	}
+	if f.Jitter != nil && f.Jitter.Duration > 0 {
+		delay += rand.N(f.Jitter.Duration)
+	}
+	if delay > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+
+	// 2. Drop the connection (the stream, over HTTP/2) without answering
+	if roll(f.ResetPercent) {
+		panic(http.ErrAbortHandler)
+	}
+
+	// 3. Answer with an error instead of forwarding
+	if roll(f.ErrorPercent) {
+		writeError(w, r, f)
+		return
+	}
+
+	// 4. Forward, throttling the response
+	if f.Bandwidth != nil && f.Bandwidth.Value() > 0 {
+		w = &throttledWriter{ResponseWriter: w, ctx: r.Context(), bytesPerSecond: f.Bandwidth.Value()}
+	}
+	next.ServeHTTP(w, r)
+}
+
+// roll reports true for percent out of every 100 calls.
+func roll(percent int32) bool {
+	return percent > 0 && rand.Int32N(100) < percent
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, f appv1alpha1.FaultSpec) {
+	// gRPC clients read the status from a trailers-only response
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", "14")
+		w.Header().Set("Grpc-Message", "fault injected")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	status := int(f.ErrorStatus)
+	if status == 0 {
+		status = http.StatusServiceUnavailable
+	}
+	http.Error(w, "fault injected", status)
+}
+
+// protocolTransport forwards each request over the protocol it arrived with, so gRPC stays on HTTP/2.
+type protocolTransport struct {
+	h1, h2 http.RoundTripper
+}
+
+func (t protocolTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.ProtoMajor == 2 {
+		return t.h2.RoundTrip(r)
+	}
+	return t.h1.RoundTrip(r)
+}
+
+// throttledWriter paces a response to a number of bytes per second, in tenth-of-a-second slices.
+type throttledWriter struct {
+	http.ResponseWriter
+	ctx            context.Context
+	bytesPerSecond int64
+}
+
+func (w *throttledWriter) Write(b []byte) (int, error) {
+	chunk := max(int(w.bytesPerSecond/10), 1)
+	written := 0
+	for len(b) > 0 {
+		n := min(chunk, len(b))
+		m, err := w.ResponseWriter.Write(b[:n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+		b = b[n:]
+		if f, ok := w.ResponseWriter.(http.Flusher); ok {
+			f.Flush()
+		}
+		select {
+		case <-w.ctx.Done():
+			return written, w.ctx.Err()
+		case <-time.After(time.Duration(int64(n) * int64(time.Second) / w.bytesPerSecond)):
+		}
+	}
+	return written, nil
+}
+
+func (w *throttledWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *throttledWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Admin returns the handler of the admin API.
+func (p *Proxy) Admin() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /faults", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, p.Faults())
+	})
+	mux.HandleFunc("PUT /faults", func(w http.ResponseWriter, r *http.Request) {
+		var f appv1alpha1.FaultSpec
+		if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		p.setFaults(f)
+		writeJSON(w, http.StatusOK, f)
+	})
+	mux.HandleFunc("DELETE /faults", func(w http.ResponseWriter, _ *http.Request) {
+		p.setFaults(p.baseline)
+		writeJSON(w, http.StatusOK, p.baseline)
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,209 @@
+package chaos
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+	"github.com/chakradharkondapalli/topas/pkg/faultproxy"
+)
+
+// Module drives the fault-injection proxies of an App's services. Faults apply to the pods running when
+// they are set; the controller restores every proxy's configured faults when the TestRun ends.
+type Module struct {
+	Client    client.Client
+	AppName   string
+	Namespace string
+	Proxies   faultproxy.Client
+}
+
+func New(c client.Client, appName, namespace string) *Module {
+	return &Module{
+		Client:    c,
+		AppName:   appName,
+		Namespace: namespace,
+		Proxies:   faultproxy.Client{HTTP: &http.Client{Timeout: 10 * time.Second}},
+	}
+}
+
+func (m *Module) Loader(L *lua.LState) int {
+	mod := L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"inject": m.Inject,
+		"reset":  m.Reset,
+		"faults": m.Faults,
+	})
+	L.Push(mod)
+	return 1
+}
+
+// Inject replaces the faults of a service's proxies with those of the table: latency and jitter as
+// durations, error_percent, error_status, reset_percent, and bandwidth in bytes per second (e.g. "64Ki").
+//
+//	chaos.inject("payments", { latency = "2s", error_percent = 10 })
+func (m *Module) Inject(L *lua.LState) int {
+	serviceName := L.CheckString(1)
+	faults := parseFaults(L, L.CheckTable(2))
+
+	ctx := context.Background()
+	for _, addr := range m.proxies(L, serviceName) {
+		if err := m.Proxies.Set(ctx, addr, faults); err != nil {
+			L.RaiseError("failed to inject faults into %s: %v", serviceName, err)
+			return 0
+		}
+	}
+	return 0
+}
+
+// Reset restores the configured faults of a service's proxies, or of every service with fault injection
+// when called without a service.
+func (m *Module) Reset(L *lua.LState) int {
+	services := []string{L.OptString(1, "")}
+	if services[0] == "" {
+		services = nil
+		for _, svc := range m.app(L).Spec.Services {
+			if svc.FaultInjection != nil {
+				services = append(services, svc.Name)
+			}
+		}
+	}
+
+	ctx := context.Background()
+	for _, serviceName := range services {
+		for _, addr := range m.proxies(L, serviceName) {
+			if err := m.Proxies.Reset(ctx, addr); err != nil {
+				L.RaiseError("failed to reset faults of %s: %v", serviceName, err)
+				return 0
+			}
+		}
+	}
+	return 0
+}
+
+// Faults returns the faults in effect for a service, as read from one of its proxies, in the table form
+// Inject takes.
+func (m *Module) Faults(L *lua.LState) int {
+	serviceName := L.CheckString(1)
+	addrs := m.proxies(L, serviceName)
+
+	f, err := m.Proxies.Get(context.Background(), addrs[0])
+	if err != nil {
+		L.RaiseError("failed to read faults of %s: %v", serviceName, err)
+		return 0
+	}
+	t := L.NewTable()
+	if f.Latency != nil {
+		t.RawSetString("latency", lua.LString(f.Latency.Duration.String()))
+	}
+	if f.Jitter != nil {
+		t.RawSetString("jitter", lua.LString(f.Jitter.Duration.String()))
+	}
+	t.RawSetString("error_percent", lua.LNumber(f.ErrorPercent))
+	if f.ErrorStatus != 0 {
+		t.RawSetString("error_status", lua.LNumber(f.ErrorStatus))
+	}
+	t.RawSetString("reset_percent", lua.LNumber(f.ResetPercent))
+	if f.Bandwidth != nil {
+		t.RawSetString("bandwidth", lua.LString(f.Bandwidth.String()))
+	}
+	L.Push(t)
+	return 1
+}
+
+func parseFaults(L *lua.LState, t *lua.LTable) appv1alpha1.FaultSpec {
+	var f appv1alpha1.FaultSpec
+	duration := func(key string) *metav1.Duration {
+		v := t.RawGetString(key)
+		if v == lua.LNil {
+			return nil
+		}
+		d, err := time.ParseDuration(v.String())
+		if err != nil || d < 0 {
+			L.RaiseError("invalid %s %q: want a duration such as 200ms", key, v.String())
+		}
+		return &metav1.Duration{Duration: d}
+	}
+	number := func(key string, lo, hi int32) int32 {
+		v := t.RawGetString(key)
+		if v == lua.LNil {
+			return 0
+		}
+		n, ok := v.(lua.LNumber)
+		if !ok || int32(n) < lo || int32(n) > hi {
+			L.RaiseError("invalid %s %s: want a number between %d and %d", key, v.String(), lo, hi)
+		}
+		return int32(n)
+	}
+
+	f.Latency = duration("latency")
+	f.Jitter = duration("jitter")
+	f.ErrorPercent = number("error_percent", 0, 100)
+	f.ErrorStatus = number("error_status", 100, 599)
+	f.ResetPercent = number("reset_percent", 0, 100)
+	if v := t.RawGetString("bandwidth"); v != lua.LNil {
+		q, err := resource.ParseQuantity(v.String())
+		if err != nil || q.Sign() <= 0 {
+			L.RaiseError("invalid bandwidth %q: want bytes per second such as 64Ki", v.String())
+		}
+		f.Bandwidth = &q
+	}
+	return f
+}
+
+func (m *Module) app(L *lua.LState) *appv1alpha1.App {
+	app := &appv1alpha1.App{}
+	if err := m.Client.Get(context.Background(), types.NamespacedName{Name: m.AppName, Namespace: m.Namespace}, app); err != nil {
+		L.RaiseError("failed to get app: %v", err)
+	}
+	return app
+}
+
+// proxies returns the admin addresses of the running fault-injection proxies of a service.
+func (m *Module) proxies(L *lua.LState, serviceName string) []string {
+	found := false
+	for _, svc := range m.app(L).Spec.Services {
+		if svc.Name != serviceName {
+			continue
+		}
+		if svc.FaultInjection == nil {
+			L.RaiseError("service %s has no faultInjection", serviceName)
+		}
+		found = true
+	}
+	if !found {
+		L.RaiseError("service not found: %s", serviceName)
+	}
+
+	var pods corev1.PodList
+	if err := m.Client.List(context.Background(), &pods, client.InNamespace(m.Namespace), client.MatchingLabels{
+		"app":                          serviceName,
+		"app.kubernetes.io/managed-by": "topas",
+		"app.kubernetes.io/part-of":    m.AppName,
+	}); err != nil {
+		L.RaiseError("failed to list pods: %v", err)
+	}
+	var addrs []string
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+		for _, c := range pod.Spec.Containers {
+			if c.Name == appv1alpha1.FaultProxyContainerName {
+				addrs = append(addrs, net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(appv1alpha1.FaultProxyAdminPort))))
+			}
+		}
+	}
+	if len(addrs) == 0 {
+		L.RaiseError("no running pods with a fault-injection proxy for %s", serviceName)
+	}
+	return addrs
+}
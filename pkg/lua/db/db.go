@@ -1,17 +1,47 @@
 package db
 
 import (
-	"database/sql"
 	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/chakradharkondapalli/topas/pkg/lua/results"
 	"github.com/chakradharkondapalli/topas/pkg/lua/util"
-	_ "github.com/lib/pq"
 	lua "github.com/yuin/gopher-lua"
 )
 
+// Supported engines, matching DatabaseSpec.Engine on the App CR
+const (
+	EnginePostgres = "postgres"
+	EngineMySQL    = "mysql"
+	EngineRedis    = "redis"
+	EngineMongoDB  = "mongodb"
+)
+
+var defaultPorts = map[string]string{
+	EnginePostgres: "5432",
+	EngineMySQL:    "3306",
+	EngineRedis:    "6379",
+	EngineMongoDB:  "27017",
+}
+
+// store is the engine-specific backend behind the Lua db functions.
+type store interface {
+	// Insert writes one row (or document/hash) into table
+	Insert(table string, row map[string]interface{}) error
+	// Exists reports whether any row in table matches all where fields
+	Exists(table string, where map[string]interface{}) (bool, error)
+	Close() error
+}
+
 type Module struct {
-	DB *sql.DB
+	store store
+
+	// Results counts the assertions checked by expect
+	Results *results.Recorder
 }
 
 func New() *Module {
@@ -29,37 +59,80 @@ func (m *Module) Loader(L *lua.LState) int {
 }
 
 func (m *Module) Connect(L *lua.LState) int {
-	// config = { type="postgres", host=..., ... } OR uri string
+	// config = { engine="postgres", host=..., ... } OR uri string
 	arg := L.CheckAny(1)
-	var dsn string
-	driver := "postgres"
+	var engine, dsn string
 
 	if arg.Type() == lua.LTString {
 		dsn = arg.String()
+		engine = engineFromURI(dsn)
 	} else if arg.Type() == lua.LTTable {
 		// Construct DSN from table
 		t := arg.(*lua.LTable)
-		user := t.RawGetString("user").String()
-		pass := t.RawGetString("password").String()
-		host := t.RawGetString("host").String()
-		port := t.RawGetString("port").String()
-		dbname := t.RawGetString("dbname").String()
-		if port == "" || port == "nil" {
-			port = "5432"
+		engine = optString(t, "engine")
+		if engine == "" {
+			engine = optString(t, "type")
+		}
+		if engine == "" {
+			engine = EnginePostgres
+		}
+		if _, ok := defaultPorts[engine]; !ok {
+			L.RaiseError("unsupported db engine: %s", engine)
+			return 0
+		}
+		port := optString(t, "port")
+		if port == "" {
+			port = defaultPorts[engine]
 		}
-		dsn = fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", user, pass, host, port, dbname)
+		u := &url.URL{
+			Scheme: engine,
+			Host:   net.JoinHostPort(optString(t, "host"), port),
+			Path:   "/" + optString(t, "dbname"),
+		}
+		if user, pass := optString(t, "user"), optString(t, "password"); user != "" || pass != "" {
+			u.User = url.UserPassword(user, pass)
+		}
+		switch engine {
+		case EnginePostgres:
+			u.RawQuery = "sslmode=disable"
+		case EngineRedis:
+			// Redis selects a numbered logical database; anything else is ignored
+			if _, err := strconv.Atoi(optString(t, "dbname")); err != nil {
+				u.Path = ""
+			}
+		case EngineMongoDB:
+			if u.User != nil {
+				u.RawQuery = "authSource=admin"
+			}
+		}
+		dsn = u.String()
+	} else {
+		L.ArgError(1, "expected connection URI or table")
+		return 0
 	}
 
-	db, err := sql.Open(driver, dsn)
-	if err != nil {
-		L.RaiseError("failed to open db: %v", err)
-		return 0
+	var (
+		s   store
+		err error
+	)
+	switch engine {
+	case EngineMySQL:
+		s, err = openMySQL(dsn)
+	case EngineRedis:
+		s, err = openRedis(dsn)
+	case EngineMongoDB:
+		s, err = openMongo(dsn)
+	default:
+		s, err = openPostgres(dsn)
 	}
-	if err := db.Ping(); err != nil {
+	if err != nil {
 		L.RaiseError("failed to connect to db: %v", err)
 		return 0
 	}
-	m.DB = db
+	if m.store != nil {
+		m.store.Close()
+	}
+	m.store = s
 	return 0
 }
 
@@ -73,26 +146,17 @@ func (m *Module) Seed(L *lua.LState) int {
 		L.RaiseError("rows must be a table")
 		return 0
 	}
+	if m.store == nil {
+		L.RaiseError("database not connected")
+		return 0
+	}
 
 	rowsTable := rows.(*lua.LTable)
 	rowsTable.ForEach(func(_, row lua.LValue) {
 		if row.Type() != lua.LTTable {
 			return
 		}
-		r := row.(*lua.LTable)
-		cols := []string{}
-		vals := []interface{}{}
-		placeholders := []string{}
-		i := 1
-		r.ForEach(func(k, v lua.LValue) {
-			cols = append(cols, k.String())
-			vals = append(vals, toGoValue(v))
-			placeholders = append(placeholders, fmt.Sprintf("$%d", i))
-			i++
-		})
-
-		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(cols, ", "), strings.Join(placeholders, ", "))
-		if _, err := m.DB.Exec(query, vals...); err != nil {
+		if err := m.store.Insert(table, toGoMap(row.(*lua.LTable))); err != nil {
 			L.RaiseError("seed failed: %v", err)
 		}
 	})
@@ -103,32 +167,22 @@ func (m *Module) Expect(L *lua.LState) int {
 	// expect({ table="users", where={name="alice"}, json={role="admin"} })
 	arg := L.CheckTable(1)
 	table := arg.RawGetString("table").String()
-	where := arg.RawGetString("where")
-
-	query := fmt.Sprintf("SELECT * FROM %s", table)
-	args := []interface{}{}
-
-	if where.Type() == lua.LTTable {
-		conds := []string{}
-		i := 1
-		where.(*lua.LTable).ForEach(func(k, v lua.LValue) {
-			conds = append(conds, fmt.Sprintf("%s = $%d", k.String(), i))
-			args = append(args, toGoValue(v))
-			i++
-		})
-		if len(conds) > 0 {
-			query += " WHERE " + strings.Join(conds, " AND ")
-		}
+	where := map[string]interface{}{}
+	if w := arg.RawGetString("where"); w.Type() == lua.LTTable {
+		where = toGoMap(w.(*lua.LTable))
+	}
+	if m.store == nil {
+		L.RaiseError("database not connected")
+		return 0
 	}
 
-	rows, err := m.DB.Query(query, args...)
+	found, err := m.store.Exists(table, where)
 	if err != nil {
 		L.RaiseError("query failed: %v", err)
 		return 0
 	}
-	defer rows.Close()
-
-	if !rows.Next() {
+	m.Results.Assert()
+	if !found {
 		L.RaiseError("assertion failed: no rows found")
 		return 0
 	}
@@ -137,6 +191,55 @@ func (m *Module) Expect(L *lua.LState) int {
 	return 0
 }
 
+// engineFromURI picks the engine from a connection URI scheme; anything else is handed to lib/pq.
+func engineFromURI(uri string) string {
+	switch {
+	case strings.HasPrefix(uri, "mysql://"):
+		return EngineMySQL
+	case strings.HasPrefix(uri, "redis://"), strings.HasPrefix(uri, "rediss://"):
+		return EngineRedis
+	case strings.HasPrefix(uri, "mongodb://"), strings.HasPrefix(uri, "mongodb+srv://"):
+		return EngineMongoDB
+	default:
+		return EnginePostgres
+	}
+}
+
+// optString returns a string field of t, or "" when it is nil.
+func optString(t *lua.LTable, key string) string {
+	v := t.RawGetString(key)
+	if v == lua.LNil {
+		return ""
+	}
+	return v.String()
+}
+
+func toGoMap(t *lua.LTable) map[string]interface{} {
+	out := map[string]interface{}{}
+	t.ForEach(func(k, v lua.LValue) {
+		out[k.String()] = toGoValue(v)
+	})
+	return out
+}
+
 func toGoValue(v lua.LValue) interface{} {
 	return util.ToGoValue(v)
 }
+
+// sortedKeys keeps generated column lists deterministic.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// stringify renders a value the way Redis stores it in a hash field.
+func stringify(v interface{}) string {
+	if f, ok := v.(float64); ok && f == float64(int64(f)) {
+		return fmt.Sprintf("%d", int64(f))
+	}
+	return fmt.Sprint(v)
+}
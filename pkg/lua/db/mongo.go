@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoStore treats tables as collections in the database named by the URI path.
+type mongoStore struct {
+	client *mongo.Client
+	db     *mongo.Database
+}
+
+func openMongo(uri string) (store, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(ctx)
+		return nil, err
+	}
+	return &mongoStore{client: client, db: client.Database(databaseFromURI(uri))}, nil
+}
+
+func (s *mongoStore) Insert(table string, row map[string]interface{}) error {
+	_, err := s.db.Collection(table).InsertOne(context.Background(), bson.M(row))
+	return err
+}
+
+func (s *mongoStore) Exists(table string, where map[string]interface{}) (bool, error) {
+	n, err := s.db.Collection(table).CountDocuments(context.Background(), bson.M(where), options.Count().SetLimit(1))
+	return n > 0, err
+}
+
+func (s *mongoStore) Close() error {
+	return s.client.Disconnect(context.Background())
+}
+
+// databaseFromURI returns the path component of a mongodb:// URI, defaulting to "test" like the shell does.
+func databaseFromURI(uri string) string {
+	rest := uri[strings.Index(uri, "://")+3:]
+	if i := strings.Index(rest, "/"); i >= 0 {
+		name := rest[i+1:]
+		if j := strings.IndexByte(name, '?'); j >= 0 {
+			name = name[:j]
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return "test"
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisStore maps each row onto a hash at "<table>:<id>". Rows without an
+// id field get one from the "<table>:_seq" counter.
+type redisStore struct {
+	client *redis.Client
+}
+
+func openRedis(uri string) (store, error) {
+	opts, err := redis.ParseURL(uri)
+	if err != nil {
+		return nil, err
+	}
+	client := redis.NewClient(opts)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &redisStore{client: client}, nil
+}
+
+func (s *redisStore) Insert(table string, row map[string]interface{}) error {
+	ctx := context.Background()
+	id, ok := row["id"]
+	if !ok {
+		n, err := s.client.Incr(ctx, table+":_seq").Result()
+		if err != nil {
+			return err
+		}
+		id = n
+	}
+
+	fields := make(map[string]interface{}, len(row))
+	for k, v := range row {
+		fields[k] = stringify(v)
+	}
+	return s.client.HSet(ctx, fmt.Sprintf("%s:%s", table, stringify(id)), fields).Err()
+}
+
+func (s *redisStore) Exists(table string, where map[string]interface{}) (bool, error) {
+	ctx := context.Background()
+	iter := s.client.Scan(ctx, 0, table+":*", 100).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		if strings.HasSuffix(key, ":_seq") {
+			continue
+		}
+		fields, err := s.client.HGetAll(ctx, key).Result()
+		if err != nil {
+			return false, err
+		}
+		if hashMatches(fields, where) {
+			return true, nil
+		}
+	}
+	return false, iter.Err()
+}
+
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
+
+func hashMatches(fields map[string]string, where map[string]interface{}) bool {
+	for k, v := range where {
+		got, ok := fields[k]
+		if !ok || got != stringify(v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+)
+
+// sqlStore backs Postgres and MySQL, which differ only in placeholder syntax.
+type sqlStore struct {
+	db          *sql.DB
+	placeholder func(i int) string
+}
+
+func openPostgres(dsn string) (store, error) {
+	return openSQL("postgres", dsn, func(i int) string { return fmt.Sprintf("$%d", i) })
+}
+
+func openMySQL(uri string) (store, error) {
+	// go-sql-driver wants user:pass@tcp(host:port)/db rather than a URL
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	cfg := mysql.NewConfig()
+	cfg.Net = "tcp"
+	cfg.Addr = u.Host
+	cfg.DBName = strings.TrimPrefix(u.Path, "/")
+	if u.User != nil {
+		cfg.User = u.User.Username()
+		cfg.Passwd, _ = u.User.Password()
+	}
+	return openSQL("mysql", cfg.FormatDSN(), func(int) string { return "?" })
+}
+
+func openSQL(driver, dsn string, placeholder func(i int) string) (store, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqlStore{db: db, placeholder: placeholder}, nil
+}
+
+func (s *sqlStore) Insert(table string, row map[string]interface{}) error {
+	cols := sortedKeys(row)
+	vals := []interface{}{}
+	placeholders := []string{}
+	for i, col := range cols {
+		vals = append(vals, row[col])
+		placeholders = append(placeholders, s.placeholder(i+1))
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(cols, ", "), strings.Join(placeholders, ", "))
+	_, err := s.db.Exec(query, vals...)
+	return err
+}
+
+func (s *sqlStore) Exists(table string, where map[string]interface{}) (bool, error) {
+	query := fmt.Sprintf("SELECT 1 FROM %s", table)
+	args := []interface{}{}
+
+	conds := []string{}
+	for i, col := range sortedKeys(where) {
+		conds = append(conds, fmt.Sprintf("%s = %s", col, s.placeholder(i+1)))
+		args = append(args, where[col])
+	}
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	rows, err := s.db.Query(query+" LIMIT 1", args...)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	return rows.Next(), rows.Err()
+}
+
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
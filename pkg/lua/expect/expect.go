@@ -0,0 +1,267 @@
+// Package expect is the matcher library of test scripts. A failed match raises an error at the line of
+// the script that checked it, with a diff of the values for the structural matchers.
+//
+//	local expect = require("expect")
+//	expect(resp.status):to_equal(200)
+//	expect(resp.json):to_match({ status = "paid", items = { { sku = "A1" } } })
+//	expect(resp.json.id).never:to_be_a("nil")
+package expect
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/chakradharkondapalli/topas/pkg/lua/results"
+	"github.com/chakradharkondapalli/topas/pkg/lua/util"
+)
+
+// matcherType names the metatable of the values expect returns.
+const matcherType = "expect.matcher"
+
+// defaultTolerance is the distance to_be_close_to allows when none is given.
+const defaultTolerance = 1e-6
+
+type Module struct {
+	// Results counts the assertions checked by the matchers
+	Results *results.Recorder
+}
+
+// matcher holds the value under test; negate is set when reached through never.
+type matcher struct {
+	actual lua.LValue
+	negate bool
+}
+
+func New() *Module {
+	return &Module{}
+}
+
+// Loader returns the module as a callable table: expect(value) returns a matcher whose methods check
+// value and return the matcher, so checks can be chained. expect(value).never inverts every check.
+func (m *Module) Loader(L *lua.LState) int {
+	methods := L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"to_equal":           m.ToEqual,
+		"to_match":           m.ToMatch,
+		"to_contain":         m.ToContain,
+		"to_contain_exactly": m.ToContainExactly,
+		"to_match_pattern":   m.ToMatchPattern,
+		"to_be_close_to":     m.ToBeCloseTo,
+		"to_be_a":            m.ToBeA,
+		"to_have_length":     m.ToHaveLength,
+	})
+	mt := L.NewTypeMetatable(matcherType)
+	L.SetField(mt, "__index", L.NewFunction(func(L *lua.LState) int {
+		mm := checkMatcher(L)
+		key := L.CheckString(2)
+		if key == "never" {
+			L.Push(newMatcher(L, mm.actual, !mm.negate))
+			return 1
+		}
+		L.Push(methods.RawGetString(key))
+		return 1
+	}))
+
+	mod := L.NewTable()
+	call := L.NewTable()
+	L.SetField(call, "__call", L.NewFunction(func(L *lua.LState) int {
+		L.Push(newMatcher(L, L.Get(2), false))
+		return 1
+	}))
+	L.SetMetatable(mod, call)
+	L.Push(mod)
+	return 1
+}
+
+func newMatcher(L *lua.LState, actual lua.LValue, negate bool) *lua.LUserData {
+	ud := L.NewUserData()
+	ud.Value = &matcher{actual: actual, negate: negate}
+	L.SetMetatable(ud, L.GetTypeMetatable(matcherType))
+	return ud
+}
+
+func checkMatcher(L *lua.LState) *matcher {
+	if ud, ok := L.Get(1).(*lua.LUserData); ok {
+		if mm, ok := ud.Value.(*matcher); ok {
+			return mm
+		}
+	}
+	L.ArgError(1, "matcher expected, call the matchers with ':'")
+	return nil
+}
+
+// check counts the assertion and raises msg when the match failed, or negMsg when it succeeded but was
+// negated. It pushes the matcher back for chaining.
+func (m *Module) check(L *lua.LState, mm *matcher, ok bool, msg, negMsg string) int {
+	m.Results.Assert()
+	if ok == mm.negate {
+		if mm.negate {
+			msg = negMsg
+		}
+		L.RaiseError("%s", msg)
+		return 0
+	}
+	L.Push(L.Get(1))
+	return 1
+}
+
+// ToEqual checks that the value deeply equals expected: same keys and array elements, in order.
+func (m *Module) ToEqual(L *lua.LState) int {
+	mm := checkMatcher(L)
+	expected := util.ToGoValue(L.Get(2))
+	actual := util.ToGoValue(mm.actual)
+	diffs := util.Compare(expected, actual, false)
+	return m.check(L, mm, len(diffs) == 0,
+		"expected values to be equal:"+util.FormatDiff(diffs),
+		"expected value not to equal "+util.Format(expected))
+}
+
+// ToMatch checks that the value holds expected as a subset: every key of an expected table must be
+// there with a matching value, extra keys are ignored, and an expected array must be a prefix of the
+// actual one. This is how http.expect compares bodies.
+func (m *Module) ToMatch(L *lua.LState) int {
+	mm := checkMatcher(L)
+	expected := util.ToGoValue(L.Get(2))
+	actual := util.ToGoValue(mm.actual)
+	diffs := util.Compare(expected, actual, true)
+	return m.check(L, mm, len(diffs) == 0,
+		"expected value to match:"+util.FormatDiff(diffs),
+		"expected value not to match "+util.Format(expected))
+}
+
+// ToContain checks that a string contains a substring, or that an array has an element equal to the
+// argument.
+func (m *Module) ToContain(L *lua.LState) int {
+	mm := checkMatcher(L)
+	item := L.Get(2)
+	var ok bool
+	switch actual := mm.actual.(type) {
+	case lua.LString:
+		ok = strings.Contains(string(actual), L.CheckString(2))
+	case *lua.LTable:
+		want := util.ToGoValue(item)
+		for i := 1; i <= actual.Len(); i++ {
+			if util.Equal(want, util.ToGoValue(actual.RawGetInt(i))) {
+				ok = true
+				break
+			}
+		}
+	default:
+		L.RaiseError("to_contain expects a string or an array, got %s", mm.actual.Type())
+		return 0
+	}
+	got, want := util.Format(util.ToGoValue(mm.actual)), util.Format(util.ToGoValue(item))
+	return m.check(L, mm, ok,
+		fmt.Sprintf("expected %s to contain %s", got, want),
+		fmt.Sprintf("expected %s not to contain %s", got, want))
+}
+
+// ToContainExactly checks that an array holds the same elements as expected, in any order.
+func (m *Module) ToContainExactly(L *lua.LState) int {
+	mm := checkMatcher(L)
+	expected := toArray(L, L.CheckTable(2))
+	actual, ok := mm.actual.(*lua.LTable)
+	if !ok {
+		L.RaiseError("to_contain_exactly expects an array, got %s", mm.actual.Type())
+		return 0
+	}
+	diffs := util.CompareUnordered(expected, toArray(L, actual))
+	return m.check(L, mm, len(diffs) == 0,
+		"expected array to contain exactly, in any order:"+util.FormatDiff(diffs),
+		"expected array not to contain exactly "+util.Format(expected))
+}
+
+// ToMatchPattern checks a string against a regular expression (Go syntax, not a Lua pattern).
+func (m *Module) ToMatchPattern(L *lua.LState) int {
+	mm := checkMatcher(L)
+	pattern := L.CheckString(2)
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		L.RaiseError("invalid pattern %q: %v", pattern, err)
+		return 0
+	}
+	s, ok := mm.actual.(lua.LString)
+	if !ok {
+		L.RaiseError("to_match_pattern expects a string, got %s", mm.actual.Type())
+		return 0
+	}
+	return m.check(L, mm, re.MatchString(string(s)),
+		fmt.Sprintf("expected %q to match pattern %q", string(s), pattern),
+		fmt.Sprintf("expected %q not to match pattern %q", string(s), pattern))
+}
+
+// ToBeCloseTo checks that a number is within tolerance (default 1e-6) of expected.
+func (m *Module) ToBeCloseTo(L *lua.LState) int {
+	mm := checkMatcher(L)
+	expected := float64(L.CheckNumber(2))
+	tolerance := float64(L.OptNumber(3, defaultTolerance))
+	n, ok := mm.actual.(lua.LNumber)
+	if !ok {
+		L.RaiseError("to_be_close_to expects a number, got %s", mm.actual.Type())
+		return 0
+	}
+	diff := math.Abs(float64(n) - expected)
+	return m.check(L, mm, diff <= tolerance,
+		fmt.Sprintf("expected %v to be within %v of %v (difference %v)", float64(n), tolerance, expected, diff),
+		fmt.Sprintf("expected %v not to be within %v of %v", float64(n), tolerance, expected))
+}
+
+// ToBeA checks the type of the value: a Lua type name (nil, boolean, number, string, table, function),
+// or array or object for tables holding a list or keys. An empty table is both.
+func (m *Module) ToBeA(L *lua.LState) int {
+	mm := checkMatcher(L)
+	want := L.CheckString(2)
+	got := mm.actual.Type().String()
+	ok := got == want
+	if t, isTable := mm.actual.(*lua.LTable); isTable {
+		switch want {
+		case "array":
+			ok = t.Len() > 0 || isEmpty(t)
+		case "object":
+			ok = t.Len() == 0
+		}
+	}
+	value := util.Format(util.ToGoValue(mm.actual))
+	return m.check(L, mm, ok,
+		fmt.Sprintf("expected type %s, got %s %s", want, got, value),
+		fmt.Sprintf("expected %s not to be of type %s", value, want))
+}
+
+// ToHaveLength checks the length of a string or an array.
+func (m *Module) ToHaveLength(L *lua.LState) int {
+	mm := checkMatcher(L)
+	want := L.CheckInt(2)
+	var got int
+	switch actual := mm.actual.(type) {
+	case lua.LString:
+		got = len(actual)
+	case *lua.LTable:
+		got = actual.Len()
+	default:
+		L.RaiseError("to_have_length expects a string or an array, got %s", mm.actual.Type())
+		return 0
+	}
+	return m.check(L, mm, got == want,
+		fmt.Sprintf("expected length %d, got %d", want, got),
+		fmt.Sprintf("expected length other than %d", want))
+}
+
+// toArray converts an array table, raising an error for a table with keys.
+func toArray(L *lua.LState, t *lua.LTable) []interface{} {
+	if isEmpty(t) {
+		return nil
+	}
+	arr, ok := util.ToGoValue(t).([]interface{})
+	if !ok {
+		L.RaiseError("array expected, got a table with keys")
+	}
+	return arr
+}
+
+func isEmpty(t *lua.LTable) bool {
+	k, _ := t.Next(lua.LNil)
+	return k == lua.LNil
+}
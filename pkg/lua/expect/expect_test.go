@@ -0,0 +1,127 @@
+package expect
+
+import (
+	"testing"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/chakradharkondapalli/topas/pkg/lua/results"
+)
+
+func TestMatchers(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		// want is the failure message, empty when the check passes
+		want string
+	}{
+		{name: "to_equal passes", script: `expect({a = 1, b = {2, 3}}):to_equal({a = 1, b = {2, 3}})`},
+		{
+			name:   "to_equal fails",
+			script: `expect({a = 1, b = {2, 3}}):to_equal({a = 1, b = {2, 4}})`,
+			want:   "expected values to be equal:\n  $.b[2]: expected 4, got 3",
+		},
+		{
+			name:   "to_equal fails negated",
+			script: `expect("x").never:to_equal("x")`,
+			want:   `expected value not to equal "x"`,
+		},
+		{name: "to_match passes", script: `expect({id = 7, name = "pen"}):to_match({name = "pen"})`},
+		{
+			name:   "to_match fails",
+			script: `expect({id = 7}):to_match({name = "pen"})`,
+			want:   "expected value to match:\n  $.name: missing, expected \"pen\"",
+		},
+		{
+			name:   "to_match fails negated",
+			script: `expect({id = 7, name = "pen"}).never:to_match({name = "pen"})`,
+			want:   `expected value not to match {"name":"pen"}`,
+		},
+		{name: "to_contain passes on a string", script: `expect("hello world"):to_contain("world")`},
+		{name: "to_contain passes on an array", script: `expect({1, {a = 2}}):to_contain({a = 2})`},
+		{
+			name:   "to_contain fails",
+			script: `expect({1, 2}):to_contain(3)`,
+			want:   "expected [1,2] to contain 3",
+		},
+		{
+			name:   "to_contain fails negated",
+			script: `expect("hello").never:to_contain("ell")`,
+			want:   `expected "hello" not to contain "ell"`,
+		},
+		{name: "to_contain_exactly passes", script: `expect({3, 1, 2}):to_contain_exactly({1, 2, 3})`},
+		{
+			name:   "to_contain_exactly fails",
+			script: `expect({1, 2, 4}):to_contain_exactly({1, 2, 3})`,
+			want:   "expected array to contain exactly, in any order:\n  $[3]: missing, expected 3\n  $[3]: unexpected 4",
+		},
+		{
+			name:   "to_contain_exactly fails negated",
+			script: `expect({2, 1}).never:to_contain_exactly({1, 2})`,
+			want:   "expected array not to contain exactly [1,2]",
+		},
+		{name: "to_match_pattern passes", script: `expect("order-42"):to_match_pattern("^order-\\d+$")`},
+		{
+			name:   "to_match_pattern fails",
+			script: `expect("order-x"):to_match_pattern("^order-\\d+$")`,
+			want:   `expected "order-x" to match pattern "^order-\\d+$"`,
+		},
+		{
+			name:   "to_match_pattern fails negated",
+			script: `expect("order-42").never:to_match_pattern("\\d")`,
+			want:   `expected "order-42" not to match pattern "\\d"`,
+		},
+		{name: "to_be_close_to passes", script: `expect(0.1 + 0.2):to_be_close_to(0.3)`},
+		{
+			name:   "to_be_close_to fails",
+			script: `expect(1.5):to_be_close_to(1, 0.25)`,
+			want:   "expected 1.5 to be within 0.25 of 1 (difference 0.5)",
+		},
+		{
+			name:   "to_be_close_to fails negated",
+			script: `expect(1.1).never:to_be_close_to(1, 0.25)`,
+			want:   "expected 1.1 not to be within 0.25 of 1",
+		},
+		{name: "to_be_a passes", script: `expect({1, 2}):to_be_a("array")`},
+		{
+			name:   "to_be_a fails",
+			script: `expect({a = 1}):to_be_a("array")`,
+			want:   `expected type array, got table {"a":1}`,
+		},
+		{
+			name:   "to_be_a fails negated",
+			script: `expect("x").never:to_be_a("string")`,
+			want:   `expected "x" not to be of type string`,
+		},
+		{name: "to_have_length passes", script: `expect({1, 2, 3}):to_have_length(3)`},
+		{
+			name:   "to_have_length fails",
+			script: `expect("abc"):to_have_length(2)`,
+			want:   "expected length 2, got 3",
+		},
+		{
+			name:   "to_have_length fails negated",
+			script: `expect("abc").never:to_have_length(3)`,
+			want:   "expected length other than 3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			L := lua.NewState()
+			defer L.Close()
+			L.PreloadModule("expect", New().Loader)
+
+			err := L.DoString(`local expect = require("expect")` + "\n" + tt.script)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Fatalf("expected the check to pass, got %v", err)
+			case tt.want != "" && err == nil:
+				t.Fatalf("expected the check to fail with %q", tt.want)
+			case err != nil:
+				if got := results.Failure(err).Message; got != tt.want {
+					t.Errorf("got message %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
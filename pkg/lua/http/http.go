@@ -6,11 +6,15 @@ import (
 	"io"
 	"net/http"
 
+	"github.com/chakradharkondapalli/topas/pkg/lua/results"
 	"github.com/chakradharkondapalli/topas/pkg/lua/util"
 	lua "github.com/yuin/gopher-lua"
 )
 
-type Module struct{}
+type Module struct {
+	// Results counts the assertions checked by expect
+	Results *results.Recorder
+}
 
 func New() *Module {
 	return &Module{}
@@ -77,6 +81,7 @@ func (m *Module) Expect(L *lua.LState) int {
 		statusVal := expectTable.RawGetString("status")
 		if statusVal.Type() == lua.LTNumber {
 			expectedStatus := int(statusVal.(lua.LNumber))
+			m.Results.Assert()
 			if resp.StatusCode != expectedStatus {
 				L.RaiseError("assertion failed: expected status %d, got %d. Body: %s", expectedStatus, resp.StatusCode, string(respBody))
 				return 0
@@ -86,6 +91,7 @@ func (m *Module) Expect(L *lua.LState) int {
 		// Assert Body (Subset Match)
 		expectBodyVal := expectTable.RawGetString("body")
 		if expectBodyVal.Type() != lua.LTNil {
+			m.Results.Assert()
 			var actual interface{}
 			if err := json.Unmarshal(respBody, &actual); err != nil {
 				L.RaiseError("failed to parse response body as JSON: %v. Body: %s", err, string(respBody))
@@ -96,8 +102,8 @@ func (m *Module) Expect(L *lua.LState) int {
 			var expected interface{}
 			json.Unmarshal(expectedJSON, &expected)
 
-			if !subsetMatch(expected, actual) {
-				L.RaiseError("assertion failed: body mismatch. Expected subset %s, got %s", string(expectedJSON), string(respBody))
+			if diffs := util.Compare(expected, actual, true); len(diffs) > 0 {
+				L.RaiseError("assertion failed: body mismatch:%s\nBody: %s", util.FormatDiff(diffs), string(respBody))
 				return 0
 			}
 		}
@@ -114,36 +120,3 @@ func toJSON(v lua.LValue) ([]byte, error) {
 func toGoValue(v lua.LValue) interface{} {
 	return util.ToGoValue(v)
 }
-
-// subsetMatch checks if 'expected' is a subset of 'actual'
-func subsetMatch(expected, actual interface{}) bool {
-	switch exp := expected.(type) {
-	case map[string]interface{}:
-		act, ok := actual.(map[string]interface{})
-		if !ok {
-			return false
-		}
-		for k, v := range exp {
-			if !subsetMatch(v, act[k]) {
-				return false
-			}
-		}
-		return true
-	case []interface{}:
-		act, ok := actual.([]interface{})
-		if !ok || len(exp) > len(act) {
-			return false
-		}
-		// Simplified: Array match assumes exact order or subset check?
-		// For robustness, let's assume exact match for arrays for now, or implement deeper logic.
-		// Implementing exact match for array elements for simplicity.
-		for i, v := range exp {
-			if !subsetMatch(v, act[i]) {
-				return false
-			}
-		}
-		return true
-	default:
-		return expected == actual
-	}
-}
@@ -0,0 +1,188 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+	"github.com/chakradharkondapalli/topas/pkg/lua/util"
+)
+
+// Module drives the stub servers behind the mock services of an App: adding stubs at runtime, reading
+// the request journal and resetting both.
+type Module struct {
+	Client    client.Client
+	AppName   string
+	Namespace string
+	HTTP      *http.Client
+}
+
+func New(c client.Client, appName, namespace string) *Module {
+	return &Module{Client: c, AppName: appName, Namespace: namespace, HTTP: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (m *Module) Loader(L *lua.LState) int {
+	mod := L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"stub":     m.Stub,
+		"requests": m.Requests,
+		"reset":    m.Reset,
+	})
+	L.Push(mod)
+	return 1
+}
+
+// Stub adds a stub to a mock service, taking precedence over every stub added before it and those of the
+// App spec. The table has the fields of a StubSpec; a table response body is sent as JSON.
+//
+//	mock.stub("payments", {
+//	  request = { method = "POST", path = "/charges" },
+//	  response = { status = 402, body = { error = "card_declined" }, delay = "200ms" },
+//	})
+func (m *Module) Stub(L *lua.LState) int {
+	base := m.baseURL(L, L.CheckString(1))
+	stubTable := L.CheckTable(2)
+
+	// A table body is the JSON the stub answers with
+	if resp, ok := stubTable.RawGetString("response").(*lua.LTable); ok {
+		if body, ok := resp.RawGetString("body").(*lua.LTable); ok {
+			raw, err := util.ToJSON(body)
+			if err != nil {
+				L.RaiseError("failed to serialize stub body: %v", err)
+				return 0
+			}
+			resp.RawSetString("body", lua.LString(raw))
+		}
+	}
+	raw, err := util.ToJSON(stubTable)
+	if err != nil {
+		L.RaiseError("failed to serialize stub: %v", err)
+		return 0
+	}
+	var spec appv1alpha1.StubSpec
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		L.RaiseError("invalid stub: %v", err)
+		return 0
+	}
+	raw, _ = json.Marshal(spec)
+
+	if _, err := m.call(http.MethodPost, base+"stubs", raw); err != nil {
+		L.RaiseError("failed to add stub: %v", err)
+	}
+	return 0
+}
+
+// Requests returns the calls a mock service received, oldest first, as a list of tables with protocol,
+// method, path, query, headers, body, json (the decoded body, if it is JSON), stub and matched. An optional
+// filter table keeps only the calls whose method, path, protocol or stub equal the given values.
+func (m *Module) Requests(L *lua.LState) int {
+	base := m.baseURL(L, L.CheckString(1))
+	filter := L.OptTable(2, L.NewTable())
+
+	raw, err := m.call(http.MethodGet, base+"requests", nil)
+	if err != nil {
+		L.RaiseError("failed to read requests: %v", err)
+		return 0
+	}
+	var journal []map[string]interface{}
+	if err := json.Unmarshal(raw, &journal); err != nil {
+		L.RaiseError("failed to parse requests: %v", err)
+		return 0
+	}
+
+	result := L.NewTable()
+	for _, req := range journal {
+		keep := true
+		for _, key := range []string{"method", "path", "protocol", "stub"} {
+			want := filter.RawGetString(key)
+			if want == lua.LNil {
+				continue
+			}
+			got, _ := req[key].(string)
+			if key == "method" {
+				keep = keep && strings.EqualFold(got, want.String())
+			} else {
+				keep = keep && got == want.String()
+			}
+		}
+		if !keep {
+			continue
+		}
+		if body, _ := req["body"].(string); body != "" {
+			var decoded interface{}
+			if json.Unmarshal([]byte(body), &decoded) == nil {
+				req["json"] = decoded
+			}
+		}
+		result.Append(util.ToLuaValue(L, req))
+	}
+	L.Push(result)
+	return 1
+}
+
+// Reset drops the stubs added at runtime and clears the request journal of a mock service. Stubs from the
+// App spec stay in place.
+func (m *Module) Reset(L *lua.LState) int {
+	base := m.baseURL(L, L.CheckString(1))
+	for _, path := range []string{"stubs", "requests"} {
+		if _, err := m.call(http.MethodDelete, base+path, nil); err != nil {
+			L.RaiseError("failed to reset mock: %v", err)
+			return 0
+		}
+	}
+	return 0
+}
+
+// baseURL returns the admin API address of a mock service, raising an error if the App has no such mock.
+func (m *Module) baseURL(L *lua.LState, serviceName string) string {
+	app := &appv1alpha1.App{}
+	if err := m.Client.Get(context.Background(), types.NamespacedName{Name: m.AppName, Namespace: m.Namespace}, app); err != nil {
+		L.RaiseError("failed to get app: %v", err)
+		return ""
+	}
+	for _, svc := range app.Spec.Services {
+		if svc.Name != serviceName {
+			continue
+		}
+		if !svc.IsMock() {
+			L.RaiseError("service %s is not a mock", serviceName)
+			return ""
+		}
+		return fmt.Sprintf("http://%s-%s.%s.svc:%d%s", m.AppName, svc.Name, m.Namespace, svc.Port, appv1alpha1.MockAdminPrefix)
+	}
+	L.RaiseError("service not found: %s", serviceName)
+	return ""
+}
+
+func (m *Module) call(method, url string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := m.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s: %s: %s", method, url, resp.Status, strings.TrimSpace(string(raw)))
+	}
+	return raw, nil
+}
@@ -0,0 +1,53 @@
+// Package params hands scripts the params of their TestRun as a read-only table.
+//
+//	local base = params.base_url or "http://localhost:8080"
+//	for key, value in pairs(params()) do print(key, value) end
+package params
+
+import (
+	lua "github.com/yuin/gopher-lua"
+)
+
+type Module struct {
+	Values map[string]string
+}
+
+func New(values map[string]string) *Module {
+	return &Module{Values: values}
+}
+
+// Loader returns the params table, also reachable as the params global once Open has run.
+func (m *Module) Loader(L *lua.LState) int {
+	L.Push(m.table(L))
+	return 1
+}
+
+// Open sets the params global.
+func (m *Module) Open(L *lua.LState) {
+	L.SetGlobal("params", m.table(L))
+}
+
+// table builds an empty proxy over the values: reads go through __index, writes raise an error. As
+// pairs does not see through a proxy, calling the table returns a copy of the values to iterate.
+func (m *Module) table(L *lua.LState) *lua.LTable {
+	values := L.NewTable()
+	for k, v := range m.Values {
+		values.RawSetString(k, lua.LString(v))
+	}
+	mt := L.NewTable()
+	L.SetField(mt, "__index", values)
+	L.SetField(mt, "__newindex", L.NewFunction(func(L *lua.LState) int {
+		L.RaiseError("params are read-only")
+		return 0
+	}))
+	L.SetField(mt, "__call", L.NewFunction(func(L *lua.LState) int {
+		cp := L.NewTable()
+		values.ForEach(func(k, v lua.LValue) { cp.RawSet(k, v) })
+		L.Push(cp)
+		return 1
+	}))
+	L.SetField(mt, "__metatable", lua.LFalse)
+	proxy := L.NewTable()
+	L.SetMetatable(proxy, mt)
+	return proxy
+}
@@ -0,0 +1,351 @@
+// Package results collects the test cases and assertions of a script run and reports them to the
+// controller through the runner's termination message.
+package results
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+	"github.com/chakradharkondapalli/topas/pkg/report"
+)
+
+// maxMessageBytes is the size limit Kubernetes puts on a container's termination message.
+const maxMessageBytes = 4096
+
+// maxOutputBytes bounds the output kept per test case; the oldest output is dropped first.
+const maxOutputBytes = 64 * 1024
+
+// Recorder collects the outcome of a script run. A nil Recorder records nothing, so modules can count
+// assertions without checking whether results are wanted.
+type Recorder struct {
+	mu         sync.Mutex
+	start      time.Time
+	cases      []appv1alpha1.TestCaseResult
+	current    *appv1alpha1.TestCaseResult
+	caseStart  time.Time
+	assertions int32
+
+	// outputs holds the output of each of cases; caseOutput that of the running case, and scriptOutput
+	// what was printed outside of any case
+	outputs      []string
+	caseOutput   output
+	scriptOutput output
+
+	// Mask, when set, hides secret values in the failures and output the Recorder reports
+	Mask func(string) string
+}
+
+// output is the tail of what a script printed.
+type output struct {
+	buf     []byte
+	dropped bool
+}
+
+func (o *output) write(p []byte) {
+	o.buf = append(o.buf, p...)
+	if len(o.buf) > maxOutputBytes {
+		o.buf = append([]byte(nil), o.buf[len(o.buf)-maxOutputBytes:]...)
+		o.dropped = true
+	}
+}
+
+func (o *output) String() string {
+	if o.dropped {
+		return "... (earlier output dropped)\n" + string(o.buf)
+	}
+	return string(o.buf)
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{start: time.Now()}
+}
+
+// Assert counts one checked assertion against the running test case.
+func (r *Recorder) Assert() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.assertions++
+	if r.current != nil {
+		r.current.Assertions++
+	}
+}
+
+// Begin starts a test case.
+func (r *Recorder) Begin(name string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current = &appv1alpha1.TestCaseResult{Name: name}
+	r.caseStart = time.Now()
+	r.caseOutput = output{}
+}
+
+// End finishes the running test case, failed when err is not nil.
+func (r *Recorder) End(err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == nil {
+		return
+	}
+	tc := *r.current
+	tc.Duration = metav1.Duration{Duration: time.Since(r.caseStart)}
+	tc.State = appv1alpha1.TestCasePassed
+	if err != nil {
+		tc.State = appv1alpha1.TestCaseFailed
+		tc.Failure = Failure(err)
+	}
+	r.cases = append(r.cases, tc)
+	r.outputs = append(r.outputs, r.caseOutput.String())
+	r.current = nil
+}
+
+// Skip records a test case that did not run.
+func (r *Recorder) Skip(name string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cases = append(r.cases, appv1alpha1.TestCaseResult{Name: name, State: appv1alpha1.TestCaseSkipped})
+	r.outputs = append(r.outputs, "")
+}
+
+// Write captures output printed by the script, for the running test case if there is one.
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		r.caseOutput.write(p)
+	} else {
+		r.scriptOutput.write(p)
+	}
+	return len(p), nil
+}
+
+// Results returns the outcome of the run, given the error the script ended with. A script that declared
+// no test cases counts as a single test case named name.
+func (r *Recorder) Results(name string, scriptErr error) appv1alpha1.TestResults {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.results(name, scriptErr)
+}
+
+func (r *Recorder) results(name string, scriptErr error) appv1alpha1.TestResults {
+	elapsed := metav1.Duration{Duration: time.Since(r.start)}
+
+	var res appv1alpha1.TestResults
+	if len(r.cases) == 0 {
+		tc := appv1alpha1.TestCaseResult{Name: name, State: appv1alpha1.TestCasePassed, Assertions: r.assertions, Duration: elapsed}
+		if scriptErr != nil {
+			tc.State = appv1alpha1.TestCaseFailed
+			tc.Failure = Failure(scriptErr)
+		}
+		res.TestCases = []appv1alpha1.TestCaseResult{tc}
+	} else {
+		res.TestCases = append(res.TestCases, r.cases...)
+		if scriptErr != nil {
+			res.Error = Failure(scriptErr)
+		}
+	}
+
+	for i := range res.TestCases {
+		res.TestCases[i].Failure = r.maskFailure(res.TestCases[i].Failure)
+	}
+	res.Error = r.maskFailure(res.Error)
+
+	res.Summary = appv1alpha1.TestSummary{Assertions: r.assertions, Duration: elapsed}
+	for _, tc := range res.TestCases {
+		res.Summary.Total++
+		switch tc.State {
+		case appv1alpha1.TestCasePassed:
+			res.Summary.Passed++
+		case appv1alpha1.TestCaseFailed:
+			res.Summary.Failed++
+		case appv1alpha1.TestCaseSkipped:
+			res.Summary.Skipped++
+		}
+	}
+	return res
+}
+
+// Report returns the outcome of the run as Results does, along with the output the script printed.
+func (r *Recorder) Report(name string, scriptErr error) report.Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := r.results(name, scriptErr)
+
+	rep := report.Report{
+		Script:    name,
+		StartTime: r.start,
+		Summary:   res.Summary,
+		Error:     res.Error,
+	}
+	for i, tc := range res.TestCases {
+		c := report.TestCase{TestCaseResult: tc}
+		if len(r.cases) == 0 {
+			c.Output = r.scriptOutput.String()
+		} else {
+			c.Output = r.outputs[i]
+		}
+		rep.TestCases = append(rep.TestCases, c)
+	}
+	if len(r.cases) > 0 {
+		rep.Output = r.scriptOutput.String()
+	}
+	if r.Mask != nil {
+		for i := range rep.TestCases {
+			rep.TestCases[i].Output = r.Mask(rep.TestCases[i].Output)
+		}
+		rep.Output = r.Mask(rep.Output)
+	}
+	return rep
+}
+
+// maskFailure returns a copy of f with secret values masked.
+func (r *Recorder) maskFailure(f *appv1alpha1.TestFailure) *appv1alpha1.TestFailure {
+	if f == nil || r.Mask == nil {
+		return f
+	}
+	masked := *f
+	masked.Message = r.Mask(f.Message)
+	masked.StackTrace = r.Mask(f.StackTrace)
+	return &masked
+}
+
+// locationPattern matches the file:line prefix Lua puts on error messages
+var locationPattern = regexp.MustCompile(`^(\S+:\d+): `)
+
+// Failure describes an error raised by a script, with the position that raised it.
+func Failure(err error) *appv1alpha1.TestFailure {
+	f := &appv1alpha1.TestFailure{Message: err.Error()}
+	prefix := ""
+	var apiErr *lua.ApiError
+	if errors.As(err, &apiErr) {
+		// Keep the context the error was wrapped in, e.g. the hook that raised it
+		prefix = strings.TrimSuffix(err.Error(), apiErr.Error())
+		f.Message = apiErr.Object.String()
+		f.StackTrace = apiErr.StackTrace
+	}
+	if m := locationPattern.FindStringSubmatch(f.Message); m != nil {
+		f.Location = m[1]
+		f.Message = strings.TrimPrefix(f.Message, m[0])
+	}
+	f.Message = prefix + f.Message
+	return f
+}
+
+// Write stores the results as JSON at path, which is the runner's termination message file. Results that
+// do not fit the termination message lose their stack traces first, then their passing test cases, then
+// every test case, and last the end of the script error's message.
+func Write(path string, res appv1alpha1.TestResults) error {
+	raw, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	shrink := []func(){
+		func() {
+			for i := range res.TestCases {
+				if f := res.TestCases[i].Failure; f != nil {
+					f.StackTrace = ""
+				}
+			}
+			if res.Error != nil {
+				res.Error.StackTrace = ""
+			}
+		},
+		func() {
+			var failed []appv1alpha1.TestCaseResult
+			for _, tc := range res.TestCases {
+				if tc.State == appv1alpha1.TestCaseFailed {
+					failed = append(failed, tc)
+				}
+			}
+			res.TestCases = failed
+			res.Truncated = true
+		},
+		func() {
+			res.TestCases = nil
+			res.Truncated = true
+		},
+		func() {
+			// The script error is all that is left, and its message can be of any size
+			if res.Error != nil {
+				res.Error.Message = truncate(res.Error.Message, encodedLen(res.Error.Message)-(len(raw)-maxMessageBytes))
+			}
+		},
+	}
+	for _, step := range shrink {
+		if len(raw) <= maxMessageBytes {
+			break
+		}
+		step()
+		if raw, err = json.Marshal(res); err != nil {
+			return err
+		}
+	}
+	if len(raw) > maxMessageBytes {
+		return fmt.Errorf("results take %d bytes, more than the %d bytes of a termination message", len(raw), maxMessageBytes)
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
+
+// truncate cuts s so that it takes at most n bytes once encoded as JSON, marking where it was cut.
+func truncate(s string, n int) string {
+	const marker = "... (truncated)"
+	n -= len(marker)
+	for over := encodedLen(s) - n; over > 0 && s != ""; over = encodedLen(s) - n {
+		// Escaped characters take more than a byte, so cutting over bytes may not be enough at once
+		s = strings.ToValidUTF8(s[:max(len(s)-over, 0)], "")
+	}
+	return s + marker
+}
+
+// encodedLen is the size of s as a JSON string, without the quotes.
+func encodedLen(s string) int {
+	raw, _ := json.Marshal(s)
+	return len(raw) - 2
+}
+
+// OpenPrint replaces the global print with one that also captures what it prints.
+func (r *Recorder) OpenPrint(L *lua.LState) {
+	out := io.MultiWriter(os.Stdout, r)
+	L.SetGlobal("print", L.NewFunction(func(L *lua.LState) int {
+		parts := make([]string, L.GetTop())
+		for i := range parts {
+			parts[i] = L.ToStringMeta(L.Get(i + 1)).String()
+		}
+		fmt.Fprintln(out, strings.Join(parts, "\t"))
+		return 0
+	}))
+}
+
+// OpenAssert replaces the global assert with one that counts the assertions it checks.
+func (r *Recorder) OpenAssert(L *lua.LState) {
+	L.SetGlobal("assert", L.NewFunction(func(L *lua.LState) int {
+		r.Assert()
+		if !L.ToBool(1) {
+			L.RaiseError("%s", L.OptString(2, "assertion failed!"))
+			return 0
+		}
+		return L.GetTop()
+	}))
+}
@@ -0,0 +1,94 @@
+package results
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+func TestWrite(t *testing.T) {
+	cases := func(n int, state string, message string) []appv1alpha1.TestCaseResult {
+		var tcs []appv1alpha1.TestCaseResult
+		for range n {
+			tc := appv1alpha1.TestCaseResult{Name: strings.Repeat("case", 10), State: state}
+			if message != "" {
+				tc.Failure = &appv1alpha1.TestFailure{Message: message, StackTrace: strings.Repeat("trace", 20)}
+			}
+			tcs = append(tcs, tc)
+		}
+		return tcs
+	}
+
+	tests := []struct {
+		name      string
+		res       appv1alpha1.TestResults
+		wantCases int
+		truncated bool
+	}{
+		{
+			name:      "small results are kept whole",
+			res:       appv1alpha1.TestResults{TestCases: cases(3, appv1alpha1.TestCaseFailed, "boom")},
+			wantCases: 3,
+		},
+		{
+			name: "passing cases are dropped first",
+			res: appv1alpha1.TestResults{TestCases: append(cases(100, appv1alpha1.TestCasePassed, ""),
+				cases(2, appv1alpha1.TestCaseFailed, "boom")...)},
+			wantCases: 2,
+			truncated: true,
+		},
+		{
+			name:      "every case is dropped when the failures do not fit",
+			res:       appv1alpha1.TestResults{TestCases: cases(100, appv1alpha1.TestCaseFailed, "boom")},
+			truncated: true,
+		},
+		{
+			name: "a long script error is cut",
+			res: appv1alpha1.TestResults{
+				TestCases: cases(100, appv1alpha1.TestCaseFailed, "boom"),
+				Error:     &appv1alpha1.TestFailure{Message: strings.Repeat("bad <input>\n", 2000)},
+			},
+			truncated: true,
+		},
+		{
+			name: "a long script error of multi-byte characters is cut",
+			res: appv1alpha1.TestResults{
+				Error: &appv1alpha1.TestFailure{Message: strings.Repeat("é", 5000)},
+			},
+			truncated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "termination-log")
+			if err := Write(path, tt.res); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(raw) > maxMessageBytes {
+				t.Errorf("wrote %d bytes, more than %d", len(raw), maxMessageBytes)
+			}
+
+			var got appv1alpha1.TestResults
+			if err := json.Unmarshal(raw, &got); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if len(got.TestCases) != tt.wantCases {
+				t.Errorf("kept %d test cases, want %d", len(got.TestCases), tt.wantCases)
+			}
+			if got.Truncated != tt.truncated {
+				t.Errorf("truncated = %v, want %v", got.Truncated, tt.truncated)
+			}
+			if tt.res.Error != nil && !strings.HasSuffix(got.Error.Message, "... (truncated)") {
+				t.Errorf("script error message was not marked as cut: %q", got.Error.Message)
+			}
+		})
+	}
+}
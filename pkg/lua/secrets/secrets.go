@@ -0,0 +1,133 @@
+// Package secrets gives scripts the values of the Secrets their TestRun references, and masks those
+// values in everything the run prints or reports.
+//
+//	local secrets = require("secrets")
+//	local password = secrets.get("db-credentials", "password")
+package secrets
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// masked replaces secret values in output.
+const masked = "****"
+
+type Module struct {
+	// Dir holds one directory per Secret, with a file per key, as the runner pod mounts them
+	Dir string
+
+	mu     sync.Mutex
+	values []string
+}
+
+func New(dir string) *Module {
+	return &Module{Dir: dir}
+}
+
+func (m *Module) Loader(L *lua.LState) int {
+	mod := L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"get": m.Get,
+	})
+	L.Push(mod)
+	return 1
+}
+
+// Get returns the value of a key of a Secret: secrets.get(name, key). The value is masked from then on.
+func (m *Module) Get(L *lua.LState) int {
+	name := L.CheckString(1)
+	key := L.CheckString(2)
+	if m.Dir == "" || !validName(name) || !validName(key) {
+		L.RaiseError("secret %s/%s is not available to this run", name, key)
+		return 0
+	}
+	raw, err := os.ReadFile(filepath.Join(m.Dir, name, key))
+	if err != nil {
+		L.RaiseError("secret %s/%s is not available to this run", name, key)
+		return 0
+	}
+	value := string(raw)
+	m.add(value)
+	L.Push(lua.LString(value))
+	return 1
+}
+
+// validName keeps names from reaching outside of Dir.
+func validName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ".") && !strings.ContainsAny(name, `/\`)
+}
+
+func (m *Module) add(value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// A value read back with its trailing newline is masked without it as well
+	for _, v := range []string{value, strings.TrimRight(value, "\r\n")} {
+		if v == "" || contains(m.values, v) {
+			continue
+		}
+		m.values = append(m.values, v)
+	}
+	// Longest first, so a value holding another is masked whole
+	sort.Slice(m.values, func(i, j int) bool { return len(m.values[i]) > len(m.values[j]) })
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Mask replaces the secret values read so far in s. A nil Module masks nothing.
+func (m *Module) Mask(s string) string {
+	if m == nil {
+		return s
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, v := range m.values {
+		s = strings.ReplaceAll(s, v, masked)
+	}
+	return s
+}
+
+// MaskFile routes what is written to *f through Mask, a line at a time, until the returned function is
+// called; it then restores *f once everything written has been flushed. Masking whole lines keeps a
+// value split across two writes from slipping through.
+func (m *Module) MaskFile(f **os.File) (restore func(), err error) {
+	orig := *f
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	*f = w
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		in := bufio.NewReader(r)
+		for {
+			line, err := in.ReadString('\n')
+			if line != "" {
+				io.WriteString(orig, m.Mask(line)) //nolint:errcheck
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return func() {
+		*f = orig
+		w.Close()
+		<-done
+		r.Close()
+	}, nil
+}
@@ -2,6 +2,10 @@ package sut
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	lua "github.com/yuin/gopher-lua"
@@ -10,22 +14,38 @@ import (
 
 	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
 )
 
+// pollInterval is how often the calls that wait on the App check it again.
+var pollInterval = time.Second
+
 type Module struct {
 	Client    client.Client
 	AppName   string
 	Namespace string
+
+	// Access is the TestRun's access to the App: calls that change it are refused unless it is Exclusive
+	Access string
+	// Lease, when set, is the App lease an Exclusive run must hold under the identity Holder to change it
+	Lease  *types.NamespacedName
+	Holder string
 }
 
 func New(c client.Client, appName, namespace string) *Module {
-	return &Module{Client: c, AppName: appName, Namespace: namespace}
+	return &Module{Client: c, AppName: appName, Namespace: namespace, Access: appv1alpha1.AppAccessExclusive}
 }
 
 func (m *Module) Loader(L *lua.LState) int {
 	mod := L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
-		"apply": m.Apply,
-		"wait":  m.Wait,
+		"apply":   m.Apply,
+		"wait":    m.Wait,
+		"version": m.Version,
+		"pause":   m.Pause,
+		"resume":  m.Resume,
+		"promote": m.Promote,
+		"abort":   m.Abort,
+		"rollout": m.Rollout,
 	})
 	L.Push(mod)
 	return 1
@@ -34,6 +54,7 @@ func (m *Module) Loader(L *lua.LState) int {
 func (m *Module) Apply(L *lua.LState) int {
 	serviceName := L.CheckString(1)
 	specTable := L.CheckTable(2)
+	m.checkExclusive(L, "apply")
 
 	ctx := context.Background()
 	app := &appv1alpha1.App{}
@@ -78,23 +99,284 @@ func (m *Module) Apply(L *lua.LState) int {
 	return 0
 }
 
-func (m *Module) Wait(L *lua.LState) int {
+// Pause stops the controller from reconciling the App's workloads, so the test can break them directly.
+func (m *Module) Pause(L *lua.LState) int {
+	m.setPaused(L, true)
+	return 0
+}
+
+// Resume lets the controller reconcile the App again; anything changed while paused is reverted and
+// reported on the App's Drifted condition.
+func (m *Module) Resume(L *lua.LState) int {
+	m.setPaused(L, false)
+	return 0
+}
+
+func (m *Module) setPaused(L *lua.LState, paused bool) {
+	if paused {
+		m.checkExclusive(L, "pause")
+	} else {
+		m.checkExclusive(L, "resume")
+	}
+	ctx := context.Background()
+	app := &appv1alpha1.App{}
+	if err := m.Client.Get(ctx, types.NamespacedName{Name: m.AppName, Namespace: m.Namespace}, app); err != nil {
+		L.RaiseError("failed to get app: %v", err)
+		return
+	}
+	if app.Spec.Paused == paused {
+		return
+	}
+	app.Spec.Paused = paused
+	if err := m.Client.Update(ctx, app); err != nil {
+		L.RaiseError("failed to update app: %v", err)
+	}
+}
+
+// Promote finishes the canary or blue/green rollout of a service: the new version replaces the stable one.
+// It returns once the controller has acted on the request, raising an error after timeout (default 60s).
+func (m *Module) Promote(L *lua.LState) int {
+	m.requestRollout(L, L.CheckString(1), appv1alpha1.RolloutRequestPromote)
+	return 0
+}
+
+// Abort abandons the canary or blue/green rollout of a service, leaving the stable version serving alone
+// until the service spec changes again. It returns once the controller has acted on the request, raising
+// an error after timeout (default 60s).
+func (m *Module) Abort(L *lua.LState) int {
+	m.requestRollout(L, L.CheckString(1), appv1alpha1.RolloutRequestAbort)
+	return 0
+}
+
+// Rollout returns the state of a service's canary or blue/green rollout as a table with strategy, phase,
+// stable and new versions, replicas and ready_replicas of the new version, or nil when none is in progress.
+func (m *Module) Rollout(L *lua.LState) int {
 	serviceName := L.CheckString(1)
-	// timeout := L.OptString(2, "60s") // TODO: Implement timeout parsing
 
+	app := &appv1alpha1.App{}
+	if err := m.Client.Get(context.Background(), types.NamespacedName{Name: m.AppName, Namespace: m.Namespace}, app); err != nil {
+		L.RaiseError("failed to get app: %v", err)
+		return 0
+	}
+	rollout := serviceRollout(app, serviceName)
+	if rollout == nil {
+		L.Push(lua.LNil)
+		return 1
+	}
+	t := L.NewTable()
+	t.RawSetString("strategy", lua.LString(rollout.Strategy))
+	t.RawSetString("phase", lua.LString(rollout.Phase))
+	t.RawSetString("stable", lua.LString(rollout.StableVersion))
+	t.RawSetString("new", lua.LString(rollout.NewVersion))
+	t.RawSetString("replicas", lua.LNumber(rollout.NewReplicas))
+	t.RawSetString("ready_replicas", lua.LNumber(rollout.NewReadyReplicas))
+	L.Push(t)
+	return 1
+}
+
+// requestRollout asks the controller to promote or abort a service's rollout, then waits until the
+// request is consumed and the App status no longer shows the rollout awaiting promotion. The controller
+// leaves a paused App alone, so the request fails at once on one.
+func (m *Module) requestRollout(L *lua.LState, serviceName, request string) {
+	call := strings.ToLower(request)
+	timeout, err := time.ParseDuration(L.OptString(2, "60s"))
+	if err != nil {
+		L.ArgError(2, "invalid timeout: "+err.Error())
+		return
+	}
+	m.checkExclusive(L, call)
 	ctx := context.Background()
-	deploymentName := m.AppName + "-" + serviceName
+	key := types.NamespacedName{Name: m.AppName, Namespace: m.Namespace}
+	annotation := appv1alpha1.RolloutRequestAnnotationPrefix + serviceName
+
+	app := &appv1alpha1.App{}
+	if err := m.Client.Get(ctx, key, app); err != nil {
+		L.RaiseError("failed to get app: %v", err)
+		return
+	}
+	i := slices.IndexFunc(app.Spec.Services, func(s appv1alpha1.ServiceSpec) bool { return s.Name == serviceName })
+	if i < 0 {
+		L.RaiseError("service not found: %s", serviceName)
+		return
+	}
+	if app.Spec.Services[i].RolloutStrategyType() == appv1alpha1.RolloutStrategyRollingUpdate {
+		L.RaiseError("service %s has no canary or blue/green strategy", serviceName)
+		return
+	}
+	if app.Spec.Paused {
+		L.RaiseError("sut.%s: App %s is paused; call sut.resume first", call, m.AppName)
+		return
+	}
+	if app.Annotations == nil {
+		app.Annotations = map[string]string{}
+	}
+	app.Annotations[annotation] = request
+	if err := m.Client.Update(ctx, app); err != nil {
+		L.RaiseError("failed to update app: %v", err)
+		return
+	}
 
-	// Poll for readiness
+	deadline := time.Now().Add(timeout)
 	for {
-		dep := &appsv1.Deployment{}
-		err := m.Client.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: m.Namespace}, dep)
+		time.Sleep(pollInterval)
+		err := m.Client.Get(ctx, key, app)
 		if err == nil {
-			if dep.Status.ReadyReplicas == *dep.Spec.Replicas {
-				return 0 // Ready
+			if app.Spec.Paused {
+				L.RaiseError("sut.%s: App %s was paused before the controller acted on the request", call, m.AppName)
+				return
+			}
+			_, pending := app.Annotations[annotation]
+			if rollout := serviceRollout(app, serviceName); !pending && (rollout == nil || rollout.Phase != appv1alpha1.RolloutPhaseAwaitingPromotion) {
+				return
+			}
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				L.RaiseError("sut.%s: %s not done after %s: failed to get app: %v", call, serviceName, timeout, err)
+			} else {
+				L.RaiseError("sut.%s: the controller did not act on the request for %s within %s", call, serviceName, timeout)
+			}
+			return
+		}
+	}
+}
+
+// checkExclusive raises an error unless the run may change the App: its access must be Exclusive and,
+// on an App shared with other runs, the App lease held by the run.
+func (m *Module) checkExclusive(L *lua.LState, call string) {
+	if m.Access != appv1alpha1.AppAccessExclusive {
+		L.RaiseError("sut.%s changes App %s, which the TestRun only has %s access to; set spec.appAccess to %s",
+			call, m.AppName, m.Access, appv1alpha1.AppAccessExclusive)
+		return
+	}
+	if m.Lease == nil {
+		return
+	}
+	lease := &coordinationv1.Lease{}
+	if err := m.Client.Get(context.Background(), *m.Lease, lease); err != nil {
+		L.RaiseError("sut.%s: cannot check the lease on App %s: %v", call, m.AppName, err)
+		return
+	}
+	spec := lease.Spec
+	if spec.HolderIdentity == nil || *spec.HolderIdentity != m.Holder {
+		L.RaiseError("sut.%s: the TestRun does not hold the lease on App %s", call, m.AppName)
+		return
+	}
+	if spec.RenewTime != nil && spec.LeaseDurationSeconds != nil &&
+		time.Since(spec.RenewTime.Time) > time.Duration(*spec.LeaseDurationSeconds)*time.Second {
+		L.RaiseError("sut.%s: the lease of the TestRun on App %s expired", call, m.AppName)
+	}
+}
+
+// serviceRollout returns the rollout reported for a service in the App status, if any.
+func serviceRollout(app *appv1alpha1.App, serviceName string) *appv1alpha1.RolloutStatus {
+	for _, c := range app.Status.Components {
+		if c.Kind == appv1alpha1.ComponentKindService && c.Name == serviceName {
+			return c.Rollout
+		}
+	}
+	return nil
+}
+
+// Wait blocks until a service or database of the App is ready, raising an error once timeout (default
+// 60s) has passed. A service is ready when its Deployment's replicas are and its desired version landed;
+// a database when the App status reports it Ready, whether it runs as a Deployment or, with storage, as a
+// StatefulSet.
+func (m *Module) Wait(L *lua.LState) int {
+	name := L.CheckString(1)
+	timeout, err := time.ParseDuration(L.OptString(2, "60s"))
+	if err != nil {
+		L.ArgError(2, "invalid timeout: "+err.Error())
+		return 0
+	}
+
+	ctx := context.Background()
+	deadline := time.Now().Add(timeout)
+	for {
+		ready, err := m.ready(ctx, name)
+		if ready {
+			return 0
+		}
+		if errors.Is(err, errUnknownComponent) {
+			L.RaiseError("sut.wait: %v", err)
+			return 0
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				L.RaiseError("sut.wait: %s not ready after %s: %v", name, timeout, err)
+			} else {
+				L.RaiseError("sut.wait: %s not ready after %s", name, timeout)
+			}
+			return 0
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// errUnknownComponent is returned by ready for a name that is neither a service nor a database of the App.
+var errUnknownComponent = errors.New("no such service or database")
+
+// ready reports whether the named service or database of the App is ready.
+func (m *Module) ready(ctx context.Context, name string) (bool, error) {
+	app := &appv1alpha1.App{}
+	if err := m.Client.Get(ctx, types.NamespacedName{Name: m.AppName, Namespace: m.Namespace}, app); err != nil {
+		return false, fmt.Errorf("failed to get app: %w", err)
+	}
+
+	if slices.ContainsFunc(app.Spec.Databases, func(db appv1alpha1.DatabaseSpec) bool { return db.Name == name }) {
+		for _, c := range app.Status.Components {
+			if c.Kind == appv1alpha1.ComponentKindDatabase && c.Name == name {
+				return c.Ready, nil
+			}
+		}
+		return false, nil
+	}
+
+	i := slices.IndexFunc(app.Spec.Services, func(s appv1alpha1.ServiceSpec) bool { return s.Name == name })
+	if i < 0 {
+		return false, fmt.Errorf("%w named %s in App %s", errUnknownComponent, name, m.AppName)
+	}
+	dep := &appsv1.Deployment{}
+	if err := m.Client.Get(ctx, types.NamespacedName{Name: m.AppName + "-" + name, Namespace: m.Namespace}, dep); err != nil {
+		return false, fmt.Errorf("failed to get deployment: %w", err)
+	}
+	desired := int32(1)
+	if dep.Spec.Replicas != nil {
+		desired = *dep.Spec.Replicas
+	}
+	return dep.Status.ReadyReplicas == desired && versionLanded(app, app.Spec.Services[i]), nil
+}
+
+// Version returns the version the service was last observed running, or nil before its first rollout completes.
+func (m *Module) Version(L *lua.LState) int {
+	serviceName := L.CheckString(1)
+
+	app := &appv1alpha1.App{}
+	if err := m.Client.Get(context.Background(), types.NamespacedName{Name: m.AppName, Namespace: m.Namespace}, app); err != nil {
+		L.RaiseError("failed to get app: %v", err)
+		return 0
+	}
+	for _, c := range app.Status.Components {
+		if c.Kind == appv1alpha1.ComponentKindService && c.Name == serviceName && c.ObservedVersion != "" {
+			L.Push(lua.LString(c.ObservedVersion))
+			return 1
+		}
+	}
+	L.Push(lua.LNil)
+	return 1
+}
+
+// versionLanded reports whether the App status shows the service running its desired version, or
+// the desired version ready in a canary or preview and waiting on sut.promote, or an aborted rollout.
+func versionLanded(app *appv1alpha1.App, svc appv1alpha1.ServiceSpec) bool {
+	for _, c := range app.Status.Components {
+		if c.Kind == appv1alpha1.ComponentKindService && c.Name == svc.Name {
+			if r := c.Rollout; r != nil {
+				return r.Phase == appv1alpha1.RolloutPhaseAborted ||
+					r.Phase == appv1alpha1.RolloutPhaseAwaitingPromotion && r.NewVersion == svc.DesiredVersion() && r.NewReadyReplicas == r.NewReplicas
 			}
+			return c.ObservedVersion == svc.DesiredVersion()
 		}
-		time.Sleep(1 * time.Second)
-		// Check for timeout
 	}
+	return false
 }
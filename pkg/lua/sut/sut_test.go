@@ -0,0 +1,223 @@
+package sut
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+func init() {
+	pollInterval = 10 * time.Millisecond
+}
+
+// newApp returns an App with a service api and a database db kept on a volume, so that it runs as a
+// StatefulSet and has no Deployment.
+func newApp(dbReady bool) *appv1alpha1.App {
+	return &appv1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "default"},
+		Spec: appv1alpha1.AppSpec{
+			Services: []appv1alpha1.ServiceSpec{{Name: "api", Image: "shop/api", Version: "v1"}},
+			Databases: []appv1alpha1.DatabaseSpec{{
+				Name:    "db",
+				Image:   "postgres:16-alpine",
+				Storage: &appv1alpha1.DatabaseStorageSpec{Size: resource.MustParse("1Gi")},
+			}},
+		},
+		Status: appv1alpha1.AppStatus{Components: []appv1alpha1.ComponentStatus{
+			{Name: "db", Kind: appv1alpha1.ComponentKindDatabase, Ready: dbReady},
+			{Name: "api", Kind: appv1alpha1.ComponentKindService, ObservedVersion: "v1"},
+		}},
+	}
+}
+
+func newClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := appv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithStatusSubresource(&appv1alpha1.App{}).Build()
+}
+
+func TestWait(t *testing.T) {
+	replicas := int32(2)
+	readyAPI := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "shop-api", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 2},
+	}
+
+	tests := []struct {
+		name   string
+		objs   []client.Object
+		script string
+		// want is part of the error raised, empty when the wait returns
+		want string
+	}{
+		{
+			name:   "a database with storage is ready",
+			objs:   []client.Object{newApp(true)},
+			script: `sut.wait("db", "1s")`,
+		},
+		{
+			name:   "a database with storage times out",
+			objs:   []client.Object{newApp(false)},
+			script: `sut.wait("db", "50ms")`,
+			want:   "sut.wait: db not ready after 50ms",
+		},
+		{
+			name:   "a service is ready",
+			objs:   []client.Object{newApp(true), readyAPI},
+			script: `sut.wait("api", "1s")`,
+		},
+		{
+			name:   "a service without a Deployment times out",
+			objs:   []client.Object{newApp(true)},
+			script: `sut.wait("api", "50ms")`,
+			want:   "api not ready after 50ms: failed to get deployment",
+		},
+		{
+			name:   "an unknown name fails at once",
+			objs:   []client.Object{newApp(true)},
+			script: `sut.wait("cache", "1h")`,
+			want:   "no such service or database named cache in App shop",
+		},
+		{
+			name:   "an invalid timeout",
+			objs:   []client.Object{newApp(true)},
+			script: `sut.wait("db", "soon")`,
+			want:   "invalid timeout",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			L := lua.NewState()
+			defer L.Close()
+			L.PreloadModule("sut", New(newClient(t, tt.objs...), "shop", "default").Loader)
+
+			err := L.DoString(`local sut = require("sut")` + "\n" + tt.script)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Fatalf("expected the wait to return, got %v", err)
+			case tt.want != "" && err == nil:
+				t.Fatalf("expected the wait to fail with %q", tt.want)
+			case err != nil && !strings.Contains(err.Error(), tt.want):
+				t.Errorf("got %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromote(t *testing.T) {
+	canaryApp := func(paused bool) *appv1alpha1.App {
+		app := newApp(true)
+		app.Spec.Paused = paused
+		app.Spec.Services[0].Strategy = &appv1alpha1.RolloutStrategy{Type: appv1alpha1.RolloutStrategyCanary}
+		return app
+	}
+
+	tests := []struct {
+		name string
+		app  *appv1alpha1.App
+		// act, when set, plays the controller acting on the request
+		act    bool
+		script string
+		want   string
+	}{
+		{
+			name:   "the controller acts on the request",
+			app:    canaryApp(false),
+			act:    true,
+			script: `sut.promote("api", "1s")`,
+		},
+		{
+			name:   "the controller never acts on the request",
+			app:    canaryApp(false),
+			script: `sut.promote("api", "50ms")`,
+			want:   "sut.promote: the controller did not act on the request for api within 50ms",
+		},
+		{
+			name:   "a paused App fails at once",
+			app:    canaryApp(true),
+			script: `sut.abort("api", "1h")`,
+			want:   "sut.abort: App shop is paused",
+		},
+		{
+			name:   "a service without canary or blue/green strategy",
+			app:    newApp(true),
+			script: `sut.promote("api")`,
+			want:   "service api has no canary or blue/green strategy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClient(t, tt.app)
+			if tt.act {
+				stop, done := make(chan struct{}), make(chan struct{})
+				go func() {
+					defer close(done)
+					consumeRolloutRequests(t, c, stop)
+				}()
+				defer func() {
+					close(stop)
+					<-done
+				}()
+			}
+			L := lua.NewState()
+			defer L.Close()
+			L.PreloadModule("sut", New(c, "shop", "default").Loader)
+
+			err := L.DoString(`local sut = require("sut")` + "\n" + tt.script)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Fatalf("expected the request to be done, got %v", err)
+			case tt.want != "" && err == nil:
+				t.Fatalf("expected the request to fail with %q", tt.want)
+			case err != nil && !strings.Contains(err.Error(), tt.want):
+				t.Errorf("got %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// consumeRolloutRequests removes the rollout request annotations of the App until stop is closed, as the
+// controller does once it acted on them.
+func consumeRolloutRequests(t *testing.T, c client.Client, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(pollInterval):
+		}
+		app := &appv1alpha1.App{}
+		if err := c.Get(context.Background(), types.NamespacedName{Name: "shop", Namespace: "default"}, app); err != nil {
+			t.Error(err)
+			return
+		}
+		for k := range app.Annotations {
+			if strings.HasPrefix(k, appv1alpha1.RolloutRequestAnnotationPrefix) {
+				delete(app.Annotations, k)
+				if err := c.Update(context.Background(), app); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// Package test gives scripts a describe/it structure. Running the script only declares the test cases;
+// the runner then runs each case on its own, so a failing case is recorded and the others still run.
+//
+//	local test = require("test")
+//	test.describe("orders", function()
+//	  test.before_each(function() db.exec("main-db", "TRUNCATE orders") end)
+//	  test.it("creates an order", function() ... end)
+//	  test.skip("refunds an order", function() ... end)
+//	end)
+package test
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+	"github.com/chakradharkondapalli/topas/pkg/lua/results"
+)
+
+// Module declares the test cases of a script and runs them once the script has finished.
+type Module struct {
+	Results *results.Recorder
+	// Tags, when set, runs only the cases whose name or enclosing describe names hold one of them as #tag
+	Tags []string
+
+	root    *block
+	current *block
+	running bool
+	only    bool
+}
+
+// block is a describe block, or the script itself at the root.
+type block struct {
+	name     string
+	parent   *block
+	children []entry
+
+	beforeAll  []*lua.LFunction
+	afterAll   []*lua.LFunction
+	beforeEach []*lua.LFunction
+	afterEach  []*lua.LFunction
+}
+
+// entry is either a nested block or a test case, in the order they were declared.
+type entry struct {
+	block *block
+	test  *testCase
+}
+
+type testCase struct {
+	name string
+	fn   *lua.LFunction
+	skip bool
+	only bool
+}
+
+func New() *Module {
+	root := &block{}
+	return &Module{root: root, current: root}
+}
+
+func (m *Module) Loader(L *lua.LState) int {
+	mod := L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"describe":    m.Describe,
+		"it":          m.It,
+		"skip":        m.Skip,
+		"only":        m.Only,
+		"before_all":  m.hook(func(b *block, fn *lua.LFunction) { b.beforeAll = append(b.beforeAll, fn) }),
+		"after_all":   m.hook(func(b *block, fn *lua.LFunction) { b.afterAll = append(b.afterAll, fn) }),
+		"before_each": m.hook(func(b *block, fn *lua.LFunction) { b.beforeEach = append(b.beforeEach, fn) }),
+		"after_each":  m.hook(func(b *block, fn *lua.LFunction) { b.afterEach = append(b.afterEach, fn) }),
+	})
+	L.Push(mod)
+	return 1
+}
+
+// Describe groups test cases and hooks under a name. The function runs right away to declare them.
+func (m *Module) Describe(L *lua.LState) int {
+	name := L.CheckString(1)
+	fn := L.CheckFunction(2)
+	m.checkDeclaring(L, "describe")
+
+	b := &block{name: name, parent: m.current}
+	m.current.children = append(m.current.children, entry{block: b})
+	m.current = b
+	defer func() { m.current = b.parent }()
+	L.Push(fn)
+	L.Call(0, 0)
+	return 0
+}
+
+// It declares a test case. A case without a function is skipped.
+func (m *Module) It(L *lua.LState) int {
+	m.declare(L, "it", false, false)
+	return 0
+}
+
+// Skip declares a test case that is reported as skipped without running.
+func (m *Module) Skip(L *lua.LState) int {
+	m.declare(L, "skip", true, false)
+	return 0
+}
+
+// Only declares a test case that runs on its own: once a script has one, every case not declared with
+// only is skipped.
+func (m *Module) Only(L *lua.LState) int {
+	m.declare(L, "only", false, true)
+	m.only = true
+	return 0
+}
+
+func (m *Module) declare(L *lua.LState, fname string, skip, only bool) {
+	name := L.CheckString(1)
+	fn := L.OptFunction(2, nil)
+	m.checkDeclaring(L, fname)
+	tc := &testCase{name: name, fn: fn, skip: skip || fn == nil, only: only}
+	m.current.children = append(m.current.children, entry{test: tc})
+}
+
+// hook returns the Lua function adding a hook to the enclosing describe block.
+func (m *Module) hook(add func(*block, *lua.LFunction)) lua.LGFunction {
+	return func(L *lua.LState) int {
+		fn := L.CheckFunction(1)
+		m.checkDeclaring(L, "hooks")
+		add(m.current, fn)
+		return 0
+	}
+}
+
+func (m *Module) checkDeclaring(L *lua.LState, what string) {
+	if m.running {
+		L.RaiseError("%s must be declared at the top of the script or in a describe block, not in a test case or hook", what)
+	}
+}
+
+// Run runs the declared test cases in order and records each one. before_all and after_all hooks run
+// once per block around its cases; before_each hooks run before every case from the outermost block in,
+// after_each hooks after it from the innermost block out. A case fails on the first error raised by
+// its before_each hooks or its function, or by an after_each hook, which always run.
+func (m *Module) Run(L *lua.LState) {
+	m.running = true
+	defer func() { m.running = false }()
+	m.runBlock(L, m.root)
+}
+
+func (m *Module) runBlock(L *lua.LState, b *block) {
+	if !m.runnable(b) {
+		m.skipBlock(b)
+		return
+	}
+
+	// 1. before_all hooks; when one fails, every case of the block fails with its error
+	var setupErr error
+	for _, fn := range b.beforeAll {
+		if setupErr = call(L, fn); setupErr != nil {
+			setupErr = fmt.Errorf("before_all: %w", setupErr)
+			break
+		}
+	}
+
+	// 2. Cases and nested blocks in declaration order
+	if setupErr != nil {
+		m.failBlock(b, setupErr)
+	} else {
+		for _, e := range b.children {
+			switch {
+			case e.block != nil:
+				m.runBlock(L, e.block)
+			case m.skipped(b, e.test):
+				m.Results.Skip(fullName(b, e.test.name))
+			default:
+				m.runCase(L, b, e.test)
+			}
+		}
+	}
+
+	// 3. after_all hooks always run; a failure is reported as a case of its own
+	for _, fn := range b.afterAll {
+		if err := call(L, fn); err != nil {
+			m.Results.Begin(fullName(b, "after_all"))
+			m.Results.End(fmt.Errorf("after_all: %w", err))
+			break
+		}
+	}
+}
+
+func (m *Module) runCase(L *lua.LState, b *block, tc *testCase) {
+	m.Results.Begin(fullName(b, tc.name))
+	fmt.Printf("--- %s\n", fullName(b, tc.name))
+
+	var chain []*block
+	for p := b; p != nil; p = p.parent {
+		chain = append([]*block{p}, chain...)
+	}
+
+	var err error
+	for _, p := range chain {
+		for _, fn := range p.beforeEach {
+			if err == nil {
+				err = call(L, fn)
+			}
+		}
+	}
+	if err == nil {
+		err = call(L, tc.fn)
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, fn := range chain[i].afterEach {
+			if hookErr := call(L, fn); err == nil && hookErr != nil {
+				err = fmt.Errorf("after_each: %w", hookErr)
+			}
+		}
+	}
+
+	if err != nil {
+		fmt.Printf("FAIL %s: %s\n", fullName(b, tc.name), results.Failure(err).Message)
+	}
+	m.Results.End(err)
+}
+
+// failBlock records every case of b, nested ones included, as failed with err without running it.
+func (m *Module) failBlock(b *block, err error) {
+	for _, e := range b.children {
+		switch {
+		case e.block != nil:
+			m.failBlock(e.block, err)
+		case m.skipped(b, e.test):
+			m.Results.Skip(fullName(b, e.test.name))
+		default:
+			m.Results.Begin(fullName(b, e.test.name))
+			m.Results.End(err)
+		}
+	}
+}
+
+func (m *Module) skipBlock(b *block) {
+	for _, e := range b.children {
+		if e.block != nil {
+			m.skipBlock(e.block)
+		} else {
+			m.Results.Skip(fullName(b, e.test.name))
+		}
+	}
+}
+
+func (m *Module) skipped(b *block, tc *testCase) bool {
+	return tc.skip || (m.only && !tc.only) || !m.selected(fullName(b, tc.name))
+}
+
+var tagPattern = regexp.MustCompile(`#([\w-]+)`)
+
+// selected reports whether a case with the given full name carries one of the wanted tags.
+func (m *Module) selected(name string) bool {
+	if len(m.Tags) == 0 {
+		return true
+	}
+	for _, match := range tagPattern.FindAllStringSubmatch(name, -1) {
+		if slices.Contains(m.Tags, match[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// runnable reports whether any case of b, nested ones included, is going to run.
+func (m *Module) runnable(b *block) bool {
+	for _, e := range b.children {
+		if e.block != nil && m.runnable(e.block) || e.test != nil && !m.skipped(b, e.test) {
+			return true
+		}
+	}
+	return false
+}
+
+func fullName(b *block, name string) string {
+	parts := []string{name}
+	for p := b; p != nil && p.parent != nil; p = p.parent {
+		parts = append([]string{p.name}, parts...)
+	}
+	return strings.Join(parts, appv1alpha1.TestCaseNameSeparator)
+}
+
+// call runs fn in protected mode, so an error ends only the case or hook that raised it.
+func call(L *lua.LState, fn *lua.LFunction) error {
+	return L.CallByParam(lua.P{Fn: fn, NRet: 0, Protect: true})
+}
@@ -0,0 +1,223 @@
+package test
+
+import (
+	"testing"
+
+	lua "github.com/yuin/gopher-lua"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+	"github.com/chakradharkondapalli/topas/pkg/lua/results"
+)
+
+// outcome is the state of a recorded test case and the message of its failure, if any.
+type outcome struct {
+	state   string
+	message string
+}
+
+// run declares the cases of script, runs them with the given tags and returns what was recorded, by
+// test case name.
+func run(t *testing.T, script string, tags ...string) map[string]outcome {
+	t.Helper()
+	L := lua.NewState()
+	defer L.Close()
+
+	rec := results.NewRecorder()
+	m := New()
+	m.Results = rec
+	m.Tags = tags
+	L.PreloadModule("test", m.Loader)
+	if err := L.DoString(script); err != nil {
+		t.Fatalf("declaring the test cases: %v", err)
+	}
+	m.Run(L)
+
+	got := map[string]outcome{}
+	for _, tc := range rec.Results("script", nil).TestCases {
+		o := outcome{state: tc.State}
+		if tc.Failure != nil {
+			o.message = tc.Failure.Message
+		}
+		got[tc.Name] = o
+	}
+	return got
+}
+
+func check(t *testing.T, got, want map[string]outcome) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("recorded %d test cases, want %d: %v", len(got), len(want), got)
+	}
+	for name, w := range want {
+		if g, ok := got[name]; !ok {
+			t.Errorf("%q was not recorded", name)
+		} else if g != w {
+			t.Errorf("%q: got %+v, want %+v", name, g, w)
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	script := `
+local test = require("test")
+test.it("lists orders #smoke", function() end)
+test.it("exports orders", function() end)
+test.describe("refunds #slow", function()
+  test.it("refunds an order", function() end)
+  test.it("refunds twice #smoke", function() end)
+end)
+`
+	passed := outcome{state: appv1alpha1.TestCasePassed}
+	skipped := outcome{state: appv1alpha1.TestCaseSkipped}
+
+	tests := []struct {
+		name string
+		tags []string
+		want map[string]outcome
+	}{
+		{
+			name: "no tags runs every case",
+			want: map[string]outcome{
+				"lists orders #smoke":                  passed,
+				"exports orders":                       passed,
+				"refunds #slow > refunds an order":     passed,
+				"refunds #slow > refunds twice #smoke": passed,
+			},
+		},
+		{
+			name: "a case tag selects the case",
+			tags: []string{"smoke"},
+			want: map[string]outcome{
+				"lists orders #smoke":                  passed,
+				"exports orders":                       skipped,
+				"refunds #slow > refunds an order":     skipped,
+				"refunds #slow > refunds twice #smoke": passed,
+			},
+		},
+		{
+			name: "a describe tag selects every case in it",
+			tags: []string{"slow"},
+			want: map[string]outcome{
+				"lists orders #smoke":                  skipped,
+				"exports orders":                       skipped,
+				"refunds #slow > refunds an order":     passed,
+				"refunds #slow > refunds twice #smoke": passed,
+			},
+		},
+		{
+			name: "a tag no case holds skips every case",
+			tags: []string{"nightly"},
+			want: map[string]outcome{
+				"lists orders #smoke":                  skipped,
+				"exports orders":                       skipped,
+				"refunds #slow > refunds an order":     skipped,
+				"refunds #slow > refunds twice #smoke": skipped,
+			},
+		},
+		{
+			name: "a tag must match whole",
+			tags: []string{"smo"},
+			want: map[string]outcome{
+				"lists orders #smoke":                  skipped,
+				"exports orders":                       skipped,
+				"refunds #slow > refunds an order":     skipped,
+				"refunds #slow > refunds twice #smoke": skipped,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check(t, run(t, script, tt.tags...), tt.want)
+		})
+	}
+}
+
+func TestHookErrors(t *testing.T) {
+	passed := outcome{state: appv1alpha1.TestCasePassed}
+	failed := func(message string) outcome {
+		return outcome{state: appv1alpha1.TestCaseFailed, message: message}
+	}
+
+	tests := []struct {
+		name   string
+		script string
+		want   map[string]outcome
+	}{
+		{
+			name: "before_all fails every case of its block",
+			script: `
+local test = require("test")
+test.describe("orders", function()
+  test.before_all(function() error("no database") end)
+  test.it("creates", function() end)
+  test.describe("refunds", function()
+    test.it("refunds", function() end)
+  end)
+end)
+test.it("lists", function() end)
+`,
+			want: map[string]outcome{
+				"orders > creates":           failed("before_all: no database"),
+				"orders > refunds > refunds": failed("before_all: no database"),
+				"lists":                      passed,
+			},
+		},
+		{
+			name: "before_each fails the case without running it",
+			script: `
+local test = require("test")
+local ran = false
+test.describe("orders", function()
+  test.before_each(function() error("no fixture") end)
+  test.it("creates", function() ran = true end)
+end)
+test.it("did not run the case", function() assert(not ran, "case ran") end)
+`,
+			want: map[string]outcome{
+				"orders > creates":     failed("no fixture"),
+				"did not run the case": passed,
+			},
+		},
+		{
+			name: "after_each fails a passing case",
+			script: `
+local test = require("test")
+test.after_each(function() error("cleanup failed") end)
+test.it("creates", function() end)
+`,
+			want: map[string]outcome{
+				"creates": failed("after_each: cleanup failed"),
+			},
+		},
+		{
+			name: "the case error wins over an after_each error",
+			script: `
+local test = require("test")
+test.after_each(function() error("cleanup failed") end)
+test.it("creates", function() error("no order") end)
+`,
+			want: map[string]outcome{
+				"creates": failed("no order"),
+			},
+		},
+		{
+			name: "after_all is reported as a case of its own",
+			script: `
+local test = require("test")
+test.describe("orders", function()
+  test.after_all(function() error("drop failed") end)
+  test.it("creates", function() end)
+end)
+`,
+			want: map[string]outcome{
+				"orders > creates":   passed,
+				"orders > after_all": failed("after_all: drop failed"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check(t, run(t, tt.script), tt.want)
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxDiffLines bounds the differences FormatDiff lists.
+const maxDiffLines = 20
+
+// Difference is one place where an actual value departs from the expected one. Path is "$" for the
+// value itself, "$.key" and "$[i]" (1-based, as in Lua) below it.
+type Difference struct {
+	Path       string
+	Expected   interface{}
+	Actual     interface{}
+	Missing    bool
+	Unexpected bool
+}
+
+func (d Difference) String() string {
+	switch {
+	case d.Missing:
+		return fmt.Sprintf("%s: missing, expected %s", d.Path, Format(d.Expected))
+	case d.Unexpected:
+		return fmt.Sprintf("%s: unexpected %s", d.Path, Format(d.Actual))
+	default:
+		return fmt.Sprintf("%s: expected %s, got %s", d.Path, Format(d.Expected), Format(d.Actual))
+	}
+}
+
+// Compare returns the differences between two values converted by ToGoValue; at each level, those of
+// the expected keys and elements come first, then the unexpected ones. With
+// subset set, actual objects may hold keys that expected does not and actual arrays may be longer;
+// arrays are still compared element by element in order.
+func Compare(expected, actual interface{}, subset bool) []Difference {
+	var diffs []Difference
+	compare("$", expected, actual, subset, &diffs)
+	return diffs
+}
+
+// Equal reports whether two values converted by ToGoValue are deeply equal.
+func Equal(expected, actual interface{}) bool {
+	return len(Compare(expected, actual, false)) == 0
+}
+
+func compare(path string, expected, actual interface{}, subset bool, diffs *[]Difference) {
+	// An empty Lua table converts to an empty object whether it was meant as an object or an array
+	if isEmptyTable(expected) && (isEmptyTable(actual) || subset && isTable(actual)) {
+		return
+	}
+
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			*diffs = append(*diffs, Difference{Path: path, Expected: expected, Actual: actual})
+			return
+		}
+		for _, k := range slices.Sorted(maps.Keys(exp)) {
+			v, found := act[k]
+			if !found {
+				*diffs = append(*diffs, Difference{Path: keyPath(path, k), Expected: exp[k], Missing: true})
+				continue
+			}
+			compare(keyPath(path, k), exp[k], v, subset, diffs)
+		}
+		if !subset {
+			for _, k := range slices.Sorted(maps.Keys(act)) {
+				if _, found := exp[k]; !found {
+					*diffs = append(*diffs, Difference{Path: keyPath(path, k), Actual: act[k], Unexpected: true})
+				}
+			}
+		}
+
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok {
+			*diffs = append(*diffs, Difference{Path: path, Expected: expected, Actual: actual})
+			return
+		}
+		for i, v := range exp {
+			if i >= len(act) {
+				*diffs = append(*diffs, Difference{Path: indexPath(path, i), Expected: v, Missing: true})
+				continue
+			}
+			compare(indexPath(path, i), v, act[i], subset, diffs)
+		}
+		if !subset {
+			for i := len(exp); i < len(act); i++ {
+				*diffs = append(*diffs, Difference{Path: indexPath(path, i), Actual: act[i], Unexpected: true})
+			}
+		}
+
+	default:
+		if expected != actual {
+			*diffs = append(*diffs, Difference{Path: path, Expected: expected, Actual: actual})
+		}
+	}
+}
+
+// CompareUnordered matches the elements of two arrays in any order, each actual element standing for at
+// most one expected element. Expected elements left without a match are reported missing, actual ones
+// unexpected.
+func CompareUnordered(expected, actual []interface{}) []Difference {
+	used := make([]bool, len(actual))
+	var diffs []Difference
+	for i, e := range expected {
+		found := false
+		for j, a := range actual {
+			if !used[j] && Equal(e, a) {
+				used[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			diffs = append(diffs, Difference{Path: indexPath("$", i), Expected: e, Missing: true})
+		}
+	}
+	for j, a := range actual {
+		if !used[j] {
+			diffs = append(diffs, Difference{Path: indexPath("$", j), Actual: a, Unexpected: true})
+		}
+	}
+	return diffs
+}
+
+// FormatDiff lists differences one per line, indented, up to maxDiffLines of them.
+func FormatDiff(diffs []Difference) string {
+	var b strings.Builder
+	for i, d := range diffs {
+		if i == maxDiffLines {
+			fmt.Fprintf(&b, "\n  ... and %d more", len(diffs)-maxDiffLines)
+			break
+		}
+		b.WriteString("\n  ")
+		b.WriteString(d.String())
+	}
+	return b.String()
+}
+
+// Format renders a value converted by ToGoValue for a failure message: JSON, or nil.
+func Format(v interface{}) string {
+	if v == nil {
+		return "nil"
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(raw)
+}
+
+func isEmptyTable(v interface{}) bool {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func isTable(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func keyPath(path, key string) string {
+	if identifierPattern.MatchString(key) {
+		return path + "." + key
+	}
+	return path + "[" + strconv.Quote(key) + "]"
+}
+
+func indexPath(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i+1)
+}
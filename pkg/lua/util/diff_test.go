@@ -0,0 +1,148 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	order := map[string]interface{}{
+		"id": 7.0,
+		"customer": map[string]interface{}{
+			"name":    "Ada",
+			"address": map[string]interface{}{"city": "London", "zip": "N1"},
+		},
+		"items": []interface{}{
+			map[string]interface{}{"sku": "pen", "qty": 2.0},
+			map[string]interface{}{"sku": "ink", "qty": 1.0},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		expected interface{}
+		actual   interface{}
+		subset   bool
+		want     string
+	}{
+		{name: "equal nested tables", expected: order, actual: order},
+		{
+			name:     "changed value in a nested object",
+			expected: order,
+			actual: map[string]interface{}{
+				"id": 7.0,
+				"customer": map[string]interface{}{
+					"name":    "Ada",
+					"address": map[string]interface{}{"city": "Paris", "zip": "N1"},
+				},
+				"items": order["items"],
+			},
+			want: "\n  $.customer.address.city: expected \"London\", got \"Paris\"",
+		},
+		{
+			name:     "changed value in an array of objects",
+			expected: order,
+			actual: map[string]interface{}{
+				"id":       7.0,
+				"customer": order["customer"],
+				"items": []interface{}{
+					map[string]interface{}{"sku": "pen", "qty": 2.0},
+					map[string]interface{}{"sku": "ink", "qty": 3.0},
+				},
+			},
+			want: "\n  $.items[2].qty: expected 1, got 3",
+		},
+		{
+			name:     "missing and unexpected keys",
+			expected: map[string]interface{}{"a": map[string]interface{}{"b": 1.0, "c": 2.0}},
+			actual:   map[string]interface{}{"a": map[string]interface{}{"b": 1.0, "d": 3.0}},
+			want:     "\n  $.a.c: missing, expected 2\n  $.a.d: unexpected 3",
+		},
+		{
+			name:     "subset ignores extra keys and elements",
+			expected: map[string]interface{}{"customer": map[string]interface{}{"name": "Ada"}, "items": []interface{}{order["items"].([]interface{})[0]}},
+			actual:   order,
+			subset:   true,
+		},
+		{
+			name:     "subset still reports missing keys",
+			expected: map[string]interface{}{"customer": map[string]interface{}{"email": "ada@example.com"}},
+			actual:   order,
+			subset:   true,
+			want:     "\n  $.customer.email: missing, expected \"ada@example.com\"",
+		},
+		{
+			name:     "array shorter than expected",
+			expected: []interface{}{[]interface{}{1.0, 2.0}},
+			actual:   []interface{}{[]interface{}{1.0}},
+			want:     "\n  $[1][2]: missing, expected 2",
+		},
+		{
+			name:     "type mismatch below a key",
+			expected: map[string]interface{}{"tags": []interface{}{"new"}},
+			actual:   map[string]interface{}{"tags": "new"},
+			want:     "\n  $.tags: expected [\"new\"], got \"new\"",
+		},
+		{
+			name:     "keys that are not identifiers are quoted",
+			expected: map[string]interface{}{"content-type": "application/json"},
+			actual:   map[string]interface{}{"content-type": "text/plain"},
+			want:     "\n  $[\"content-type\"]: expected \"application/json\", got \"text/plain\"",
+		},
+		{
+			name:     "empty table stands for an empty array",
+			expected: map[string]interface{}{"items": map[string]interface{}{}},
+			actual:   map[string]interface{}{"items": []interface{}{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatDiff(Compare(tt.expected, tt.actual, tt.subset)); got != tt.want {
+				t.Errorf("got diff %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareUnordered(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []interface{}
+		actual   []interface{}
+		want     string
+	}{
+		{
+			name:     "same elements in another order",
+			expected: []interface{}{1.0, map[string]interface{}{"a": 1.0}, "x"},
+			actual:   []interface{}{"x", 1.0, map[string]interface{}{"a": 1.0}},
+		},
+		{
+			name:     "each actual element matches once",
+			expected: []interface{}{1.0, 1.0},
+			actual:   []interface{}{1.0, 2.0},
+			want:     "\n  $[2]: missing, expected 1\n  $[2]: unexpected 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatDiff(CompareUnordered(tt.expected, tt.actual)); got != tt.want {
+				t.Errorf("got diff %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDiffLimit(t *testing.T) {
+	var diffs []Difference
+	for i := range maxDiffLines + 3 {
+		diffs = append(diffs, Difference{Path: fmt.Sprintf("$[%d]", i+1), Expected: 1.0, Missing: true})
+	}
+	got := FormatDiff(diffs)
+	if lines := strings.Count(got, "\n"); lines != maxDiffLines+1 {
+		t.Errorf("got %d lines, want %d", lines, maxDiffLines+1)
+	}
+	if !strings.HasSuffix(got, "\n  ... and 3 more") {
+		t.Errorf("got %q, want it to end with the count of dropped differences", got)
+	}
+}
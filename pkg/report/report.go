@@ -0,0 +1,241 @@
+// Package report renders the results of a test run as the JSON and JUnit XML reports the runner stores
+// in the TestRun's report ConfigMap, and as an HTML page.
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// maxConfigMapBytes keeps the reports clear of the 1MiB limit on a ConfigMap.
+const maxConfigMapBytes = 900 * 1024
+
+// Report is the outcome of a script run with the output it printed.
+type Report struct {
+	TestRun   string                   `json:"testRun,omitempty"`
+	Script    string                   `json:"script"`
+	StartTime time.Time                `json:"startTime"`
+	Summary   appv1alpha1.TestSummary  `json:"summary"`
+	TestCases []TestCase               `json:"testCases"`
+	Error     *appv1alpha1.TestFailure `json:"error,omitempty"`
+
+	// Output is what the script printed outside its test cases
+	Output string `json:"output,omitempty"`
+	// OutputDropped is set when the output did not fit the ConfigMap and was left out
+	OutputDropped bool `json:"outputDropped,omitempty"`
+}
+
+// TestCase is the outcome of a test case with the output it printed.
+type TestCase struct {
+	appv1alpha1.TestCaseResult
+	Output string `json:"output,omitempty"`
+}
+
+// ConfigMapData renders the JSON and JUnit reports under their ConfigMap keys. Reports too large for a
+// ConfigMap lose the captured output.
+func ConfigMapData(r Report) (map[string]string, error) {
+	for {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(r); err != nil {
+			return nil, err
+		}
+		raw := buf.Bytes()
+		junit, err := JUnit(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(raw)+len(junit) <= maxConfigMapBytes || r.OutputDropped {
+			return map[string]string{
+				appv1alpha1.ReportJSONKey:  string(raw),
+				appv1alpha1.ReportJUnitKey: string(junit),
+			}, nil
+		}
+		r.TestCases = append([]TestCase(nil), r.TestCases...)
+		for i := range r.TestCases {
+			r.TestCases[i].Output = ""
+		}
+		r.Output = ""
+		r.OutputDropped = true
+	}
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int32        `xml:"tests,attr"`
+	Failures int32        `xml:"failures,attr"`
+	Errors   int32        `xml:"errors,attr"`
+	Skipped  int32        `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string         `xml:"name,attr"`
+	Tests     int32          `xml:"tests,attr"`
+	Failures  int32          `xml:"failures,attr"`
+	Errors    int32          `xml:"errors,attr"`
+	Skipped   int32          `xml:"skipped,attr"`
+	Time      string         `xml:"time,attr"`
+	Timestamp string         `xml:"timestamp,attr"`
+	Cases     []junitCase    `xml:"testcase"`
+	SystemOut *junitCharData `xml:"system-out,omitempty"`
+}
+
+type junitCase struct {
+	Name       string         `xml:"name,attr"`
+	ClassName  string         `xml:"classname,attr"`
+	Assertions int32          `xml:"assertions,attr"`
+	Time       string         `xml:"time,attr"`
+	Failure    *junitFailure  `xml:"failure,omitempty"`
+	Error      *junitFailure  `xml:"error,omitempty"`
+	Skipped    *struct{}      `xml:"skipped,omitempty"`
+	SystemOut  *junitCharData `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+type junitCharData struct {
+	Text string `xml:",cdata"`
+}
+
+// JUnit renders the report as JUnit XML: one suite named after the script, with a test case per case of
+// the script classed by the groups it is nested in. An error that ended the script outside its test
+// cases is reported as an errored test case named after the script.
+func JUnit(r Report) ([]byte, error) {
+	suite := junitSuite{
+		Name:      r.Script,
+		Tests:     r.Summary.Total,
+		Failures:  r.Summary.Failed,
+		Skipped:   r.Summary.Skipped,
+		Time:      seconds(r.Summary.Duration.Duration),
+		Timestamp: r.StartTime.UTC().Format("2006-01-02T15:04:05"),
+		SystemOut: charData(r.Output),
+	}
+	for _, tc := range r.TestCases {
+		jc := junitCase{
+			Name:       tc.Name,
+			ClassName:  r.Script,
+			Assertions: tc.Assertions,
+			Time:       seconds(tc.Duration.Duration),
+			SystemOut:  charData(tc.Output),
+		}
+		if i := strings.LastIndex(tc.Name, appv1alpha1.TestCaseNameSeparator); i >= 0 {
+			jc.ClassName = tc.Name[:i]
+			jc.Name = tc.Name[i+len(appv1alpha1.TestCaseNameSeparator):]
+		}
+		switch tc.State {
+		case appv1alpha1.TestCaseFailed:
+			jc.Failure = failure(tc.Failure, "AssertionError")
+		case appv1alpha1.TestCaseSkipped:
+			jc.Skipped = &struct{}{}
+		}
+		suite.Cases = append(suite.Cases, jc)
+	}
+	if r.Error != nil {
+		suite.Tests++
+		suite.Errors++
+		suite.Cases = append(suite.Cases, junitCase{Name: r.Script, ClassName: r.Script, Time: "0", Error: failure(r.Error, "ScriptError")})
+	}
+
+	name := r.TestRun
+	if name == "" {
+		name = r.Script
+	}
+	suites := junitSuites{
+		Name:     name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitSuite{suite},
+	}
+	raw, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(raw, '\n')...), nil
+}
+
+func failure(f *appv1alpha1.TestFailure, kind string) *junitFailure {
+	if f == nil {
+		return &junitFailure{Type: kind}
+	}
+	text := f.Message
+	if f.Location != "" {
+		text = f.Location + ": " + text
+	}
+	if f.StackTrace != "" {
+		text += "\n" + f.StackTrace
+	}
+	return &junitFailure{Message: f.Message, Type: kind, Text: text}
+}
+
+func charData(s string) *junitCharData {
+	if s == "" {
+		return nil
+	}
+	return &junitCharData{Text: s}
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"ms": func(d time.Duration) string { return d.Round(time.Millisecond).String() },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{if .TestRun}}{{.TestRun}}{{else}}{{.Script}}{{end}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ddd; padding: 6px 8px; text-align: left; vertical-align: top; }
+pre { margin: 4px 0; white-space: pre-wrap; }
+.Passed { color: #2e7d32; } .Failed { color: #c62828; } .Skipped { color: #757575; }
+</style>
+</head>
+<body>
+<h1>{{if .TestRun}}{{.TestRun}}{{else}}{{.Script}}{{end}}</h1>
+<p>{{.Summary.Passed}} passed, {{.Summary.Failed}} failed, {{.Summary.Skipped}} skipped of {{.Summary.Total}},
+{{.Summary.Assertions}} assertions in {{ms .Summary.Duration.Duration}}. Started {{.StartTime.Format "2006-01-02 15:04:05 MST"}}.</p>
+{{with .Error}}<p class="Failed">Script error{{if .Location}} at {{.Location}}{{end}}: {{.Message}}</p><pre>{{.StackTrace}}</pre>{{end}}
+<table>
+<tr><th>Test case</th><th>State</th><th>Assertions</th><th>Duration</th><th>Details</th></tr>
+{{range .TestCases}}<tr>
+<td>{{.Name}}</td><td class="{{.State}}">{{.State}}</td><td>{{.Assertions}}</td><td>{{ms .Duration.Duration}}</td>
+<td>{{with .Failure}}{{if .Location}}{{.Location}}: {{end}}{{.Message}}{{if .StackTrace}}<pre>{{.StackTrace}}</pre>{{end}}{{end}}
+{{if .Output}}<details><summary>Output</summary><pre>{{.Output}}</pre></details>{{end}}</td>
+</tr>
+{{end}}</table>
+{{if .Output}}<h2>Output</h2><pre>{{.Output}}</pre>{{end}}
+{{if .OutputDropped}}<p>Output was left out: the report did not fit its ConfigMap.</p>{{end}}
+</body>
+</html>
+`))
+
+// HTML renders the report as a standalone HTML page.
+func HTML(r Report) ([]byte, error) {
+	var b bytes.Buffer
+	if err := htmlTemplate.Execute(&b, r); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,206 @@
+package stub
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/mem"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	v1reflectiongrpc "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	v1alphareflectiongrpc "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// LoadDescriptors reads the FileDescriptorSet of the config directory, if there is one. Without it gRPC
+// stubs can only answer with status codes.
+func (s *Server) LoadDescriptors() error {
+	if s.configDir == "" {
+		return nil
+	}
+	raw, err := os.ReadFile(filepath.Join(s.configDir, appv1alpha1.MockDescriptorsFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(raw, &set); err != nil {
+		return fmt.Errorf("%s: %w", appv1alpha1.MockDescriptorsFile, err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return fmt.Errorf("%s: %w", appv1alpha1.MockDescriptorsFile, err)
+	}
+	s.files = files
+	return nil
+}
+
+// GRPCServer returns a gRPC server that answers every call from the stubs, alongside the health service
+// and reflection over the loaded descriptors.
+func (s *Server) GRPCServer() *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.ForceServerCodecV2(rawCodec{}),
+		grpc.UnknownServiceHandler(s.handleStream),
+	)
+	grpc_health_v1.RegisterHealthServer(srv, health.NewServer())
+
+	opts := reflection.ServerOptions{Services: services{srv, s}, DescriptorResolver: s.files}
+	v1reflectiongrpc.RegisterServerReflectionServer(srv, reflection.NewServerV1(opts))
+	v1alphareflectiongrpc.RegisterServerReflectionServer(srv, reflection.NewServer(opts))
+	return srv
+}
+
+// services advertises the registered services plus those of the loaded descriptors over reflection.
+type services struct {
+	srv  *grpc.Server
+	stub *Server
+}
+
+func (p services) GetServiceInfo() map[string]grpc.ServiceInfo {
+	info := p.srv.GetServiceInfo()
+	p.stub.files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		for i := range fd.Services().Len() {
+			info[string(fd.Services().Get(i).FullName())] = grpc.ServiceInfo{}
+		}
+		return true
+	})
+	return info
+}
+
+// handleStream answers a unary call to any method not otherwise registered.
+func (s *Server) handleStream(_ any, stream grpc.ServerStream) error {
+	method, _ := grpc.MethodFromServerStream(stream)
+	var in rawMessage
+	if err := stream.RecvMsg(&in); err != nil {
+		return err
+	}
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	desc := s.method(method)
+	body := ""
+	if desc != nil {
+		msg := dynamicpb.NewMessage(desc.Input())
+		if err := proto.Unmarshal(in, msg); err == nil {
+			if b, err := protojson.Marshal(msg); err == nil {
+				body = string(b)
+			}
+		}
+	}
+
+	stub := s.find(func(c compiledStub) bool { return c.matchGRPC(method, md, body) })
+	req := Request{Protocol: "grpc", Method: "POST", Path: method, Body: body, Headers: map[string]string{}}
+	for k, v := range md {
+		req.Headers[k] = strings.Join(v, ",")
+	}
+	s.record(req, stub)
+
+	if stub == nil {
+		return status.Errorf(codes.Unimplemented, "no stub matches %s", method)
+	}
+	if !sleep(stream.Context(), stub.Response) {
+		return stream.Context().Err()
+	}
+	if len(stub.Response.Headers) > 0 {
+		if err := stream.SendHeader(metadata.New(stub.Response.Headers)); err != nil {
+			return err
+		}
+	}
+	if stub.Response.GRPCCode != 0 {
+		return status.Error(codes.Code(stub.Response.GRPCCode), stub.Response.Body)
+	}
+	out, err := encodeReply(desc, method, stub.Response)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return stream.SendMsg(&out)
+}
+
+// method looks up a full method name such as /pkg.Service/Method in the loaded descriptors.
+func (s *Server) method(fullMethod string) protoreflect.MethodDescriptor {
+	service, name, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return nil
+	}
+	d, err := s.files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil
+	}
+	return sd.Methods().ByName(protoreflect.Name(name))
+}
+
+// encodeReply turns the JSON body of a stub into the method's response message.
+func encodeReply(desc protoreflect.MethodDescriptor, method string, resp appv1alpha1.StubResponse) (rawMessage, error) {
+	if desc == nil {
+		if resp.Body != "" {
+			return nil, fmt.Errorf("no descriptor for %s to encode the stub response with", method)
+		}
+		return rawMessage{}, nil
+	}
+	msg := dynamicpb.NewMessage(desc.Output())
+	if resp.Body != "" {
+		if err := protojson.Unmarshal([]byte(resp.Body), msg); err != nil {
+			return nil, fmt.Errorf("stub response for %s: %w", method, err)
+		}
+	}
+	out, err := proto.Marshal(msg)
+	return rawMessage(out), err
+}
+
+func (c compiledStub) matchGRPC(method string, md metadata.MD, body string) bool {
+	m := c.spec.Request
+	if m.GRPCMethod != method {
+		return false
+	}
+	if m.BodyContains != "" && !strings.Contains(body, m.BodyContains) {
+		return false
+	}
+	for k, v := range m.Headers {
+		if got := md.Get(k); len(got) == 0 || got[0] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// rawMessage carries the encoded request and response of a stubbed call.
+type rawMessage []byte
+
+// rawCodec passes stubbed calls through as bytes and leaves generated messages to protobuf.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) (mem.BufferSlice, error) {
+	if m, ok := v.(*rawMessage); ok {
+		return mem.BufferSlice{mem.SliceBuffer(*m)}, nil
+	}
+	b, err := proto.Marshal(v.(proto.Message))
+	return mem.BufferSlice{mem.SliceBuffer(b)}, err
+}
+
+func (rawCodec) Unmarshal(data mem.BufferSlice, v any) error {
+	if m, ok := v.(*rawMessage); ok {
+		*m = data.Materialize()
+		return nil
+	}
+	return proto.Unmarshal(data.Materialize(), v.(proto.Message))
+}
+
+func (rawCodec) Name() string { return "proto" }
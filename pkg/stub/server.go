@@ -0,0 +1,333 @@
+// Package stub is the stub server run for mock services. It answers HTTP requests and gRPC calls with
+// canned responses and journals every call so tests can verify what the system under test sent.
+//
+// It serves its own API under appv1alpha1.MockAdminPrefix rather than stubs:
+//
+//	GET    /__topas/health    liveness and readiness
+//	GET    /__topas/stubs     every stub, runtime ones first
+//	POST   /__topas/stubs     add a runtime stub (a StubSpec as JSON)
+//	DELETE /__topas/stubs     drop the runtime stubs
+//	GET    /__topas/requests  the request journal, oldest first
+//	DELETE /__topas/requests  clear the journal
+//
+// The config directory holds an optional FileDescriptorSet named appv1alpha1.MockDescriptorsFile;
+// every other file in it is a JSON or YAML list of stubs.
+package stub
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"sigs.k8s.io/yaml"
+
+	appv1alpha1 "github.com/chakradharkondapalli/topas/api/v1alpha1"
+)
+
+// maxJournal bounds the request journal; the oldest entries are dropped first.
+const maxJournal = 1000
+
+// Request is one journaled HTTP request or gRPC call.
+type Request struct {
+	Time     time.Time         `json:"time"`
+	Protocol string            `json:"protocol"`
+	Method   string            `json:"method"`
+	Path     string            `json:"path"`
+	Query    map[string]string `json:"query,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Body     string            `json:"body,omitempty"`
+	Stub     string            `json:"stub,omitempty"`
+	Matched  bool              `json:"matched"`
+}
+
+// Server holds the stubs and the request journal.
+type Server struct {
+	configDir string
+	files     *protoregistry.Files
+
+	mu         sync.Mutex
+	configHash [sha256.Size]byte
+	configured []compiledStub
+	runtime    []compiledStub
+	journal    []Request
+}
+
+type compiledStub struct {
+	spec    appv1alpha1.StubSpec
+	pattern *regexp.Regexp
+}
+
+// New creates a server reading its stubs and descriptors from configDir, which may be empty.
+func New(configDir string) *Server {
+	return &Server{configDir: configDir, files: new(protoregistry.Files)}
+}
+
+func compile(spec appv1alpha1.StubSpec) (compiledStub, error) {
+	c := compiledStub{spec: spec}
+	if spec.Request.PathPattern != "" {
+		re, err := regexp.Compile(spec.Request.PathPattern)
+		if err != nil {
+			return c, fmt.Errorf("stub %q: invalid pathPattern: %w", spec.Name, err)
+		}
+		c.pattern = re
+	}
+	return c, nil
+}
+
+// Load reads the stub files of the config directory, replacing the configured stubs when they changed.
+func (s *Server) Load() error {
+	if s.configDir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(s.configDir)
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	var stubs []compiledStub
+	for _, e := range entries {
+		// Skip the ..data links and timestamped directories of a mounted ConfigMap
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") || e.Name() == appv1alpha1.MockDescriptorsFile {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join(s.configDir, e.Name()))
+		if err != nil {
+			return err
+		}
+		hash.Write(raw)
+		var specs []appv1alpha1.StubSpec
+		if err := yaml.Unmarshal(raw, &specs); err != nil {
+			return fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		for _, spec := range specs {
+			c, err := compile(spec)
+			if err != nil {
+				return fmt.Errorf("%s: %w", e.Name(), err)
+			}
+			stubs = append(stubs, c)
+		}
+	}
+
+	var sum [sha256.Size]byte
+	copy(sum[:], hash.Sum(nil))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sum != s.configHash {
+		s.configHash = sum
+		s.configured = stubs
+		log.Printf("Loaded %d configured stubs", len(stubs))
+	}
+	return nil
+}
+
+// Watch reloads the configured stubs every interval until ctx is done, so ConfigMap updates apply
+// without a restart.
+func (s *Server) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Load(); err != nil {
+				log.Printf("Keeping previous stubs: %v", err)
+			}
+		}
+	}
+}
+
+// find returns the first stub accepted by match: the newest runtime stub first, then the configured ones.
+func (s *Server) find(match func(compiledStub) bool) *appv1alpha1.StubSpec {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.runtime) - 1; i >= 0; i-- {
+		if match(s.runtime[i]) {
+			return &s.runtime[i].spec
+		}
+	}
+	for i := range s.configured {
+		if match(s.configured[i]) {
+			return &s.configured[i].spec
+		}
+	}
+	return nil
+}
+
+func (s *Server) record(req Request, stub *appv1alpha1.StubSpec) {
+	req.Time = time.Now()
+	if stub != nil {
+		req.Matched = true
+		req.Stub = stub.Name
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.journal = append(s.journal, req)
+	if len(s.journal) > maxJournal {
+		s.journal = slices.Delete(s.journal, 0, len(s.journal)-maxJournal)
+	}
+}
+
+// ServeHTTP answers admin calls and stubbed HTTP requests.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, appv1alpha1.MockAdminPrefix) {
+		s.serveAdmin(w, r)
+		return
+	}
+
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	body := string(raw)
+	stub := s.find(func(c compiledStub) bool { return c.matchHTTP(r, body) })
+
+	req := Request{Protocol: "http", Method: r.Method, Path: r.URL.Path, Body: body}
+	if q := r.URL.Query(); len(q) > 0 {
+		req.Query = map[string]string{}
+		for k := range q {
+			req.Query[k] = q.Get(k)
+		}
+	}
+	req.Headers = map[string]string{}
+	for k := range r.Header {
+		req.Headers[k] = r.Header.Get(k)
+	}
+	s.record(req, stub)
+
+	if stub == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no stub matches " + r.Method + " " + r.URL.Path})
+		return
+	}
+	if !sleep(r.Context(), stub.Response) {
+		return
+	}
+	for k, v := range stub.Response.Headers {
+		w.Header().Set(k, v)
+	}
+	status := int(stub.Response.Status)
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, stub.Response.Body)
+}
+
+func (c compiledStub) matchHTTP(r *http.Request, body string) bool {
+	m := c.spec.Request
+	switch {
+	case m.GRPCMethod != "":
+		return false
+	case m.Method != "" && !strings.EqualFold(m.Method, r.Method):
+		return false
+	case m.Path != "" && m.Path != r.URL.Path:
+		return false
+	case c.pattern != nil && !c.pattern.MatchString(r.URL.Path):
+		return false
+	case m.BodyContains != "" && !strings.Contains(body, m.BodyContains):
+		return false
+	}
+	for k, v := range m.Query {
+		if r.URL.Query().Get(k) != v {
+			return false
+		}
+	}
+	for k, v := range m.Headers {
+		if r.Header.Get(k) != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) serveAdmin(w http.ResponseWriter, r *http.Request) {
+	switch strings.TrimPrefix(r.URL.Path, appv1alpha1.MockAdminPrefix) + " " + r.Method {
+	case "health GET":
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+
+	case "stubs GET":
+		s.mu.Lock()
+		stubs := []appv1alpha1.StubSpec{}
+		for i := len(s.runtime) - 1; i >= 0; i-- {
+			stubs = append(stubs, s.runtime[i].spec)
+		}
+		for _, c := range s.configured {
+			stubs = append(stubs, c.spec)
+		}
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, stubs)
+
+	case "stubs POST":
+		var spec appv1alpha1.StubSpec
+		if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		c, err := compile(spec)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		s.mu.Lock()
+		s.runtime = append(s.runtime, c)
+		s.mu.Unlock()
+		writeJSON(w, http.StatusCreated, spec)
+
+	case "stubs DELETE":
+		s.mu.Lock()
+		s.runtime = nil
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+
+	case "requests GET":
+		s.mu.Lock()
+		journal := slices.Clone(s.journal)
+		s.mu.Unlock()
+		if journal == nil {
+			journal = []Request{}
+		}
+		writeJSON(w, http.StatusOK, journal)
+
+	case "requests DELETE":
+		s.mu.Lock()
+		s.journal = nil
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown admin call " + r.Method + " " + r.URL.Path})
+	}
+}
+
+// sleep waits out a response's delay and reports whether the caller is still there.
+func sleep(ctx context.Context, resp appv1alpha1.StubResponse) bool {
+	if resp.Delay == nil || resp.Delay.Duration <= 0 {
+		return true
+	}
+	d := resp.Delay.Duration
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}